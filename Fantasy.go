@@ -1,10 +1,12 @@
 package main
 
 import (
+    "context"
     "database/sql"
     "fmt"
     "log"
     "net/http"
+    "strconv"
     "time"
 	"errors"
 
@@ -21,19 +23,43 @@ const (
     dbName     = "your_database"
 )
 
+const (
+    // Default number of entries a single user may hold in one contest
+    defaultMaxEntriesPerUser = 1
+
+    // How long a user promoted off a contest waitlist has to confirm the held slot
+    waitlistHoldWindow = 15 * time.Minute
+)
+
+// Contest is the persistence model of a contest row; the API exposes it as ContestResponse
 type Contest struct {
     ID           int
     Name         string
     Prize        float64
+    PrizeStructure string
     TotalSlots   int
     RemainingSlots int
     StartDate    time.Time
     EndDate      time.Time
     Status       string
     ActiveDate   time.Time
+    IsPrivate    bool
+    CommissionerID int
+    IsLocked     bool
+    SlateID      int
+    IsGuaranteed bool
+    MinFillPercent int
+    AutoClone    bool
+    MaxClones    int
+    SeriesID     int
+    CloneNumber  int
+    MaxEntriesPerUser int
+    EntryFee     float64
+    RosterSize   int
     CreatedAt    time.Time
 }
 
+// Team is the persistence model of a team row; the API exposes it as TeamResponse
 type Team struct {
     ID          int
     Name        string
@@ -49,21 +75,87 @@ func main() {
     }
     defer db.Close()
 
+    // Request types declare their validation rules with binding tags
+    if err := registerValidators(); err != nil {
+        log.Fatal(err)
+    }
+
+    // Drafts pick up their pick timers where they left off before a restart
+    drafts := newDraftManager(db)
+    r := newRouter(db, drafts)
+    if err := drafts.resume(); err != nil {
+        log.Printf("draft: failed to resume running drafts: %v", err)
+    }
+
+    // The OpenAPI document is generated from apiOperations; refuse to start if a route
+    // was added or removed without updating it
+    if err := checkRouteDrift(r.Routes()); err != nil {
+        log.Fatal(err)
+    }
+
+    // Background jobs run until shutdown, when serve waits for them to return
+    jobs := []func(context.Context){
+        func(ctx context.Context) { runWaitlistJob(ctx, db) },
+        func(ctx context.Context) { runContestGeneratorJob(ctx, db) },
+        func(ctx context.Context) { runContestLockJob(ctx, db) },
+        func(ctx context.Context) { runWaiverJob(ctx, db) },
+        func(ctx context.Context) { runTradeJob(ctx, db) },
+        func(ctx context.Context) { runIdempotencyJob(ctx, db) },
+        func(ctx context.Context) { runWebhookJob(ctx, db) },
+    }
+
+    // The gRPC API shares the domain code with the HTTP API; both servers and the jobs
+    // start and shut down together
+    if err := serve(r, newGRPCServer(db), jobs...); err != nil {
+        log.Fatal(err)
+    }
+}
+
+// newRouter builds the HTTP API: the middleware chain and every route, including the
+// OpenAPI document
+func newRouter(db *sql.DB, drafts *draftManager) *gin.Engine {
     r := gin.Default()
 
-    // Define your API routes here
+    // Every request gets an ID; retried mutating requests with the same Idempotency-Key
+    // replay the first response, and errors recorded by handlers are rendered last so the
+    // stored response includes them
+    r.Use(requestIDMiddleware(), idempotencyMiddleware(db), errorMiddleware())
 
-    r.Run(":8080")
+    // Define your API routes here
+    setupContestRoutes(r, db)
+    setupTeamRoutes(r, db)
+    setupContestEntryRoutes(r, db)
+    setupContestChangeRoutes(r, db)
+    setupContestLeaveRoutes(r, db)
+    setupEntryRoutes(r, db)
+    setupWaitlistRoutes(r, db)
+    setupPrivateContestRoutes(r, db)
+    setupH2HRoutes(r, db)
+    setupTemplateRoutes(r, db)
+    setupFinanceRoutes(r, db)
+    setupNotificationRoutes(r, db)
+    setupLeagueRoutes(r, db)
+    setupSeasonRoutes(r, db)
+    setupWaiverRoutes(r, db)
+    setupTradeRoutes(r, db)
+    setupScoringRoutes(r, db)
+    setupGraphQLRoutes(r, db)
+    setupWebhookRoutes(r, db)
+    setupDraftRoutes(r, db, drafts)
+    setupAuctionRoutes(r, db, drafts)
+    setupOpenAPIRoutes(r)
+
+    return r
 }
 
 // CRUD operations for contests
 
-// Create a new contest
-func createContest(db *sql.DB, name string, prize float64, totalSlots int, startDate, endDate time.Time) error {
-	// Create a transaction to ensure consistency
+// Create a new contest and return its ID
+func createContest(db *sql.DB, contest *Contest) (int, error) {
+	// Start a transaction to ensure consistency
 	tx, err := db.Begin()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() {
 		if p := recover(); p != nil {
@@ -72,55 +164,93 @@ func createContest(db *sql.DB, name string, prize float64, totalSlots int, start
 	}()
 
 	// Insert a new contest record into the database
-	_, err = tx.Exec(
-		"INSERT INTO contest (name, prize, total_slots, remaining_slots, start_date, end_date, status, active_date, created_at) VALUES (?, ?, ?, ?, ?, ?, 'active', ?, NOW())",
-		name, prize, totalSlots, totalSlots, startDate, endDate, startDate,
-	)
+	contestID, err := insertContest(tx, contest)
 	if err != nil {
 		tx.Rollback()
-		return err
+		return 0, err
+	}
+
+	// Names must be unique among the contests open for entries. The unique index on
+	// open_name enforces it, so two concurrent creates cannot both take a name.
+	_, err = tx.Exec("UPDATE contest SET name_unique = TRUE WHERE id = ?", contestID)
+	if err != nil {
+		tx.Rollback()
+		if isDuplicateKeyError(err) {
+			return 0, conflictError("name_taken", "An open contest already has this name")
+		}
+		return 0, err
 	}
 
 	// Commit the transaction
 	err = tx.Commit()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return contestID, nil
 }
 
+// contestColumns lists the contest columns in the order scanContest reads them
+const contestColumns = "id, name, prize, prize_structure, total_slots, remaining_slots, start_date, end_date, status, active_date, is_private, IFNULL(commissioner_id, 0), is_locked, IFNULL(slate_id, 0), is_guaranteed, min_fill_percent, auto_clone, max_clones, IFNULL(series_id, id), clone_number, max_entries_per_user, entry_fee, roster_size, created_at"
 
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-// Get a contest by ID
-func getContest(db *sql.DB, contestID int) (*Contest, error) {
-	// Query the database to fetch the contest by ID
-	row := db.QueryRow("SELECT id, name, prize, total_slots, remaining_slots, start_date, end_date, status, active_date, created_at FROM contest WHERE id = ?", contestID)
-
+// scanContest reads one contest selected with contestColumns
+func scanContest(row rowScanner) (*Contest, error) {
 	var contest Contest
 	err := row.Scan(
 		&contest.ID,
 		&contest.Name,
 		&contest.Prize,
+		&contest.PrizeStructure,
 		&contest.TotalSlots,
 		&contest.RemainingSlots,
 		&contest.StartDate,
 		&contest.EndDate,
 		&contest.Status,
 		&contest.ActiveDate,
+		&contest.IsPrivate,
+		&contest.CommissionerID,
+		&contest.IsLocked,
+		&contest.SlateID,
+		&contest.IsGuaranteed,
+		&contest.MinFillPercent,
+		&contest.AutoClone,
+		&contest.MaxClones,
+		&contest.SeriesID,
+		&contest.CloneNumber,
+		&contest.MaxEntriesPerUser,
+		&contest.EntryFee,
+		&contest.RosterSize,
 		&contest.CreatedAt,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contest, nil
+}
+
+// Get a contest by ID
+func getContest(db *sql.DB, contestID int) (*Contest, error) {
+	// Query the database to fetch the contest by ID
+	row := db.QueryRow("SELECT "+contestColumns+" FROM contest WHERE id = ?", contestID)
+
+	contest, err := scanContest(row)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// No contest found with the given ID
-			return nil, errors.New("contest not found")
+			return nil, errContestNotFound
 		}
 		// Other database-related error
 		return nil, err
 	}
 
-	return &contest, nil
+	return contest, nil
 }
 
 
@@ -165,6 +295,69 @@ func updateContestSlot(db *sql.DB, contestID int, newSlot int) error {
 	return nil
 }
 
+// Update a contest's total number of slots. Only operators, or the commissioner of a
+// private contest, may do so. Remaining slots move by the same amount, and any slots
+// that open up are offered to the waitlist.
+func updateContestTotalSlots(db *sql.DB, userID int, contestID int, totalSlots int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the contest so concurrent entries see the new slot count
+	var currentTotal, remainingSlots, commissionerID int
+	var isPrivate bool
+	err = tx.QueryRow("SELECT total_slots, remaining_slots, is_private, IFNULL(commissioner_id, 0) FROM contest WHERE id = ? FOR UPDATE", contestID).Scan(&currentTotal, &remainingSlots, &isPrivate, &commissionerID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if !isPrivate || commissionerID != userID {
+		operator, err := isOperator(tx, userID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if !operator {
+			tx.Rollback()
+			return forbiddenError("not_commissioner", "Only operators and the commissioner of a private contest can change its slots")
+		}
+	}
+
+	// Slots already taken cannot be removed
+	newRemainingSlots := remainingSlots + (totalSlots - currentTotal)
+	if newRemainingSlots < 0 {
+		tx.Rollback()
+		return conflictError("slots_taken", "Total slots cannot be lower than the number of taken slots")
+	}
+
+	_, err = tx.Exec("UPDATE contest SET total_slots = ?, remaining_slots = ? WHERE id = ?", totalSlots, newRemainingSlots, contestID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := promoteWaitlist(tx, contestID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // Delete a contest by ID
 func deleteContest(db *sql.DB, contestID int) error {
 	// Start a transaction to ensure consistency
@@ -197,12 +390,12 @@ func deleteContest(db *sql.DB, contestID int) error {
 
 // CRUD operations for teams
 
-// Create a new team
-func createTeam(db *sql.DB, name, displayName string) error {
+// Create a new team and return its ID
+func createTeam(db *sql.DB, name, displayName string) (int, error) {
 	// Start a transaction to ensure consistency
 	tx, err := db.Begin()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() {
 		if p := recover(); p != nil {
@@ -210,20 +403,31 @@ func createTeam(db *sql.DB, name, displayName string) error {
 		}
 	}()
 
-	// Insert a new team record into the database
-	_, err = tx.Exec("INSERT INTO team (name, displayname, created_at) VALUES (?, ?, NOW())", name, displayName)
+	// Insert a new team record into the database. Team names are unique outside of
+	// leagues; the unique index on open_name enforces it, so two concurrent creates
+	// cannot both take a name.
+	res, err := tx.Exec("INSERT INTO team (name, displayname, created_at) VALUES (?, ?, NOW())", name, displayName)
 	if err != nil {
 		tx.Rollback()
-		return err
+		if isDuplicateKeyError(err) {
+			return 0, validationError("name_taken", "A team with this name already exists", fieldError("name", "is already taken"))
+		}
+		return 0, err
+	}
+
+	teamID, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
 	}
 
 	// Commit the transaction
 	err = tx.Commit()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return int(teamID), nil
 }
 
 
@@ -244,7 +448,7 @@ func getTeam(db *sql.DB, teamID int) (*Team, error) {
     if err != nil {
         if err == sql.ErrNoRows {
             // No team found with the given ID
-            return nil, errors.New("team not found")
+            return nil, errTeamNotFound
         }
         // Other database-related error
         return nil, err
@@ -254,197 +458,139 @@ func getTeam(db *sql.DB, teamID int) (*Team, error) {
 }
 
 
-func setupRoutes(r *gin.Engine) {
-		// Route to create a new team
-		r.POST("/teams", func(c *gin.Context) {
-			// Parse the request body to get the team data
-			var team Team // Assuming you have a Team struct defined
-	
-			if err := c.ShouldBindJSON(&team); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-	
-			// Validate and create the team in the database
-			if err := createTeam(db, team.Name, team.DisplayName); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create team"})
-				return
-			}
-	
-			c.JSON(http.StatusCreated, gin.H{"message": "Team created successfully"})
-		})
-	}
-	
-	
-	func setupRoutes(r *gin.Engine) {
-		// Route to fetch all teams
-		r.GET("/teams", func(c *gin.Context) {
-			// logic to fetch all teams from the database
-	
-			// Assuming you have a function getTeams(db *sql.DB) that fetches all teams
-			teams, err := getTeams(db)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch teams"})
-				return
-			}
-	
-			c.JSON(http.StatusOK, teams)
-		})
-	}
-	
-	
-	func setupRoutes(r *gin.Engine, db *sql.DB) {
-		// Route to fetch a particular team by ID
-		r.GET("/teams/:id", func(c *gin.Context) {
-			// Get the team ID from the URL parameter
-			teamIDStr := c.Param("id")
-	
-			// Convert the teamIDStr to an integer
-			teamID, err := strconv.Atoi(teamIDStr)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team ID"})
-				return
-			}
-	
-			// logic to fetch a team by ID from the database
-			team, err := getTeamByID(db, teamID)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch team"})
-				return
-			}
-	
-			c.JSON(http.StatusOK, team)
-		})
-	}
-	
-	// Define your Team struct here if not already defined
-	type Team struct {
-		ID          int
-		Name        string
-		DisplayName string
-		CreatedAt   time.Time
-	}
-	
-	// getTeamByID function that retrieves a team by its ID
-	func getTeamByID(db *sql.DB, teamID int) (*Team, error) {
-		var team Team
-		err := db.QueryRow("SELECT id, name, displayname, created_at FROM team WHERE id = ?", teamID).
-			Scan(&team.ID, &team.Name, &team.DisplayName, &team.CreatedAt)
-	
+func setupTeamRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to create a new team
+	r.POST("/teams", func(c *gin.Context) {
+		// Parse and validate the request body to get the team data
+		var req CreateTeamRequest
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+
+		// Create the team in the database
+		teamID, err := createTeam(db, req.Name, req.DisplayName)
 		if err != nil {
-			if err == sql.ErrNoRows {
-				return nil, errors.New("team not found")
-			}
-			return nil, err
+			c.Error(apiError(err, "Failed to create team"))
+			return
 		}
-	
-		return &team, nil
-	}
-	
-	
-	func setupRoutes(r *gin.Engine, db *sql.DB) {
-		// Route to create a new contest
-		r.POST("/contests", func(c *gin.Context) {
-			// Parse the request body to get the contest data
-			var contest Contest // Assuming you have a Contest struct defined
-	
-			if err := c.ShouldBindJSON(&contest); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-				return
-			}
-	
-			// Set default values for status and active_date
-			contest.Status = "active"
-			contest.ActiveDate = time.Now()
-	
-			// Validate and create the contest in the database
-			if err := createContest(db, contest); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create contest"})
-				return
-			}
-	
-			c.JSON(http.StatusCreated, gin.H{"message": "Contest created successfully"})
-		})
-	}
-	
-	// Define your Contest struct here if not already defined
-	type Contest struct {
-		Name         string    `json:"name"`
-		Prize        float64   `json:"prize"`
-		TotalSlots   int       `json:"total_slots"`
-		RemainingSlots int     `json:"remaining_slots"`
-		StartDate    time.Time `json:"start_date"`
-		EndDate      time.Time `json:"end_date"`
-		Status       string    `json:"status"`
-		ActiveDate   time.Time `json:"active_date"`
-	}
-	
-	// createContest function that inserts a new contest into the database
-	func createContest(db *sql.DB, contest Contest) error {
-		// Start a transaction to ensure consistency
-		tx, err := db.Begin()
+
+		team, err := getTeam(db, teamID)
 		if err != nil {
-			return err
+			c.Error(apiError(err, "Failed to fetch team"))
+			return
 		}
-		defer func() {
-			if p := recover(); p != nil {
-				tx.Rollback()
-			}
-		}()
-	
-		// Insert a new contest record into the database
-		_, err = tx.Exec(
-			"INSERT INTO contest (name, prize, total_slots, remaining_slots, start_date, end_date, status, active_date, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW())",
-			contest.Name, contest.Prize, contest.TotalSlots, contest.TotalSlots, contest.StartDate, contest.EndDate, contest.Status, contest.ActiveDate,
-		)
+
+		c.JSON(http.StatusCreated, newTeamResponse(team))
+	})
+
+	// Route to fetch all teams
+	r.GET("/teams", func(c *gin.Context) {
+		teams, err := getTeams(db)
 		if err != nil {
-			tx.Rollback()
-			return err
+			c.Error(apiError(err, "Failed to fetch teams"))
+			return
 		}
-	
-		// Commit the transaction
-		err = tx.Commit()
+
+		res := make([]TeamResponse, len(teams))
+		for i := range teams {
+			res[i] = newTeamResponse(&teams[i])
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+
+	// Route to fetch a particular team by ID
+	r.GET("/teams/:id", func(c *gin.Context) {
+		teamID, err := strconv.Atoi(c.Param("id"))
 		if err != nil {
-			return err
+			c.Error(invalidParam("id", "Invalid team ID"))
+			return
 		}
-	
-		return nil
+
+		team, err := getTeam(db, teamID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch team"))
+			return
+		}
+
+		c.JSON(http.StatusOK, newTeamResponse(team))
+	})
+}
+
+// getTeams returns the teams that are not part of a league, oldest first
+func getTeams(db *sql.DB) ([]Team, error) {
+	rows, err := db.Query("SELECT id, name, displayname, created_at FROM team WHERE league_id IS NULL ORDER BY id")
+	if err != nil {
+		return nil, err
 	}
-	
+	defer rows.Close()
+
+	teams := []Team{}
+	for rows.Next() {
+		var team Team
+		if err := rows.Scan(&team.ID, &team.Name, &team.DisplayName, &team.CreatedAt); err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+
+	return teams, rows.Err()
+}
 
-			func setupRoutes(r *gin.Engine, db *sql.DB) {
+			func setupContestEntryRoutes(r *gin.Engine, db *sql.DB) {
 				// Route to enter a contest
 				r.POST("/contests/enter", func(c *gin.Context) {
+					userID, err := currentUserID(c)
+					if err != nil {
+						c.Error(err)
+						return
+					}
+			
 					// Parse the request body to get the user's entry data
 					var entry ContestEntry
 			
 					if err := c.ShouldBindJSON(&entry); err != nil {
-						c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+						c.Error(invalidBody(err))
 						return
 					}
+					entry.UserID = userID
 			
 					// Assuming you have a function enterContest(db *sql.DB, entry ContestEntry) that handles contest entry
-					if err := enterContest(db, entry); err != nil {
-						c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enter contest"})
+					entryID, err := enterContest(db, entry)
+					if errors.Is(err, errContestFull) {
+						c.Error(conflictError("contest_full", "Contest is full, join the waitlist to be notified when a slot opens"))
+						return
+					}
+					if err != nil {
+						c.Error(apiError(err, "Failed to enter contest"))
 						return
 					}
 			
-					c.JSON(http.StatusCreated, gin.H{"message": "Entered contest successfully"})
+					c.JSON(http.StatusCreated, gin.H{"message": "Entered contest successfully", "entry_id": entryID})
 				})
 			}
 			
 			// Define your ContestEntry struct here if not already defined
 			type ContestEntry struct {
-				ContestID int `json:"contest_id"`
-				UserID    int `json:"user_id"`
+				ContestID  int         `json:"contest_id"`
+				UserID     int         `json:"-"` // the authenticated user, never taken from the body
+				Lineup     []int       `json:"lineup"`
+				Roles      LineupRoles `json:"roles,omitempty"`
+				InviteCode string      `json:"invite_code,omitempty"`
+				Password   string      `json:"password,omitempty"`
 			}
 			
-			// enterContest function that handles contest entry
-			func enterContest(db *sql.DB, entry ContestEntry) error {
+			// errContestFull is returned when a contest has no slot left for a new entry
+			var errContestFull = conflictError("contest_full", "No remaining slots available in the contest")
+			
+			// enterContest function that handles contest entry and returns the new entry ID.
+			// A user may enter the same contest several times, up to the contest's max_entries_per_user.
+			func enterContest(db *sql.DB, entry ContestEntry) (int, error) {
 				// Start a transaction to ensure consistency
 				tx, err := db.Begin()
 				if err != nil {
-					return err
+					return 0, err
 				}
 				defer func() {
 					if p := recover(); p != nil {
@@ -452,45 +598,129 @@ func setupRoutes(r *gin.Engine) {
 					}
 				}()
 			
-				// 1. Check User Eligibility (Define your eligibility criteria)
-				if !isUserEligible(db, entry.UserID, entry.ContestID) {
+				// A full contest that auto-clones sends the entrant to the next contest in its series
+				entry.ContestID, err = resolveEntryContest(tx, entry.ContestID)
+				if err != nil {
 					tx.Rollback()
-					return errors.New("User is not eligible to enter this contest")
+					return 0, err
 				}
 			
-				// 2. Check Remaining Slots
-				remainingSlots, err := getRemainingSlots(db, entry.ContestID)
+				entryID, err := addEntry(db, tx, entry, true)
 				if err != nil {
 					tx.Rollback()
-					return err
+					return 0, err
 				}
 			
-				if remainingSlots <= 0 {
-					tx.Rollback()
-					return errors.New("No remaining slots available in the contest")
+				// Commit the transaction
+				err = tx.Commit()
+				if err != nil {
+					return 0, err
+				}
+			
+				return entryID, nil
+			}
+			
+			// addEntry checks and inserts one entry inside tx. When reserveSlot is false the
+			// caller already holds a slot for the user, e.g. a promoted waitlist spot.
+			func addEntry(db *sql.DB, tx *sql.Tx, entry ContestEntry, reserveSlot bool) (int, error) {
+				// 1. Check User Eligibility (Define your eligibility criteria)
+				if !isUserEligible(db, entry.UserID, entry.ContestID) {
+					return 0, errNotEligible
 				}
 			
-				// 3. Update Contest Slots
-				_, err = tx.Exec("UPDATE contest SET remaining_slots = remaining_slots - 1 WHERE id = ?", entry.ContestID)
+				// 2. Lock the contest row so concurrent entries are serialized and the
+				// slot and per-user cap checks below cannot race each other
+				var remainingSlots, maxEntries int
+				var entryFee float64
+				var isPrivate, isLocked bool
+				var inviteCode, passwordHash sql.NullString
+				var status string
+				var startDate time.Time
+				err := tx.QueryRow(
+					"SELECT remaining_slots, max_entries_per_user, entry_fee, is_private, is_locked, invite_code, password_hash, status, start_date FROM contest WHERE id = ? FOR UPDATE",
+					entry.ContestID,
+				).Scan(&remainingSlots, &maxEntries, &entryFee, &isPrivate, &isLocked, &inviteCode, &passwordHash, &status, &startDate)
 				if err != nil {
-					tx.Rollback()
-					return err
+					return 0, err
+				}
+			
+				if status != contestStatusActive || !time.Now().Before(startDate) {
+					return 0, errContestStarted
 				}
 			
-				// 4. Insert a record in the user-contest relationship table
-				_, err = tx.Exec("INSERT INTO user_contest (user_id, contest_id) VALUES (?, ?)", entry.UserID, entry.ContestID)
+				if isLocked {
+					return 0, conflictError("contest_locked", "The contest has been locked by its commissioner")
+				}
+			
+				// Private contests can only be entered with their invite code and password
+				if isPrivate {
+					if err := checkPrivateContestAccess(inviteCode.String, passwordHash.String, entry); err != nil {
+						return 0, err
+					}
+				}
+			
+				if reserveSlot && remainingSlots <= 0 {
+					return 0, errContestFull
+				}
+			
+				// 3. Check the per-user entry cap
+				var userEntries int
+				err = tx.QueryRow("SELECT COUNT(*) FROM user_contest WHERE user_id = ? AND contest_id = ?", entry.UserID, entry.ContestID).Scan(&userEntries)
 				if err != nil {
-					tx.Rollback()
-					return err
+					return 0, err
 				}
 			
-				// Commit the transaction
-				err = tx.Commit()
+				if userEntries >= maxEntries {
+					return 0, errMaxEntries
+				}
+			
+				// 4. Check the lineup against the contest's rules
+				if err := validateLineup(tx, entry.ContestID, entry.Lineup); err != nil {
+					return 0, err
+				}
+			
+				// 5. Charge the entry fee
+				if err := debitWallet(tx, entry.UserID, entryFee, fmt.Sprintf("Entry fee for contest %d", entry.ContestID)); err != nil {
+					return 0, err
+				}
+			
+				// 6. Update Contest Slots
+				if reserveSlot {
+					_, err = tx.Exec("UPDATE contest SET remaining_slots = remaining_slots - 1 WHERE id = ?", entry.ContestID)
+					if err != nil {
+						return 0, err
+					}
+				}
+			
+				// 7. Insert a record in the user-contest relationship table; each row is one entry
+				res, err := tx.Exec("INSERT INTO user_contest (user_id, contest_id, created_at) VALUES (?, ?, NOW())", entry.UserID, entry.ContestID)
+				if err != nil {
+					return 0, err
+				}
+			
+				entryID, err := res.LastInsertId()
 				if err != nil {
-					return err
+					return 0, err
+				}
+			
+				// 8. Store the entry's lineup
+				if err := saveEntryLineup(tx, int(entryID), entry.Lineup, entry.Roles); err != nil {
+					return 0, err
+				}
+			
+				// 9. Tell webhook subscribers about the entry, and that the contest filled up
+				// if it took the last slot
+				if err := recordEntryEvent(tx, webhookEntryCreated, int(entryID), entry.ContestID, entry.UserID); err != nil {
+					return 0, err
 				}
 			
-				return nil
+				if reserveSlot && remainingSlots == 1 {
+					if err := recordContestEvent(tx, webhookContestFilled, entry.ContestID, nil); err != nil {
+						return 0, err
+					}
+				}
+			
+				return int(entryID), nil
 			}
 			
 			// Define your eligibility criteria function (isUserEligible) and remaining slots retrieval function (getRemainingSlots) here.
@@ -523,74 +753,25 @@ func getRemainingSlots(db *sql.DB, contestID int) (int, error) {
 }
 
 	
-	func setupRoutes(r *gin.Engine, db *sql.DB) {
-		// Route to change the selected contest for a user
-		r.PUT("/contests/change/:userID", func(c *gin.Context) {
-			// Parse the request body to get the new contest selection
-			var contestChange ContestChange // Assuming you have a ContestChange struct defined
-	
-			if err := c.ShouldBindJSON(&contestChange); err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	func setupContestChangeRoutes(r *gin.Engine, db *sql.DB) {
+		// Route to move one of a user's entries from one contest to another
+		r.PUT("/contests/change", func(c *gin.Context) {
+			userID, err := currentUserID(c)
+			if err != nil {
+				c.Error(err)
 				return
 			}
 	
-			// Get the user ID from the URL parameter
-			userIDStr := c.Param("userID")
-			userID, err := strconv.Atoi(userIDStr)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			// Parse the request body to get the entry and the new contest
+			var contestChange ContestChange
+	
+			if err := c.ShouldBindJSON(&contestChange); err != nil {
+				c.Error(invalidBody(err))
 				return
 			}
 	
-			// changeSelectedContest function that handles changing the selected contest for a user
-		func changeSelectedContest(db *sql.DB, userID int, newContestID int) error {
-    	// Start a database transaction to ensure data consistency
-    	tx, err := db.Begin()
-    	if err != nil {
-	        return err
-    	}
-    	defer func() {
-        	if p := recover(); p != nil {
-            	tx.Rollback()
-        	}
-    	}()
-
-    // Check if the new contest is valid and exists
-    if !isContestValid(db, newContestID) {
-        tx.Rollback()
-        return errors.New("Invalid or non-existent contest selected")
-    }
-
-    // Update the user's selected contest in the database
-    _, err = tx.Exec("UPDATE users SET selected_contest_id = ? WHERE id = ?", newContestID, userID)
-    if err != nil {
-        tx.Rollback()
-        return err
-    }
-
-    // Commit the transaction
-    err = tx.Commit()
-    if err != nil {
-        return err
-    }
-
-    return nil
-}
-
-// check if the new contest is valid and exists
-func isContestValid(db *sql.DB, contestID int) bool {
-    var valid bool
-    err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM contest WHERE id = ?)", contestID).Scan(&valid)
-    if err != nil {
-        return false
-    }
-    return valid
-}
-
-	
-			// Assuming you have a function changeSelectedContest(db *sql.DB, userID int, newContestID int) that handles the contest change
-			if err := changeSelectedContest(db, userID, contestChange.NewContestID); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change the selected contest"})
+			if err := changeSelectedContest(db, userID, contestChange); err != nil {
+				c.Error(apiError(err, "Failed to change the selected contest"))
 				return
 			}
 	
@@ -598,58 +779,183 @@ func isContestValid(db *sql.DB, contestID int) bool {
 		})
 	}
 	
-	// Define your ContestChange struct here if not already defined
+	// ContestChange identifies the entry to move and the contest it moves to
 	type ContestChange struct {
+		ContestID    int `json:"contest_id"`
+		EntryID      int `json:"entry_id"`
 		NewContestID int `json:"new_contest_id"`
 	}
 	
-	// changeSelectedContest function that handles changing the selected contest for a user
-	func changeSelectedContest(db *sql.DB, userID int, newContestID int) error {
-		// This may involve updating the user's contest selection in the database
-		// logic to change the selected contest for a user
-func changeSelectedContest(db *sql.DB, userID int, newContestID int) error {
-    // Start a transaction to ensure data consistency
-    tx, err := db.Begin()
-    if err != nil {
-        return err
-    }
-    defer func() {
-        if p := recover(); p != nil {
-            tx.Rollback()
-        }
-    }()
-
-    // Check if the new contest (newContestID) exists in the database
-    var contestExists bool
-    err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM contest WHERE id = ?)", newContestID).Scan(&contestExists)
-    if err != nil {
-        tx.Rollback()
-        return err
-    }
-
-    if !contestExists {
-        tx.Rollback()
-        return errors.New("The specified contest does not exist")
-    }
-
-    // Update the user's selected contest in the users table
-    _, err = tx.Exec("UPDATE users SET selected_contest_id = ? WHERE id = ?", newContestID, userID)
-    if err != nil {
-        tx.Rollback()
-        return err
-    }
-
-    // Commit the transaction
-    err = tx.Commit()
-    if err != nil {
-        return err
-    }
-
-    return nil
-}
-
+	// changeSelectedContest moves a single entry of the user from change.ContestID to
+	// change.NewContestID. Everything happens in one transaction: the target contest's
+	// state, the user's eligibility and the lineup rules are checked, the old slot is
+	// released, a new slot is reserved and the entry fee difference is settled in the wallet.
+	func changeSelectedContest(db *sql.DB, userID int, change ContestChange) error {
+		if change.ContestID == change.NewContestID {
+			return conflictError("already_in_contest", "The entry is already in this contest")
+		}
+	
+		// Start a database transaction to ensure data consistency
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if p := recover(); p != nil {
+				tx.Rollback()
+			}
+		}()
+	
+		// Lock both contests, always in ID order so two opposite switches cannot deadlock
+		contests := make(map[int]*Contest, 2)
+		rows, err := tx.Query(
+			"SELECT id, status, start_date, remaining_slots, max_entries_per_user, entry_fee, is_private, is_locked FROM contest WHERE id IN (?, ?) ORDER BY id FOR UPDATE",
+			change.ContestID, change.NewContestID,
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		for rows.Next() {
+			var contest Contest
+			if err := rows.Scan(&contest.ID, &contest.Status, &contest.StartDate, &contest.RemainingSlots, &contest.MaxEntriesPerUser, &contest.EntryFee, &contest.IsPrivate, &contest.IsLocked); err != nil {
+				rows.Close()
+				tx.Rollback()
+				return err
+			}
+			contests[contest.ID] = &contest
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		rows.Close()
+	
+		oldContest, newContest := contests[change.ContestID], contests[change.NewContestID]
+		if oldContest == nil || newContest == nil {
+			tx.Rollback()
+			return notFoundError("contest_not_found", "Invalid or non-existent contest selected")
+		}
+	
+		// Neither contest may have started: a started entry is locked in place
+		now := time.Now()
+		if !now.Before(oldContest.StartDate) {
+			tx.Rollback()
+			return conflictError("contest_started", "The current contest has already started")
+		}
+	
+		if newContest.Status != "active" || !now.Before(newContest.StartDate) || newContest.IsLocked {
+			tx.Rollback()
+			return conflictError("contest_not_open", "The new contest is not open for entries")
+		}
+	
+		// Private contests are joined through their invite, never by switching
+		if newContest.IsPrivate {
+			tx.Rollback()
+			return forbiddenError("private_contest", "Entries cannot be switched into a private contest")
+		}
+	
+		// Check that the entry belongs to the user and is in the contest being left
+		var isParticipating bool
+		err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM user_contest WHERE id = ? AND user_id = ? AND contest_id = ?)", change.EntryID, userID, change.ContestID).Scan(&isParticipating)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	
+		if !isParticipating {
+			tx.Rollback()
+			return errNotInContest
+		}
+	
+		// The user must be eligible for the new contest and still under its entry cap
+		if !isUserEligible(db, userID, change.NewContestID) {
+			tx.Rollback()
+			return errNotEligible
+		}
+	
+		var userEntries int
+		err = tx.QueryRow("SELECT COUNT(*) FROM user_contest WHERE user_id = ? AND contest_id = ?", userID, change.NewContestID).Scan(&userEntries)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	
+		if userEntries >= newContest.MaxEntriesPerUser {
+			tx.Rollback()
+			return errMaxEntries
+		}
+	
+		// The existing lineup must satisfy the new contest's rules
+		lineup, err := getEntryLineup(tx, change.EntryID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	
+		if err := validateLineup(tx, change.NewContestID, lineup); err != nil {
+			tx.Rollback()
+			return err
+		}
+	
+		// Reserve a slot in the new contest and release the old one
+		if newContest.RemainingSlots <= 0 {
+			tx.Rollback()
+			return errContestFull
+		}
+	
+		_, err = tx.Exec("UPDATE contest SET remaining_slots = remaining_slots - 1 WHERE id = ?", change.NewContestID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	
+		_, err = tx.Exec("UPDATE contest SET remaining_slots = remaining_slots + 1 WHERE id = ?", change.ContestID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	
+		if err := promoteWaitlist(tx, change.ContestID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	
+		// Settle the entry fee difference: charge extra for a pricier contest, refund for a cheaper one
+		reason := fmt.Sprintf("Entry fee difference for switching from contest %d to %d", change.ContestID, change.NewContestID)
+		if diff := newContest.EntryFee - oldContest.EntryFee; diff > 0 {
+			err = debitWallet(tx, userID, diff, reason)
+		} else {
+			err = creditWallet(tx, userID, -diff, reason)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	
+		// Point the entry at the new contest
+		_, err = tx.Exec("UPDATE user_contest SET contest_id = ? WHERE id = ?", change.NewContestID, change.EntryID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	
+		// To webhook subscribers the switch is leaving one contest and entering the other
+		err = recordEntryEvent(tx, webhookEntryDeleted, change.EntryID, change.ContestID, userID)
+		if err == nil {
+			err = recordEntryEvent(tx, webhookEntryCreated, change.EntryID, change.NewContestID, userID)
+		}
+		if err == nil && newContest.RemainingSlots == 1 {
+			err = recordContestEvent(tx, webhookContestFilled, change.NewContestID, nil)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
 	
-		_, err := db.Exec("UPDATE user_contest SET contest_id = ? WHERE user_id = ?", newContestID, userID)
+		// Commit the transaction
+		err = tx.Commit()
 		if err != nil {
 			return err
 		}
@@ -657,59 +963,41 @@ func changeSelectedContest(db *sql.DB, userID int, newContestID int) error {
 		return nil
 	}
 	
+	// check if the new contest is valid and exists
+	func isContestValid(db *sql.DB, contestID int) bool {
+		var valid bool
+		err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM contest WHERE id = ?)", contestID).Scan(&valid)
+		if err != nil {
+			return false
+		}
+		return valid
+	}
+	
 	
-	func setupRoutes(r *gin.Engine, db *sql.DB) {
-		// Route to leave a contest
-		r.DELETE("/contests/leave/:userID", func(c *gin.Context) {
-			// Get the user ID from the URL parameter
-			userIDStr := c.Param("userID")
-			userID, err := strconv.Atoi(userIDStr)
+	func setupContestLeaveRoutes(r *gin.Engine, db *sql.DB) {
+		// Route to leave a contest with one specific entry
+		r.DELETE("/contests/leave/:contestID/:entryID", func(c *gin.Context) {
+			userID, err := currentUserID(c)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+				c.Error(err)
 				return
 			}
 	
-			// logic to allow users to leave a contest
-func leaveContest(db *sql.DB, userID int) error {
-    // Start a database transaction to ensure data consistency
-    tx, err := db.Begin()
-    if err != nil {
-        return err
-    }
-    defer func() {
-        if p := recover(); p != nil {
-            tx.Rollback()
-        }
-    }()
-
-    // logic to allow users to leave a contest here
-    // Option 1: Update the user's selected contest to null (assuming NULL is used to indicate no selection)
-    _, err = tx.Exec("UPDATE users SET selected_contest_id = NULL WHERE id = ?", userID)
-    if err != nil {
-        tx.Rollback()
-        return err
-    }
-
-    // Option 2: Delete the user's participation record in the user-contest relationship table
-    _, err = tx.Exec("DELETE FROM user_contest WHERE user_id = ?", userID)
-    if err != nil {
-        tx.Rollback()
-        return err
-    }
-
-    // Commit the transaction
-    err = tx.Commit()
-    if err != nil {
-        return err
-    }
-
-    return nil
-}
-
+			// Get the contest and entry IDs from the URL parameters
+			contestID, err := strconv.Atoi(c.Param("contestID"))
+			if err != nil {
+				c.Error(invalidParam("contestID", "Invalid contest ID"))
+				return
+			}
 	
-			// Assuming you have a function leaveContest(db *sql.DB, userID int) that handles contest leaving
-			if err := leaveContest(db, userID); err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave the contest"})
+			entryID, err := strconv.Atoi(c.Param("entryID"))
+			if err != nil {
+				c.Error(invalidParam("entryID", "Invalid entry ID"))
+				return
+			}
+	
+			if err := leaveContest(db, userID, contestID, entryID); err != nil {
+				c.Error(apiError(err, "Failed to leave the contest"))
 				return
 			}
 	
@@ -717,8 +1005,9 @@ func leaveContest(db *sql.DB, userID int) error {
 		})
 	}
 	
-	// leaveContest function that handles contest leaving
-	func leaveContest(db *sql.DB, userID int) error {
+	// leaveContest withdraws a single entry owned by the user and frees its slot.
+	// The user's other entries, in this contest or any other, are left untouched.
+	func leaveContest(db *sql.DB, userID int, contestID int, entryID int) error {
 		// Start a database transaction to ensure data consistency
 		tx, err := db.Begin()
 		if err != nil {
@@ -730,79 +1019,77 @@ func leaveContest(db *sql.DB, userID int) error {
 			}
 		}()
 	
-		// This may involve updating the user's contest selection or removing the user from the contest participation records
-		// logic to allow users to leave a contest
-func leaveContest(db *sql.DB, userID int, contestID int) error {
-    // Start a database transaction to ensure data consistency
-    tx, err := db.Begin()
-    if err != nil {
-        return err
-    }
-    defer func() {
-        if p := recover(); p != nil {
-            tx.Rollback()
-        }
-    }()
-
-    // Check if the user is currently participating in the contest
-    var isParticipating bool
-    err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM user_contest WHERE user_id = ? AND contest_id = ?)", userID, contestID).Scan(&isParticipating)
-    if err != nil {
-        tx.Rollback()
-        return err
-    }
-
-    if !isParticipating {
-        tx.Rollback()
-        return errors.New("User is not participating in the contest")
-    }
-
-    // logic to allow users to leave a contest here
-
-    // Option 1: Update the user's selected contest to null (assuming NULL is used to indicate no selection)
-    _, err = tx.Exec("UPDATE users SET selected_contest_id = NULL WHERE id = ?", userID)
-    if err != nil {
-        tx.Rollback()
-        return err
-    }
-
-    // Option 2: Delete the user's participation record in the user-contest relationship table
-    _, err = tx.Exec("DELETE FROM user_contest WHERE user_id = ? AND contest_id = ?", userID, contestID)
-    if err != nil {
-        tx.Rollback()
-        return err
-    }
-
-    // Commit the transaction
-    err = tx.Commit()
-    if err != nil {
-        return err
-    }
-
-    return nil
-}
-
+		if err := removeEntry(tx, userID, contestID, entryID); err != nil {
+			tx.Rollback()
+			return err
+		}
 	
-		// For example, you can update the user's selected contest to null (assuming NULL is used to indicate no selection):
-		_, err = tx.Exec("UPDATE users SET selected_contest_id = NULL WHERE id = ?", userID)
+		// Commit the transaction
+		err = tx.Commit()
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 	
-		// Or you can delete the user's participation record in the user-contest relationship table:
-		_, err = tx.Exec("DELETE FROM user_contest WHERE user_id = ?", userID)
+		return nil
+	}
+	
+	// removeEntry deletes one of the user's entries inside tx, gives its slot back to the
+	// contest, refunds the entry fee and offers the slot to the waitlist
+	func removeEntry(tx *sql.Tx, userID int, contestID int, entryID int) error {
+		// Check that the entry exists, belongs to the user and is in the given contest
+		var isParticipating bool
+		err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM user_contest WHERE id = ? AND user_id = ? AND contest_id = ?)", entryID, userID, contestID).Scan(&isParticipating)
 		if err != nil {
-			tx.Rollback()
 			return err
 		}
 	
-		// Commit the transaction
-		err = tx.Commit()
+		if !isParticipating {
+			return errNotInContest
+		}
+	
+		// Entries are locked in once the contest starts
+		var startDate time.Time
+		err = tx.QueryRow("SELECT start_date FROM contest WHERE id = ? FOR UPDATE", contestID).Scan(&startDate)
 		if err != nil {
 			return err
 		}
 	
-		return nil
-	}
+		if !time.Now().Before(startDate) {
+			return errContestStarted
+		}
 	
+		// Delete the entry's lineup and the participation record itself
+		_, err = tx.Exec("DELETE FROM entry_lineup WHERE entry_id = ?", entryID)
+		if err != nil {
+			return err
+		}
+	
+		_, err = tx.Exec("DELETE FROM user_contest WHERE id = ?", entryID)
+		if err != nil {
+			return err
+		}
+	
+		// Give the slot back to the contest
+		_, err = tx.Exec("UPDATE contest SET remaining_slots = remaining_slots + 1 WHERE id = ?", contestID)
+		if err != nil {
+			return err
+		}
+	
+		// Refund the entry fee
+		var entryFee float64
+		err = tx.QueryRow("SELECT entry_fee FROM contest WHERE id = ?", contestID).Scan(&entryFee)
+		if err != nil {
+			return err
+		}
+	
+		if err := creditWallet(tx, userID, entryFee, fmt.Sprintf("Refund for leaving contest %d", contestID)); err != nil {
+			return err
+		}
+	
+		if err := recordEntryEvent(tx, webhookEntryDeleted, entryID, contestID, userID); err != nil {
+			return err
+		}
+	
+		// Offer the freed slot to the next user on the waitlist
+		return promoteWaitlist(tx, contestID)
+	}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+)
+
+// errorKind classifies an APIError; each kind maps to one HTTP status
+type errorKind int
+
+const (
+	kindInternal errorKind = iota
+	kindValidation
+	kindUnauthorized
+	kindForbidden
+	kindNotFound
+	kindConflict
+	kindUnprocessable
+	kindUnavailable
+)
+
+// requestIDHeader carries the ID that ties a response to the server's logs. Clients may
+// send their own; otherwise one is generated.
+const requestIDHeader = "X-Request-ID"
+
+// requestIDKey is the gin context key holding the request ID
+const requestIDKey = "request_id"
+
+// maxRequestIDLength bounds client-supplied request IDs so they stay safe to log
+const maxRequestIDLength = 64
+
+// FieldError describes what is wrong with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError is an error returned to the client. Code is stable and meant for programs;
+// Message is meant for people and may change. Err keeps the underlying cause for logs.
+type APIError struct {
+	Kind    errorKind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is match any APIError with the same code, so sentinel errors such as
+// errContestFull can be compared even after being wrapped
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	return ok && t.Code == e.Code
+}
+
+// errorResponse is the JSON body of every failed request
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id"`
+}
+
+func validationError(code, message string, fields ...FieldError) *APIError {
+	return &APIError{Kind: kindValidation, Code: code, Message: message, Fields: fields}
+}
+
+func unauthorizedError(code, message string) *APIError {
+	return &APIError{Kind: kindUnauthorized, Code: code, Message: message}
+}
+
+func forbiddenError(code, message string) *APIError {
+	return &APIError{Kind: kindForbidden, Code: code, Message: message}
+}
+
+func notFoundError(code, message string) *APIError {
+	return &APIError{Kind: kindNotFound, Code: code, Message: message}
+}
+
+func conflictError(code, message string) *APIError {
+	return &APIError{Kind: kindConflict, Code: code, Message: message}
+}
+
+func unprocessableError(code, message string) *APIError {
+	return &APIError{Kind: kindUnprocessable, Code: code, Message: message}
+}
+
+func unavailableError(code, message string, err error) *APIError {
+	return &APIError{Kind: kindUnavailable, Code: code, Message: message, Err: err}
+}
+
+// fieldError builds the detail of one invalid request field
+func fieldError(field, message string) FieldError {
+	return FieldError{Field: field, Message: message}
+}
+
+// invalidField reports a validation error caused by a single request field
+func invalidField(code, field, message string) *APIError {
+	return validationError(code, message, fieldError(field, message))
+}
+
+// invalidParam reports a malformed path or query parameter
+func invalidParam(param, message string) *APIError {
+	return invalidField("invalid_parameter", param, message)
+}
+
+// invalidBody reports a request body that could not be decoded
+func invalidBody(err error) *APIError {
+	return &APIError{Kind: kindValidation, Code: "invalid_body", Message: "Invalid request body: " + err.Error(), Err: err}
+}
+
+// apiError returns err as an APIError. Errors that are not already one are internal
+// failures reported with message, except lost database connections and timeouts,
+// which are reported as unavailable so clients know to retry.
+func apiError(err error, message string) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return unavailableError("service_unavailable", "The service is temporarily unavailable, please retry", err)
+	}
+
+	return &APIError{Kind: kindInternal, Code: "internal_error", Message: message, Err: err}
+}
+
+// errorStatus returns the HTTP status of an error kind
+func errorStatus(kind errorKind) int {
+	switch kind {
+	case kindValidation:
+		return http.StatusBadRequest
+	case kindUnauthorized:
+		return http.StatusUnauthorized
+	case kindForbidden:
+		return http.StatusForbidden
+	case kindNotFound:
+		return http.StatusNotFound
+	case kindConflict:
+		return http.StatusConflict
+	case kindUnprocessable:
+		return http.StatusUnprocessableEntity
+	case kindUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// requestIDMiddleware gives every request an ID, echoed back in the X-Request-ID header
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+
+		c.Set(requestIDKey, requestID)
+		c.Header(requestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// newRequestID returns a random 128-bit request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// errorMiddleware renders the last error a handler recorded with c.Error, unless the
+// handler already wrote a response
+func errorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		abortWithError(c, c.Errors.Last().Err)
+	}
+}
+
+// abortWithError writes err as the response and stops the handler chain. Internal and
+// unavailable errors are logged with their cause; clients only see the message.
+func abortWithError(c *gin.Context, err error) {
+	apiErr := apiError(err, "Internal server error")
+	requestID := c.GetString(requestIDKey)
+
+	if apiErr.Kind == kindInternal || apiErr.Kind == kindUnavailable {
+		log.Printf("%s %s [%s]: %s: %v", c.Request.Method, c.Request.URL.Path, requestID, apiErr.Message, apiErr.Err)
+	}
+
+	c.AbortWithStatusJSON(errorStatus(apiErr.Kind), errorResponse{Error: errorBody{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Fields:    apiErr.Fields,
+		RequestID: requestID,
+	}})
+}
@@ -0,0 +1,589 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Kinds of entries in an auction's bid log
+const (
+	bidNomination = "nomination"
+	bidRaise      = "bid"
+)
+
+// AuctionLot is the player currently up for bidding in an auction draft
+type AuctionLot struct {
+	PlayerID      int        `json:"player_id"`
+	HighBid       int        `json:"high_bid"`
+	HighBidTeamID int        `json:"high_bid_team_id"`
+	Deadline      *time.Time `json:"deadline,omitempty"`
+}
+
+// DraftBid is one entry in an auction's bid log. Rejected bids are logged too, with
+// the reason, so disputes can be settled from the log alone.
+type DraftBid struct {
+	ID         int       `json:"id"`
+	Nomination int       `json:"nomination"`
+	TeamID     int       `json:"team_id"`
+	PlayerID   int       `json:"player_id"`
+	Amount     int       `json:"amount"`
+	Kind       string    `json:"kind"`
+	Accepted   bool      `json:"accepted"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NominationRequest is the request body used to put a player up for auction
+type NominationRequest struct {
+	PlayerID int `json:"player_id"`
+	Amount   int `json:"amount"`
+}
+
+// BidRequest is the request body used to bid on the player up for auction
+type BidRequest struct {
+	Amount int `json:"amount"`
+}
+
+func setupAuctionRoutes(r *gin.Engine, db *sql.DB, drafts *draftManager) {
+	// Route for the team whose turn it is to nominate a player with an opening bid
+	r.POST("/drafts/:id/nominations", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		draftID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid draft ID"))
+			return
+		}
+
+		var req NominationRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		lot, err := drafts.nominate(draftID, userID, req.PlayerID, req.Amount)
+		if err != nil {
+			c.Error(apiError(err, "Failed to nominate the player"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, lot)
+	})
+
+	// Route to bid on the player up for auction
+	r.POST("/drafts/:id/bids", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		draftID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid draft ID"))
+			return
+		}
+
+		var req BidRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		lot, err := drafts.bid(draftID, userID, req.Amount)
+		if err != nil {
+			c.Error(apiError(err, "Failed to place the bid"))
+			return
+		}
+
+		c.JSON(http.StatusOK, lot)
+	})
+
+	// Route to fetch the full bid log of an auction
+	r.GET("/drafts/:id/bids", func(c *gin.Context) {
+		draftID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid draft ID"))
+			return
+		}
+
+		bids, err := getDraftBids(db, draftID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch the bid log"))
+			return
+		}
+
+		c.JSON(http.StatusOK, bids)
+	})
+}
+
+// maxBid is the most a team can bid while still being able to fill each of its other
+// open roster spots at the minimum bid
+func maxBid(budget int, filled int, rosterSpots int, minBid int) int {
+	return budget - (rosterSpots-filled-1)*minBid
+}
+
+// auctionNominator returns the team whose turn it is to nominate. Turns go round the
+// order; teams with a full roster are skipped. It returns 0 once every roster is full.
+func auctionNominator(order []int, filled map[int]int, rosterSpots int, nomination int) int {
+	n := len(order)
+	for i := 0; i < n; i++ {
+		teamID := order[(nomination-1+i)%n]
+		if filled[teamID] < rosterSpots {
+			return teamID
+		}
+	}
+
+	return 0
+}
+
+// auctionTeams returns each team's remaining budget and number of players bought
+func auctionTeams(q queryer, draftID int) (map[int]int, map[int]int, error) {
+	rows, err := q.Query(
+		"SELECT o.team_id, o.budget, COUNT(p.player_id) FROM draft_order o LEFT JOIN draft_pick p ON p.draft_id = o.draft_id AND p.team_id = o.team_id WHERE o.draft_id = ? GROUP BY o.team_id, o.budget",
+		draftID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	budgets := make(map[int]int)
+	filled := make(map[int]int)
+	for rows.Next() {
+		var teamID, budget, players int
+		if err := rows.Scan(&teamID, &budget, &players); err != nil {
+			return nil, nil, err
+		}
+		budgets[teamID] = budget
+		filled[teamID] = players
+	}
+
+	return budgets, filled, rows.Err()
+}
+
+// draftTeamForUser returns the user's team in the draft
+func draftTeamForUser(q queryer, draftID int, userID int) (int, error) {
+	var teamID int
+	err := q.QueryRow(
+		"SELECT o.team_id FROM draft_order o JOIN team t ON t.id = o.team_id WHERE o.draft_id = ? AND t.owner_id = ?",
+		draftID, userID,
+	).Scan(&teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errNotInDraft
+		}
+		return 0, err
+	}
+
+	return teamID, nil
+}
+
+// nominate puts a player up for auction for the team owned by userID, which must be
+// the team whose turn it is to nominate. The amount is that team's opening bid.
+func (m *draftManager) nominate(draftID int, userID int, playerID int, amount int) (*AuctionLot, error) {
+	// Start a transaction to ensure consistency
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	d, err := lockDraft(tx, draftID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	teamID, err := draftTeamForUser(tx, draftID, userID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	lot, err := placeNomination(tx, d, teamID, playerID, amount)
+	if err != nil {
+		tx.Rollback()
+		m.logRejectedBid(d, teamID, playerID, amount, bidNomination, err)
+		return nil, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	m.afterNomination(draftID, d.CurrentPick, lot)
+
+	return lot, nil
+}
+
+// autoNominate nominates for the team whose nomination clock ran out, taking the next
+// player from its queue or the rankings at the minimum bid. It does nothing if that
+// nomination was already made.
+func (m *draftManager) autoNominate(draftID int, nomination int) error {
+	// Start a transaction to ensure consistency
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	d, err := lockDraft(tx, draftID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if d.Status != draftInProgress || d.Type != draftAuction || d.CurrentPick != nomination || d.LotPlayerID != 0 {
+		tx.Rollback()
+		return nil
+	}
+
+	_, filled, err := auctionTeams(tx, draftID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	teamID := auctionNominator(d.Order, filled, d.Rounds, d.CurrentPick)
+	playerID, err := nextAutoPick(tx, draftID, teamID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	lot, err := placeNomination(tx, d, teamID, playerID, d.MinBid)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	m.afterNomination(draftID, d.CurrentPick, lot)
+
+	return nil
+}
+
+// placeNomination opens bidding on a player with the nominating team's opening bid
+// and starts the bidding clock
+func placeNomination(tx *sql.Tx, d *draftState, teamID int, playerID int, amount int) (*AuctionLot, error) {
+	if d.Status != draftInProgress || d.Type != draftAuction {
+		return nil, conflictError("draft_not_nominating", "The draft is not taking nominations")
+	}
+	if d.LotPlayerID != 0 {
+		return nil, conflictError("lot_open", "A player is already up for auction")
+	}
+
+	budgets, filled, err := auctionTeams(tx, d.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if auctionNominator(d.Order, filled, d.Rounds, d.CurrentPick) != teamID {
+		return nil, conflictError("not_your_turn", "It is not your turn to nominate")
+	}
+
+	if err := checkDraftable(tx, d.ID, playerID); err != nil {
+		return nil, err
+	}
+
+	if amount < d.MinBid {
+		return nil, invalidField("bid_too_low", "amount", fmt.Sprintf("The opening bid must be at least %d", d.MinBid))
+	}
+	if limit := maxBid(budgets[teamID], filled[teamID], d.Rounds, d.MinBid); amount > limit {
+		return nil, invalidField("bid_too_high", "amount", fmt.Sprintf("Your maximum bid is %d", limit))
+	}
+
+	deadline := time.Now().Add(time.Duration(d.BidSeconds) * time.Second)
+	_, err = tx.Exec(
+		"UPDATE draft SET lot_player_id = ?, lot_bid = ?, lot_team_id = ?, pick_deadline = ? WHERE id = ?",
+		playerID, amount, teamID, deadline, d.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO draft_bid (draft_id, nomination, team_id, player_id, amount, kind, accepted, created_at) VALUES (?, ?, ?, ?, ?, ?, TRUE, NOW(3))",
+		d.ID, d.CurrentPick, teamID, playerID, amount, bidNomination,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuctionLot{PlayerID: playerID, HighBid: amount, HighBidTeamID: teamID, Deadline: &deadline}, nil
+}
+
+// bid raises the high bid on the player up for auction for the team owned by userID.
+// A bid in the last seconds of the clock puts the clock back to the anti-sniping window.
+func (m *draftManager) bid(draftID int, userID int, amount int) (*AuctionLot, error) {
+	// Start a transaction to ensure consistency
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	d, err := lockDraft(tx, draftID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	teamID, err := draftTeamForUser(tx, draftID, userID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	lot, err := placeBid(tx, d, teamID, amount)
+	if err != nil {
+		tx.Rollback()
+		m.logRejectedBid(d, teamID, d.LotPlayerID, amount, bidRaise, err)
+		return nil, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	m.hub.broadcast(draftID, draftEvent{Type: draftEventBid, Lot: lot})
+	m.startLotTimer(draftID, d.CurrentPick, *lot.Deadline)
+
+	return lot, nil
+}
+
+// placeBid checks a bid against the open lot and the team's budget and records it
+func placeBid(tx *sql.Tx, d *draftState, teamID int, amount int) (*AuctionLot, error) {
+	if d.Status != draftInProgress || d.Type != draftAuction || d.LotPlayerID == 0 {
+		return nil, conflictError("no_open_lot", "No player is up for auction")
+	}
+
+	// The clock is checked here too, since the timer closing the lot may be a moment late
+	now := time.Now()
+	if d.Deadline == nil || !now.Before(*d.Deadline) {
+		return nil, conflictError("bidding_closed", "Bidding has closed")
+	}
+
+	if teamID == d.LotTeamID {
+		return nil, conflictError("already_high_bidder", "You already hold the high bid")
+	}
+	if amount <= d.LotBid {
+		return nil, invalidField("bid_too_low", "amount", fmt.Sprintf("Bids must beat the high bid of %d", d.LotBid))
+	}
+
+	budgets, filled, err := auctionTeams(tx, d.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if filled[teamID] >= d.Rounds {
+		return nil, conflictError("roster_full", "Your roster is full")
+	}
+	if limit := maxBid(budgets[teamID], filled[teamID], d.Rounds, d.MinBid); amount > limit {
+		return nil, invalidField("bid_too_high", "amount", fmt.Sprintf("Your maximum bid is %d", limit))
+	}
+
+	deadline := *d.Deadline
+	if window := time.Duration(d.AntiSnipeSeconds) * time.Second; deadline.Sub(now) < window {
+		deadline = now.Add(window)
+	}
+
+	_, err = tx.Exec("UPDATE draft SET lot_bid = ?, lot_team_id = ?, pick_deadline = ? WHERE id = ?", amount, teamID, deadline, d.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO draft_bid (draft_id, nomination, team_id, player_id, amount, kind, accepted, created_at) VALUES (?, ?, ?, ?, ?, ?, TRUE, NOW(3))",
+		d.ID, d.CurrentPick, teamID, d.LotPlayerID, amount, bidRaise,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuctionLot{PlayerID: d.LotPlayerID, HighBid: amount, HighBidTeamID: teamID, Deadline: &deadline}, nil
+}
+
+// closeLot sells the player up for auction to the high bidder once the bidding clock
+// runs out, then hands the nomination to the next team or completes the draft
+func (m *draftManager) closeLot(draftID int, nomination int) error {
+	// Start a transaction to ensure consistency
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	d, err := lockDraft(tx, draftID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if d.Status != draftInProgress || d.Type != draftAuction || d.CurrentPick != nomination || d.LotPlayerID == 0 {
+		tx.Rollback()
+		return nil
+	}
+
+	// A late bid moved the clock after this timer fired; wait for the new deadline
+	if d.Deadline != nil && time.Now().Before(*d.Deadline) {
+		tx.Rollback()
+		m.startLotTimer(draftID, nomination, *d.Deadline)
+		return nil
+	}
+
+	pick, err := recordPick(tx, d, d.LotTeamID, d.LotPlayerID, false, d.LotBid)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE draft_order SET budget = budget - ? WHERE draft_id = ? AND team_id = ?", d.LotBid, draftID, d.LotTeamID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, filled, err := auctionTeams(tx, draftID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	next := d.CurrentPick + 1
+	nominator := auctionNominator(d.Order, filled, d.Rounds, next)
+
+	var deadline time.Time
+	if nominator == 0 {
+		_, err = tx.Exec(
+			"UPDATE draft SET status = ?, current_pick = ?, lot_player_id = NULL, lot_bid = 0, lot_team_id = NULL, pick_deadline = NULL, completed_at = NOW() WHERE id = ?",
+			draftCompleted, next, draftID,
+		)
+	} else {
+		deadline = time.Now().Add(time.Duration(d.PickSeconds) * time.Second)
+		_, err = tx.Exec(
+			"UPDATE draft SET current_pick = ?, lot_player_id = NULL, lot_bid = 0, lot_team_id = NULL, pick_deadline = ? WHERE id = ?",
+			next, deadline, draftID,
+		)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	m.hub.broadcast(draftID, draftEvent{Type: draftEventPick, Pick: pick})
+
+	if nominator == 0 {
+		m.stopTimer(draftID)
+		m.hub.broadcast(draftID, draftEvent{Type: draftEventCompleted})
+		return nil
+	}
+
+	m.onTheNomination(draftID, next, nominator, deadline)
+
+	return nil
+}
+
+// onTheNomination announces the team due to nominate and nominates for it at the deadline
+func (m *draftManager) onTheNomination(draftID int, nomination int, teamID int, deadline time.Time) {
+	m.hub.broadcast(draftID, draftEvent{Type: draftEventOnTheClock, PickNumber: nomination, TeamID: teamID, Deadline: &deadline})
+
+	m.startTimer(draftID, deadline, m.retrying(draftID, nomination, "auto-nomination", func() error {
+		return m.autoNominate(draftID, nomination)
+	}))
+}
+
+// afterNomination announces a new lot and starts its bidding clock
+func (m *draftManager) afterNomination(draftID int, nomination int, lot *AuctionLot) {
+	m.hub.broadcast(draftID, draftEvent{Type: draftEventNominated, PickNumber: nomination, Lot: lot})
+	m.startLotTimer(draftID, nomination, *lot.Deadline)
+}
+
+// startLotTimer closes the lot when its bidding clock runs out
+func (m *draftManager) startLotTimer(draftID int, nomination int, deadline time.Time) {
+	m.startTimer(draftID, deadline, m.retrying(draftID, nomination, "closing lot", func() error {
+		return m.closeLot(draftID, nomination)
+	}))
+}
+
+// logRejectedBid records a bid or nomination that was turned down. It runs after the
+// bid's transaction rolled back, so a failure here only loses the log line.
+func (m *draftManager) logRejectedBid(d *draftState, teamID int, playerID int, amount int, kind string, reason error) {
+	var player sql.NullInt64
+	if playerID != 0 {
+		player = sql.NullInt64{Int64: int64(playerID), Valid: true}
+	}
+
+	_, err := m.db.Exec(
+		"INSERT INTO draft_bid (draft_id, nomination, team_id, player_id, amount, kind, accepted, reason, created_at) VALUES (?, ?, ?, ?, ?, ?, FALSE, ?, NOW(3))",
+		d.ID, d.CurrentPick, teamID, player, amount, kind, reason.Error(),
+	)
+	if err != nil {
+		log.Printf("draft: failed to log rejected %s in draft %d: %v", kind, d.ID, err)
+	}
+}
+
+// getDraftBids returns the bid log of a draft in the order the bids were made
+func getDraftBids(db *sql.DB, draftID int) ([]DraftBid, error) {
+	rows, err := db.Query(
+		"SELECT id, nomination, team_id, IFNULL(player_id, 0), amount, kind, accepted, IFNULL(reason, ''), created_at FROM draft_bid WHERE draft_id = ? ORDER BY id",
+		draftID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bids := []DraftBid{}
+	for rows.Next() {
+		var b DraftBid
+		if err := rows.Scan(&b.ID, &b.Nomination, &b.TeamID, &b.PlayerID, &b.Amount, &b.Kind, &b.Accepted, &b.Reason, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		bids = append(bids, b)
+	}
+
+	return bids, rows.Err()
+}
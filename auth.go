@@ -0,0 +1,56 @@
+package main
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errNotOperator = forbiddenError("not_operator", "Only operators can do this")
+
+// userIDHeader carries the authenticated user's ID, set by the API gateway in front of this service
+const userIDHeader = "X-User-ID"
+
+// currentUserID returns the ID of the user making the request
+func currentUserID(c *gin.Context) (int, error) {
+	userID, err := strconv.Atoi(c.GetHeader(userIDHeader))
+	if err != nil || userID <= 0 {
+		return 0, unauthorizedError("unauthenticated", "Missing or invalid user ID")
+	}
+
+	return userID, nil
+}
+
+// isOperator reports whether the user is one of the site's operators, who manage
+// templates, scores and contests on behalf of the site
+func isOperator(q queryer, userID int) (bool, error) {
+	var operator bool
+	err := q.QueryRow("SELECT is_operator FROM users WHERE id = ?", userID).Scan(&operator)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return operator, err
+}
+
+// operatorRequest reads the current user of an operator route and checks they are an
+// operator, writing the error response itself when they are not
+func operatorRequest(c *gin.Context, db *sql.DB) (int, bool) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return 0, false
+	}
+
+	operator, err := isOperator(db, userID)
+	if err != nil {
+		c.Error(apiError(err, "Failed to check the account"))
+		return 0, false
+	}
+	if !operator {
+		c.Error(errNotOperator)
+		return 0, false
+	}
+
+	return userID, true
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ListContests returns the lobby, one row per contest series
+func (c *Client) ListContests(ctx context.Context) ([]LobbyContest, error) {
+	var contests []LobbyContest
+	err := c.do(ctx, http.MethodGet, "/contests", nil, &contests)
+	return contests, err
+}
+
+// CreateContest creates a contest
+func (c *Client) CreateContest(ctx context.Context, req CreateContestRequest) (*Contest, error) {
+	var contest Contest
+	if err := c.do(ctx, http.MethodPost, "/contests", req, &contest); err != nil {
+		return nil, err
+	}
+	return &contest, nil
+}
+
+// GetContest fetches a contest
+func (c *Client) GetContest(ctx context.Context, contestID int) (*Contest, error) {
+	var contest Contest
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/contests/%d", contestID), nil, &contest); err != nil {
+		return nil, err
+	}
+	return &contest, nil
+}
+
+// GetLeaderboard ranks a contest's entries by fantasy points, highest first
+func (c *Client) GetLeaderboard(ctx context.Context, contestID int) ([]LeaderboardEntry, error) {
+	var leaderboard []LeaderboardEntry
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/contests/%d/leaderboard", contestID), nil, &leaderboard)
+	return leaderboard, err
+}
+
+// CreateTeam creates a team
+func (c *Client) CreateTeam(ctx context.Context, req CreateTeamRequest) (*Team, error) {
+	var team Team
+	if err := c.do(ctx, http.MethodPost, "/teams", req, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// ListTeams returns the teams that are not part of a league
+func (c *Client) ListTeams(ctx context.Context) ([]Team, error) {
+	var teams []Team
+	err := c.do(ctx, http.MethodGet, "/teams", nil, &teams)
+	return teams, err
+}
+
+// GetTeam fetches a team
+func (c *Client) GetTeam(ctx context.Context, teamID int) (*Team, error) {
+	var team Team
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/teams/%d", teamID), nil, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// EnterContest enters the client's user into a contest with a lineup and returns the
+// new entry's ID
+func (c *Client) EnterContest(ctx context.Context, req EnterContestRequest) (int, error) {
+	var resp struct {
+		EntryID int `json:"entry_id"`
+	}
+	err := c.do(ctx, http.MethodPost, "/contests/enter", req, &resp)
+	return resp.EntryID, err
+}
+
+// ChangeContest moves one of the user's entries from contestID to newContestID
+func (c *Client) ChangeContest(ctx context.Context, entryID, contestID, newContestID int) error {
+	req := struct {
+		ContestID    int `json:"contest_id"`
+		EntryID      int `json:"entry_id"`
+		NewContestID int `json:"new_contest_id"`
+	}{contestID, entryID, newContestID}
+	return c.do(ctx, http.MethodPut, "/contests/change", req, nil)
+}
+
+// LeaveContest withdraws one of the user's entries and refunds its entry fee
+func (c *Client) LeaveContest(ctx context.Context, contestID, entryID int) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/contests/leave/%d/%d", contestID, entryID), nil, nil)
+}
+
+// ListMyEntries returns the user's entries across all contests
+func (c *Client) ListMyEntries(ctx context.Context) ([]Entry, error) {
+	var entries []Entry
+	err := c.do(ctx, http.MethodGet, "/me/entries", nil, &entries)
+	return entries, err
+}
+
+// GetEntryLineup returns one of the user's lineups with its lock state and points
+func (c *Client) GetEntryLineup(ctx context.Context, entryID int) (*EntryLineup, error) {
+	var lineup EntryLineup
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/me/entries/%d/lineup", entryID), nil, &lineup); err != nil {
+		return nil, err
+	}
+	return &lineup, nil
+}
+
+// UpdateLineup replaces the lineup and roles of one of the user's entries. Players whose
+// game has started cannot be swapped out.
+func (c *Client) UpdateLineup(ctx context.Context, entryID int, lineup []int, roles LineupRoles) error {
+	req := struct {
+		EntryID int         `json:"entry_id"`
+		Lineup  []int       `json:"lineup"`
+		Roles   LineupRoles `json:"roles"`
+	}{entryID, lineup, roles}
+	return c.do(ctx, http.MethodPut, "/contests/lineup", req, nil)
+}
@@ -0,0 +1,226 @@
+// Package client is a Go client for the contest and team API. It only depends on the
+// standard library so other services can vendor it without pulling in the server.
+//
+//	c := client.New("http://fantasy:8080", client.WithUserID(42))
+//	contest, err := c.GetContest(ctx, 7)
+//	if client.IsCode(err, client.CodeContestNotFound) {
+//		...
+//	}
+//
+// Mutating calls made for a user (WithUserID or ForUser) send an Idempotency-Key, so
+// they are retried safely after network errors and 503s: the server replays the first
+// response instead of running the request twice. The server only accepts keys from
+// users, so anonymous mutating calls are sent once and never retried.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	userIDHeader      = "X-User-ID"
+	idempotencyHeader = "Idempotency-Key"
+	requestIDHeader   = "X-Request-ID"
+
+	defaultMaxRetries = 3
+	defaultBackoff    = 200 * time.Millisecond
+	maxBackoff        = 5 * time.Second
+)
+
+// Client calls the API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	userID     int
+	maxRetries int
+	backoff    time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used to send requests
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithUserID sets the user the requests are made for
+func WithUserID(userID int) Option {
+	return func(c *Client) {
+		c.userID = userID
+	}
+}
+
+// WithRetries sets how many times a failed request is retried and the backoff before
+// the first retry; the backoff doubles on every further retry
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// New returns a client for the API at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: defaultMaxRetries,
+		backoff:    defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ForUser returns a copy of the client that makes requests for another user
+func (c *Client) ForUser(userID int) *Client {
+	cp := *c
+	cp.userID = userID
+	return &cp
+}
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey makes the mutating call made with ctx use key instead of a random
+// one. Reusing the key across calls, e.g. when a job restarts, turns a repeated call into
+// a replay of the first response.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// do sends a request and decodes the JSON response into out, retrying failures that
+// are safe to retry. body and out may be nil.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	// GETs are naturally idempotent; every other method made for a user gets a key that
+	// stays the same across retries
+	var key string
+	if method != http.MethodGet && c.userID != 0 {
+		key, _ = ctx.Value(idempotencyKeyContextKey{}).(string)
+		if key == "" {
+			key = newIdempotencyKey()
+		}
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.send(ctx, method, path, key, payload, out)
+		if err == nil || attempt >= c.maxRetries || !retryable(err) || (method != http.MethodGet && key == "") {
+			return err
+		}
+
+		wait := backoff
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send makes a single attempt. It returns the server's Retry-After delay, if any.
+func (c *Client) send(ctx context.Context, method, path, key string, payload []byte, out interface{}) (time.Duration, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userID != 0 {
+		req.Header.Set(userIDHeader, strconv.Itoa(c.userID))
+	}
+	if key != "" {
+		req.Header.Set(idempotencyHeader, key)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+		return time.Duration(retryAfter) * time.Second, decodeError(resp, data)
+	}
+
+	if out == nil || len(data) == 0 {
+		return 0, nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return 0, fmt.Errorf("client: decoding the response of %s %s: %w", method, path, err)
+	}
+	return 0, nil
+}
+
+// retryable reports whether a failed attempt may be sent again. do only asks for GETs
+// and requests with an idempotency key, the requests that are safe to send twice.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout, http.StatusTooManyRequests:
+		return true
+	}
+	// The first attempt is still being processed; retrying picks up its stored response
+	return apiErr.Code == CodeIdempotencyKeyInUse
+}
+
+// newIdempotencyKey returns a random 128-bit key
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
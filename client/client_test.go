@@ -0,0 +1,241 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// teamServer answers POST /teams like the server: it replays the stored response of a
+// repeated Idempotency-Key from the same user, and fails the first failures attempts
+// with status
+type teamServer struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	keys     []string
+	created  int
+	replays  map[string][]byte
+}
+
+func newTeamServer() *teamServer {
+	return &teamServer{replays: map[string][]byte{}}
+}
+
+func (s *teamServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := r.Header.Get(idempotencyHeader)
+	s.keys = append(s.keys, key)
+
+	if s.failures > 0 {
+		s.failures--
+		writeError(w, s.status, CodeIdempotencyKeyInUse, "A request with this Idempotency-Key is still being processed")
+		return
+	}
+
+	scoped := r.Header.Get(userIDHeader) + "\x00" + key
+	if body, ok := s.replays[scoped]; ok {
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+		return
+	}
+
+	var req CreateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidBody, err.Error())
+		return
+	}
+
+	s.created++
+	body, _ := json.Marshal(Team{ID: s.created, Name: req.Name})
+	s.replays[scoped] = body
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string, fields ...FieldError) {
+	var body errorResponse
+	body.Error.Code = code
+	body.Error.Message = message
+	body.Error.Fields = fields
+	body.Error.RequestID = "req-1"
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func TestRetriesServiceUnavailable(t *testing.T) {
+	srv := newTeamServer()
+	ts := NewTestServer(srv)
+	defer ts.Close()
+
+	ts.FailNext(2, http.StatusServiceUnavailable)
+	team, err := ts.Client(WithUserID(1)).CreateTeam(context.Background(), CreateTeamRequest{Name: "Hawks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if team.Name != "Hawks" {
+		t.Errorf("team name = %q, want Hawks", team.Name)
+	}
+	if len(srv.keys) != 1 || srv.created != 1 {
+		t.Errorf("server saw %d attempts and created %d teams, want 1 and 1", len(srv.keys), srv.created)
+	}
+}
+
+func TestRetriesGiveUpAfterMaxRetries(t *testing.T) {
+	ts := NewTestServer(newTeamServer())
+	defer ts.Close()
+
+	ts.FailNext(10, http.StatusServiceUnavailable)
+	_, err := ts.Client(WithUserID(1), WithRetries(2, time.Millisecond)).CreateTeam(context.Background(), CreateTeamRequest{Name: "Hawks"})
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an *Error", err)
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Code != CodeServiceUnavailable {
+		t.Errorf("got %d %s, want 503 %s", apiErr.StatusCode, apiErr.Code, CodeServiceUnavailable)
+	}
+}
+
+func TestRetriesKeyInUseWithTheSameKey(t *testing.T) {
+	srv := newTeamServer()
+	srv.failures = 2
+	srv.status = http.StatusConflict
+	ts := NewTestServer(srv)
+	defer ts.Close()
+
+	if _, err := ts.Client(WithUserID(1)).CreateTeam(context.Background(), CreateTeamRequest{Name: "Hawks"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.keys) != 3 {
+		t.Fatalf("server saw %d attempts, want 3", len(srv.keys))
+	}
+	for _, key := range srv.keys {
+		if key == "" || key != srv.keys[0] {
+			t.Fatalf("attempts sent the keys %q, want one non-empty key", srv.keys)
+		}
+	}
+}
+
+func TestAnonymousCallsSendNoKeyAndAreNotRetried(t *testing.T) {
+	srv := newTeamServer()
+	ts := NewTestServer(srv)
+	defer ts.Close()
+
+	ts.FailNext(1, http.StatusServiceUnavailable)
+	if _, err := ts.Client().CreateTeam(context.Background(), CreateTeamRequest{Name: "Hawks"}); !IsCode(err, CodeServiceUnavailable) {
+		t.Fatalf("got %v, want %s", err, CodeServiceUnavailable)
+	}
+	if len(srv.keys) != 0 {
+		t.Fatalf("server saw %d attempts, want the failure injected in front of it only", len(srv.keys))
+	}
+
+	if _, err := ts.Client().CreateTeam(context.Background(), CreateTeamRequest{Name: "Hawks"}); err != nil {
+		t.Fatal(err)
+	}
+	if srv.keys[0] != "" {
+		t.Errorf("sent key %q, want none", srv.keys[0])
+	}
+}
+
+func TestIdempotencyKeyReplaysTheFirstResponse(t *testing.T) {
+	srv := newTeamServer()
+	ts := NewTestServer(srv)
+	defer ts.Close()
+
+	c := ts.Client(WithUserID(1))
+	ctx := WithIdempotencyKey(context.Background(), "import-7")
+
+	first, err := c.CreateTeam(ctx, CreateTeamRequest{Name: "Hawks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.CreateTeam(ctx, CreateTeamRequest{Name: "Hawks"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second.ID != first.ID || srv.created != 1 {
+		t.Errorf("got teams %d and %d with %d created, want one team replayed", first.ID, second.ID, srv.created)
+	}
+	if srv.keys[0] != "import-7" {
+		t.Errorf("sent key %q, want import-7", srv.keys[0])
+	}
+
+	// Without a shared key every call is a new request
+	if _, err := c.CreateTeam(context.Background(), CreateTeamRequest{Name: "Hawks"}); err != nil {
+		t.Fatal(err)
+	}
+	if srv.created != 2 {
+		t.Errorf("created %d teams, want 2", srv.created)
+	}
+}
+
+func TestDecodesServerErrors(t *testing.T) {
+	ts := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "The request has invalid fields", FieldError{Field: "name", Message: "is required"})
+	}))
+	defer ts.Close()
+
+	_, err := ts.Client().CreateTeam(context.Background(), CreateTeamRequest{})
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an *Error", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.RequestID != "req-1" {
+		t.Errorf("got status %d and request %q, want 400 and req-1", apiErr.StatusCode, apiErr.RequestID)
+	}
+	if len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != "name" {
+		t.Errorf("fields = %v, want the name field", apiErr.Fields)
+	}
+	if !IsCode(err, CodeValidationFailed) || !errors.Is(err, &Error{Code: CodeValidationFailed}) {
+		t.Errorf("%v does not match %s", err, CodeValidationFailed)
+	}
+}
+
+func TestDecodesErrorsOutsideTheServerFormat(t *testing.T) {
+	tests := []struct {
+		status  int
+		body    string
+		code    string
+		message string
+	}{
+		{http.StatusBadGateway, "upstream unreachable\n", CodeInternal, "upstream unreachable"},
+		{http.StatusServiceUnavailable, "", CodeServiceUnavailable, "Service Unavailable"},
+		{http.StatusNotFound, "<html>not here</html>", CodeInternal, "<html>not here</html>"},
+	}
+
+	for _, tt := range tests {
+		ts := NewTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		_, err := ts.Client(WithRetries(0, time.Millisecond)).GetTeam(context.Background(), 1)
+		ts.Close()
+
+		var apiErr *Error
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("%d: got %v, want an *Error", tt.status, err)
+		}
+		if apiErr.StatusCode != tt.status || apiErr.Code != tt.code || apiErr.Message != tt.message {
+			t.Errorf("%d: got %d %s %q, want %d %s %q", tt.status, apiErr.StatusCode, apiErr.Code, apiErr.Message, tt.status, tt.code, tt.message)
+		}
+	}
+
+	if !IsNotFound(&Error{StatusCode: http.StatusNotFound}) {
+		t.Error("IsNotFound does not match a 404")
+	}
+}
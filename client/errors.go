@@ -0,0 +1,111 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Error codes returned by the server. Codes are stable; messages may change.
+const (
+	CodeInternal            = "internal_error"
+	CodeServiceUnavailable  = "service_unavailable"
+	CodeInvalidBody         = "invalid_body"
+	CodeInvalidParameter    = "invalid_parameter"
+	CodeValidationFailed    = "validation_failed"
+	CodeUnauthenticated     = "unauthenticated"
+	CodeNameTaken           = "name_taken"
+	CodeContestNotFound     = "contest_not_found"
+	CodeContestStarted      = "contest_started"
+	CodeContestFull         = "contest_full"
+	CodeContestNotOpen      = "contest_not_open"
+	CodeAlreadyInContest    = "already_in_contest"
+	CodeNotEligible         = "not_eligible"
+	CodeMaxEntriesReached   = "max_entries_reached"
+	CodeEntryNotFound       = "entry_not_found"
+	CodeTeamNotFound        = "team_not_found"
+	CodePlayerNotFound      = "player_not_found"
+	CodeIdempotencyKeyInUse = "idempotency_key_in_use"
+	CodeIdempotencyKeyReuse = "idempotency_key_reused"
+)
+
+// FieldError describes what is wrong with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error response from the server
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Fields     []FieldError
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	if e.RequestID == "" {
+		return fmt.Sprintf("client: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("client: %d %s: %s (request %s)", e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// Is makes errors.Is match any Error with the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// IsCode reports whether err is an Error with the given code
+func IsCode(err error, code string) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// IsNotFound reports whether err is an Error for a missing resource
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+type errorResponse struct {
+	Error struct {
+		Code      string       `json:"code"`
+		Message   string       `json:"message"`
+		Fields    []FieldError `json:"fields"`
+		RequestID string       `json:"request_id"`
+	} `json:"error"`
+}
+
+// decodeError builds an Error from a failed response. Bodies that are not the server's
+// error format, e.g. from a proxy in front of it, keep the status with a generic code.
+func decodeError(resp *http.Response, data []byte) *Error {
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get(requestIDHeader),
+	}
+
+	var body errorResponse
+	if err := json.Unmarshal(data, &body); err == nil && body.Error.Code != "" {
+		apiErr.Code = body.Error.Code
+		apiErr.Message = body.Error.Message
+		apiErr.Fields = body.Error.Fields
+		if body.Error.RequestID != "" {
+			apiErr.RequestID = body.Error.RequestID
+		}
+		return apiErr
+	}
+
+	apiErr.Code = CodeInternal
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		apiErr.Code = CodeServiceUnavailable
+	}
+	apiErr.Message = strings.TrimSpace(string(data))
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	return apiErr
+}
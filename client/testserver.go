@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+)
+
+// TestServer serves the API for integration tests of services that use this client.
+// It wraps the server's own router, built over a test database, so responses, error
+// codes and Idempotency-Key replays are exactly those of the real server. The client
+// cannot import the server, so the router is passed in:
+//
+//	ts := client.NewTestServer(router)
+//	defer ts.Close()
+//	c := ts.Client(client.WithUserID(1))
+type TestServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	failures    int
+	failureCode int
+}
+
+// NewTestServer starts a TestServer in front of handler. Close it when the test is done.
+func NewTestServer(handler http.Handler) *TestServer {
+	ts := &TestServer{}
+	ts.Server = httptest.NewServer(ts.middleware(handler))
+	return ts
+}
+
+// Client returns a client for the test server. Retries back off for a millisecond so
+// tests exercising them stay fast.
+func (ts *TestServer) Client(opts ...Option) *Client {
+	opts = append([]Option{WithHTTPClient(ts.Server.Client()), WithRetries(defaultMaxRetries, time.Millisecond)}, opts...)
+	return New(ts.URL, opts...)
+}
+
+// FailNext makes the next n requests fail with status, e.g. 503 to exercise retries.
+// Failed requests never reach the handler, so they do not consume their Idempotency-Key.
+func (ts *TestServer) FailNext(n, status int) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.failures = n
+	ts.failureCode = status
+}
+
+// middleware injects the failures set with FailNext before handing requests on
+func (ts *TestServer) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ts.mu.Lock()
+		fail := ts.failures > 0
+		if fail {
+			ts.failures--
+		}
+		status := ts.failureCode
+		ts.mu.Unlock()
+
+		if !fail {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body errorResponse
+		body.Error.Code = CodeServiceUnavailable
+		body.Error.Message = "Injected failure"
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	})
+}
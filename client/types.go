@@ -0,0 +1,128 @@
+package client
+
+import "time"
+
+// CreateContestRequest is the request body used to create a contest
+type CreateContestRequest struct {
+	Name              string    `json:"name"`
+	Prize             float64   `json:"prize"`
+	EntryFee          float64   `json:"entry_fee"`
+	TotalSlots        int       `json:"total_slots"`
+	MaxEntriesPerUser int       `json:"max_entries_per_user,omitempty"`
+	RosterSize        int       `json:"roster_size"`
+	IsGuaranteed      bool      `json:"is_guaranteed"`
+	MinFillPercent    int       `json:"min_fill_percent"`
+	AutoClone         bool      `json:"auto_clone"`
+	MaxClones         int       `json:"max_clones"`
+	StartDate         time.Time `json:"start_date"`
+	EndDate           time.Time `json:"end_date"`
+}
+
+// Contest is a contest as returned by the API
+type Contest struct {
+	ID                int       `json:"id"`
+	Name              string    `json:"name"`
+	Prize             float64   `json:"prize"`
+	PrizeStructure    string    `json:"prize_structure"`
+	EntryFee          float64   `json:"entry_fee"`
+	TotalSlots        int       `json:"total_slots"`
+	RemainingSlots    int       `json:"remaining_slots"`
+	FilledSlots       int       `json:"filled_slots"`
+	FillPercent       float64   `json:"fill_percent"`
+	MaxEntriesPerUser int       `json:"max_entries_per_user"`
+	RosterSize        int       `json:"roster_size"`
+	Status            string    `json:"status"`
+	IsPrivate         bool      `json:"is_private"`
+	IsLocked          bool      `json:"is_locked"`
+	IsGuaranteed      bool      `json:"is_guaranteed"`
+	MinFillPercent    int       `json:"min_fill_percent"`
+	SlateID           *int      `json:"slate_id,omitempty"`
+	SeriesID          int       `json:"series_id"`
+	CloneNumber       int       `json:"clone_number"`
+	StartDate         time.Time `json:"start_date"`
+	EndDate           time.Time `json:"end_date"`
+	LocksAt           time.Time `json:"locks_at"`
+	SecondsToLock     int64     `json:"seconds_to_lock"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// LobbyContest is one series row of the lobby: the series' open contest and totals
+// across all its contests
+type LobbyContest struct {
+	Contest
+	SeriesContests int `json:"series_contests"`
+	SeriesEntries  int `json:"series_entries"`
+}
+
+// CreateTeamRequest is the request body used to create a team
+type CreateTeamRequest struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+// Team is a team as returned by the API
+type Team struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Lineup roles. A player holding a role scores their points times the role's multiplier.
+const (
+	RoleCaptain     = "captain"
+	RoleViceCaptain = "vice_captain"
+)
+
+// LineupRoles maps a role such as RoleCaptain to the player ID holding it
+type LineupRoles map[string]int
+
+// EnterContestRequest is the request body used to enter a contest. InviteCode and
+// Password are only needed for private contests.
+type EnterContestRequest struct {
+	ContestID  int         `json:"contest_id"`
+	Lineup     []int       `json:"lineup"`
+	Roles      LineupRoles `json:"roles,omitempty"`
+	InviteCode string      `json:"invite_code,omitempty"`
+	Password   string      `json:"password,omitempty"`
+}
+
+// Entry is one of the user's entries
+type Entry struct {
+	ID          int         `json:"id"`
+	ContestID   int         `json:"contest_id"`
+	ContestName string      `json:"contest_name"`
+	UserID      int         `json:"user_id"`
+	Status      string      `json:"status"`
+	Lineup      []int       `json:"lineup"`
+	Roles       LineupRoles `json:"roles"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// LineupPlayer is a player of a lineup with the lock state of their game
+type LineupPlayer struct {
+	ID       int        `json:"id"`
+	Name     string     `json:"name"`
+	Position string     `json:"position"`
+	GameID   int        `json:"game_id"`
+	Kickoff  *time.Time `json:"kickoff,omitempty"`
+	Locked   bool       `json:"locked"`
+}
+
+// EntryLineup is an entry's lineup with its roles and the points scored so far
+type EntryLineup struct {
+	EntryID    int            `json:"entry_id"`
+	ServerTime time.Time      `json:"server_time"`
+	Players    []LineupPlayer `json:"players"`
+	Roles      LineupRoles    `json:"roles"`
+	Points     float64        `json:"points"`
+}
+
+// LeaderboardEntry is one entry's standing in a contest. Entries on equal points share
+// a rank.
+type LeaderboardEntry struct {
+	Rank    int     `json:"rank"`
+	EntryID int     `json:"entry_id"`
+	UserID  int     `json:"user_id"`
+	Points  float64 `json:"points"`
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"database/sql"
+)
+
+// resolveEntryContest returns the contest a new entry should go into. For a full contest
+// that auto-clones this is the first open contest in its series, cloning the series'
+// original contest when every existing one is full and the clone cap allows it.
+// Otherwise the requested contest is returned unchanged.
+func resolveEntryContest(tx *sql.Tx, contestID int) (int, error) {
+	var seriesID int
+	var autoClone bool
+	err := tx.QueryRow("SELECT IFNULL(series_id, id), auto_clone FROM contest WHERE id = ?", contestID).Scan(&seriesID, &autoClone)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return contestID, nil
+		}
+		return 0, err
+	}
+
+	if !autoClone {
+		return contestID, nil
+	}
+
+	// Lock the series' original contest so only one entrant at a time can create a clone
+	var maxClones int
+	err = tx.QueryRow("SELECT max_clones FROM contest WHERE id = ? FOR UPDATE", seriesID).Scan(&maxClones)
+	if err != nil {
+		return 0, err
+	}
+
+	var openID int
+	err = tx.QueryRow(
+		"SELECT id FROM contest WHERE (id = ? OR series_id = ?) AND status = ? AND start_date > NOW() AND remaining_slots > 0 ORDER BY id = ? DESC, clone_number, id LIMIT 1",
+		seriesID, seriesID, contestStatusActive, contestID,
+	).Scan(&openID)
+	if err == nil {
+		return openID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	var clones int
+	err = tx.QueryRow("SELECT COUNT(*) FROM contest WHERE series_id = ?", seriesID).Scan(&clones)
+	if err != nil {
+		return 0, err
+	}
+
+	// Out of clones: the caller will find the contest full
+	if clones >= maxClones {
+		return contestID, nil
+	}
+
+	return cloneContest(tx, seriesID, clones+1)
+}
+
+// cloneContest creates a copy of the series' original contest with the same rules, prize
+// and schedule and all of its slots open, and announces it to webhook subscribers
+func cloneContest(tx *sql.Tx, seriesID int, cloneNumber int) (int, error) {
+	res, err := tx.Exec(
+		"INSERT INTO contest (name, prize, prize_structure, entry_fee, total_slots, remaining_slots, max_entries_per_user, roster_size, slate_id, is_guaranteed, min_fill_percent, auto_clone, max_clones, series_id, clone_number, start_date, end_date, status, active_date, created_at) "+
+			"SELECT name, prize, prize_structure, entry_fee, total_slots, total_slots, max_entries_per_user, roster_size, slate_id, is_guaranteed, min_fill_percent, auto_clone, max_clones, id, ?, start_date, end_date, status, active_date, NOW() FROM contest WHERE id = ?",
+		cloneNumber, seriesID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	contestID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := recordContestEvent(tx, webhookContestOpened, int(contestID), nil); err != nil {
+		return 0, err
+	}
+
+	return int(contestID), nil
+}
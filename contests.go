@@ -0,0 +1,206 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+)
+
+// Contest statuses
+const (
+	contestStatusActive    = "active"
+	contestStatusLocked    = "locked"
+	contestStatusCancelled = "cancelled"
+	contestStatusSettled   = "settled"
+)
+
+// Prize structures describing how a contest's prize is split
+const (
+	prizeWinnerTakesAll = "winner_takes_all"
+	prizeFiftyFifty     = "fifty_fifty"
+	prizeTopHeavy       = "top_heavy"
+
+	defaultPrizeStructure = prizeWinnerTakesAll
+)
+
+// Errors shared by the contest entry paths
+var (
+	errContestNotFound = notFoundError("contest_not_found", "Contest not found")
+	errContestStarted  = conflictError("contest_started", "The contest has already started")
+	errNotEligible     = forbiddenError("not_eligible", "User is not eligible to enter this contest")
+	errMaxEntries      = conflictError("max_entries_reached", "Maximum number of entries for this contest reached")
+	errNotInContest    = notFoundError("entry_not_found", "User is not participating in the contest with this entry")
+)
+
+// ContestSlotsChange is the request body used to resize a contest
+type ContestSlotsChange struct {
+	TotalSlots int `json:"total_slots"`
+}
+
+func setupContestRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to list the contests shown in the public lobby
+	r.GET("/contests", func(c *gin.Context) {
+		contests, err := getLobbyContests(db)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch contests"))
+			return
+		}
+
+		now := time.Now()
+		res := make([]LobbyContestResponse, len(contests))
+		for i, row := range contests {
+			res[i] = newLobbyContestResponse(row, now)
+		}
+
+		c.JSON(http.StatusOK, res)
+	})
+
+	// Route to create a new contest
+	r.POST("/contests", func(c *gin.Context) {
+		var req CreateContestRequest
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+
+		contestID, err := createContest(db, req.toContest())
+		if err != nil {
+			c.Error(apiError(err, "Failed to create contest"))
+			return
+		}
+
+		contest, err := getContest(db, contestID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch contest"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, newContestResponse(contest, time.Now()))
+	})
+
+	// Route to fetch a contest by ID
+	r.GET("/contests/:id", func(c *gin.Context) {
+		contestID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid contest ID"))
+			return
+		}
+
+		contest, err := getContest(db, contestID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch contest"))
+			return
+		}
+
+		c.JSON(http.StatusOK, newContestResponse(contest, time.Now()))
+	})
+
+	// Route to change the total number of slots in a contest
+	r.PUT("/contests/:id/slots", func(c *gin.Context) {
+		userID, contestID, ok := commissionerRequest(c)
+		if !ok {
+			return
+		}
+
+		var change ContestSlotsChange
+		if err := c.ShouldBindJSON(&change); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		if change.TotalSlots <= 0 {
+			c.Error(validationError("invalid_total_slots", "Total slots must be positive", fieldError("total_slots", "must be positive")))
+			return
+		}
+
+		if err := updateContestTotalSlots(db, userID, contestID, change.TotalSlots); err != nil {
+			c.Error(apiError(err, "Failed to update contest slots"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Contest slots updated successfully"})
+	})
+}
+
+// LobbyContest is one row of the lobby. Contests cloned from the same original are
+// shown as a single series row pointing at the contest new entrants will join.
+type LobbyContest struct {
+	*Contest
+	SeriesContests int `json:"series_contests"`
+	SeriesEntries  int `json:"series_entries"`
+}
+
+// getLobbyContests returns the public contests that are still open for entries, one row
+// per series. Private contests are only reachable through their invite code.
+func getLobbyContests(db *sql.DB) ([]*LobbyContest, error) {
+	rows, err := db.Query("SELECT " + contestColumns + " FROM contest WHERE is_private = FALSE AND status = 'active' AND start_date > NOW() ORDER BY start_date, IFNULL(series_id, id), clone_number")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lobby := []*LobbyContest{}
+	series := make(map[int]*LobbyContest)
+	for rows.Next() {
+		contest, err := scanContest(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		row, ok := series[contest.SeriesID]
+		if !ok {
+			row = &LobbyContest{Contest: contest}
+			series[contest.SeriesID] = row
+			lobby = append(lobby, row)
+		} else if row.RemainingSlots <= 0 && contest.RemainingSlots > 0 {
+			// Show the first contest of the series that still has room
+			row.Contest = contest
+		}
+
+		row.SeriesContests++
+		row.SeriesEntries += contest.TotalSlots - contest.RemainingSlots
+	}
+
+	return lobby, rows.Err()
+}
+
+// insertContest creates a public contest inside an existing transaction, announces it to
+// webhook subscribers and returns its ID
+func insertContest(tx *sql.Tx, contest *Contest) (int, error) {
+	if contest.MaxEntriesPerUser <= 0 {
+		contest.MaxEntriesPerUser = defaultMaxEntriesPerUser
+	}
+	if contest.PrizeStructure == "" {
+		contest.PrizeStructure = defaultPrizeStructure
+	}
+
+	res, err := tx.Exec(
+		"INSERT INTO contest (name, prize, prize_structure, entry_fee, total_slots, remaining_slots, max_entries_per_user, roster_size, slate_id, is_guaranteed, min_fill_percent, auto_clone, max_clones, start_date, end_date, status, active_date, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULLIF(?, 0), ?, ?, ?, ?, ?, ?, 'active', ?, NOW())",
+		contest.Name, contest.Prize, contest.PrizeStructure, contest.EntryFee, contest.TotalSlots, contest.TotalSlots, contest.MaxEntriesPerUser, contest.RosterSize, contest.SlateID, contest.IsGuaranteed, contest.MinFillPercent, contest.AutoClone, contest.MaxClones, contest.StartDate, contest.EndDate, contest.StartDate,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	contestID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := recordContestEvent(tx, webhookContestOpened, int(contestID), nil); err != nil {
+		return 0, err
+	}
+
+	return int(contestID), nil
+}
+
+// isDuplicateKeyError reports whether err is MySQL rejecting a row that violates a unique index
+func isDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
@@ -0,0 +1,1037 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Draft statuses
+const (
+	draftScheduled  = "scheduled"
+	draftInProgress = "in_progress"
+	draftCompleted  = "completed"
+)
+
+// Draft types
+const (
+	draftSnake   = "snake"
+	draftAuction = "auction"
+)
+
+const (
+	// Time each team has to make a pick when the commissioner does not choose one
+	defaultPickSeconds = 90
+
+	// Number of rounds when the commissioner does not choose one
+	defaultDraftRounds = 15
+
+	// Wait before trying a failed auto-pick, auto-nomination or lot close again
+	draftRetryDelay = 10 * time.Second
+
+	// Auction defaults: each team's budget, the smallest bid, how long the bidding
+	// clock runs after a nomination, and how much time a late bid puts back on it
+	defaultAuctionBudget    = 200
+	defaultMinBid           = 1
+	defaultBidSeconds       = 30
+	defaultAntiSnipeSeconds = 10
+)
+
+var (
+	errDraftNotFound = notFoundError("draft_not_found", "Draft not found")
+	errNotInDraft    = forbiddenError("not_in_draft", "You do not have a team in this draft")
+)
+
+// Draft is a league draft together with its order and the picks made so far
+type Draft struct {
+	ID           int         `json:"id"`
+	LeagueID     int         `json:"league_id"`
+	Type         string      `json:"type"`
+	Status       string      `json:"status"`
+	Rounds       int         `json:"rounds"`
+	PickSeconds  int         `json:"pick_seconds"`
+	CurrentPick  int         `json:"current_pick"`
+	OnTheClock   int         `json:"on_the_clock,omitempty"`
+	PickDeadline *time.Time  `json:"pick_deadline,omitempty"`
+	Order        []int       `json:"order"`
+	Picks        []DraftPick `json:"picks"`
+	CreatedAt    time.Time   `json:"created_at"`
+
+	// Auction drafts only
+	Budget           int         `json:"budget,omitempty"`
+	MinBid           int         `json:"min_bid,omitempty"`
+	BidSeconds       int         `json:"bid_seconds,omitempty"`
+	AntiSnipeSeconds int         `json:"anti_snipe_seconds,omitempty"`
+	Budgets          map[int]int `json:"budgets,omitempty"`
+	Lot              *AuctionLot `json:"lot,omitempty"`
+}
+
+// DraftPick is one player taken in a draft. Auto is set when the pick timer ran out
+// and the pick was made from the team's queue or the rankings.
+type DraftPick struct {
+	PickNumber int       `json:"pick_number"`
+	Round      int       `json:"round"`
+	TeamID     int       `json:"team_id"`
+	PlayerID   int       `json:"player_id"`
+	Auto       bool      `json:"auto"`
+	Price      int       `json:"price,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// DraftRequest is the request body used by a commissioner to schedule a draft.
+// Order lists every team in the league by first-round pick (by nomination turn in an
+// auction); it is randomized when left out. Rounds is the number of roster spots filled.
+type DraftRequest struct {
+	Type             string `json:"type"`
+	Rounds           int    `json:"rounds"`
+	PickSeconds      int    `json:"pick_seconds"`
+	Order            []int  `json:"order"`
+	Budget           int    `json:"budget"`
+	MinBid           int    `json:"min_bid"`
+	BidSeconds       int    `json:"bid_seconds"`
+	AntiSnipeSeconds int    `json:"anti_snipe_seconds"`
+}
+
+// DraftPickRequest is the request body used to make a pick
+type DraftPickRequest struct {
+	PlayerID int `json:"player_id"`
+}
+
+// DraftQueue is the request body used to replace a team's pick queue, best first
+type DraftQueue struct {
+	PlayerIDs []int `json:"player_ids"`
+}
+
+// draftState is the part of a draft row needed to make a pick, read under lock.
+// In an auction CurrentPick counts nominations and the Lot fields describe the
+// player currently up for bidding, if any.
+type draftState struct {
+	ID               int
+	LeagueID         int
+	Type             string
+	Status           string
+	Rounds           int
+	PickSeconds      int
+	CurrentPick      int
+	Budget           int
+	MinBid           int
+	BidSeconds       int
+	AntiSnipeSeconds int
+	LotPlayerID      int
+	LotBid           int
+	LotTeamID        int
+	Deadline         *time.Time
+	Order            []int
+}
+
+// draftManager owns the pick timers of running drafts and pushes draft events to the
+// draft rooms. The database stays the source of truth: timers only ever act on the
+// pick they were started for, and resume rebuilds them after a restart.
+type draftManager struct {
+	db     *sql.DB
+	hub    *draftHub
+	mu     sync.Mutex
+	timers map[int]*time.Timer
+}
+
+func newDraftManager(db *sql.DB) *draftManager {
+	return &draftManager{
+		db:     db,
+		hub:    newDraftHub(),
+		timers: make(map[int]*time.Timer),
+	}
+}
+
+func setupDraftRoutes(r *gin.Engine, db *sql.DB, drafts *draftManager) {
+	// Route for the commissioner to schedule a league draft
+	r.POST("/leagues/:id/drafts", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		leagueID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid league ID"))
+			return
+		}
+
+		var req DraftRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		draftID, err := createDraft(db, userID, leagueID, req)
+		if err != nil {
+			c.Error(apiError(err, "Failed to create the draft"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Draft created successfully", "draft_id": draftID})
+	})
+
+	// Route to fetch a draft with its order and picks
+	r.GET("/drafts/:id", func(c *gin.Context) {
+		draftID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid draft ID"))
+			return
+		}
+
+		draft, err := getDraft(db, draftID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch the draft"))
+			return
+		}
+
+		c.JSON(http.StatusOK, draft)
+	})
+
+	// Route for the commissioner to start a scheduled draft
+	r.POST("/drafts/:id/start", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		draftID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid draft ID"))
+			return
+		}
+
+		if err := drafts.start(draftID, userID); err != nil {
+			c.Error(apiError(err, "Failed to start the draft"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Draft started successfully"})
+	})
+
+	// Route for the team on the clock to make its pick
+	r.POST("/drafts/:id/picks", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		draftID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid draft ID"))
+			return
+		}
+
+		var req DraftPickRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		pick, err := drafts.pick(draftID, userID, req.PlayerID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to make the pick"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, pick)
+	})
+
+	// Route to replace the current user's pick queue, used for auto-picks
+	r.PUT("/drafts/:id/queue", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		draftID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid draft ID"))
+			return
+		}
+
+		var req DraftQueue
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		if err := setDraftQueue(db, draftID, userID, req.PlayerIDs); err != nil {
+			c.Error(apiError(err, "Failed to update the queue"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Queue updated successfully"})
+	})
+
+	// Route to join the live draft room
+	r.GET("/drafts/:id/room", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		draftID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid draft ID"))
+			return
+		}
+
+		drafts.serveRoom(c, draftID, userID)
+	})
+}
+
+// createDraft schedules a draft for every team in the league
+func createDraft(db *sql.DB, userID int, leagueID int, req DraftRequest) (int, error) {
+	if req.Type == "" {
+		req.Type = draftSnake
+	}
+	if req.Type != draftSnake && req.Type != draftAuction {
+		return 0, invalidField("invalid_draft_type", "type", "Unknown draft type")
+	}
+	if req.Rounds <= 0 {
+		req.Rounds = defaultDraftRounds
+	}
+	if req.PickSeconds <= 0 {
+		req.PickSeconds = defaultPickSeconds
+	}
+	if req.Type == draftAuction {
+		if req.Budget <= 0 {
+			req.Budget = defaultAuctionBudget
+		}
+		if req.MinBid <= 0 {
+			req.MinBid = defaultMinBid
+		}
+		if req.BidSeconds <= 0 {
+			req.BidSeconds = defaultBidSeconds
+		}
+		if req.AntiSnipeSeconds <= 0 {
+			req.AntiSnipeSeconds = defaultAntiSnipeSeconds
+		}
+		if req.Budget < req.Rounds*req.MinBid {
+			return 0, invalidField("invalid_budget", "budget", "The budget cannot fill every roster spot at the minimum bid")
+		}
+	} else {
+		req.Budget, req.MinBid, req.BidSeconds, req.AntiSnipeSeconds = 0, 0, 0, 0
+	}
+
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the league so no team joins while the order is drawn
+	var commissionerID int
+	err = tx.QueryRow("SELECT commissioner_id FROM league WHERE id = ? FOR UPDATE", leagueID).Scan(&commissionerID)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return 0, errLeagueNotFound
+		}
+		return 0, err
+	}
+
+	if commissionerID != userID {
+		tx.Rollback()
+		return 0, forbiddenError("not_commissioner", "Only the commissioner can schedule a draft")
+	}
+
+	var drafts int
+	err = tx.QueryRow("SELECT COUNT(*) FROM draft WHERE league_id = ?", leagueID).Scan(&drafts)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if drafts > 0 {
+		tx.Rollback()
+		return 0, conflictError("draft_exists", "The league already has a draft")
+	}
+
+	rows, err := tx.Query("SELECT id FROM team WHERE league_id = ? ORDER BY id", leagueID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	var teamIDs []int
+	for rows.Next() {
+		var teamID int
+		if err := rows.Scan(&teamID); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return 0, err
+		}
+		teamIDs = append(teamIDs, teamID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if len(teamIDs) < 2 {
+		tx.Rollback()
+		return 0, conflictError("not_enough_teams", "A draft needs at least two teams")
+	}
+
+	order, err := draftOrder(teamIDs, req.Order)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	res, err := tx.Exec(
+		"INSERT INTO draft (league_id, type, status, rounds, pick_seconds, current_pick, budget, min_bid, bid_seconds, anti_snipe_seconds, created_at) VALUES (?, ?, ?, ?, ?, 0, ?, ?, ?, ?, NOW())",
+		leagueID, req.Type, draftScheduled, req.Rounds, req.PickSeconds, req.Budget, req.MinBid, req.BidSeconds, req.AntiSnipeSeconds,
+	)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	draftID, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	for i, teamID := range order {
+		_, err = tx.Exec("INSERT INTO draft_order (draft_id, slot, team_id, budget) VALUES (?, ?, ?, ?)", draftID, i+1, teamID, req.Budget)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(draftID), nil
+}
+
+// draftOrder returns the first-round order: the requested one when it names every
+// league team exactly once, or a random shuffle of the teams when none was given
+func draftOrder(teamIDs []int, requested []int) ([]int, error) {
+	if len(requested) == 0 {
+		order := append([]int(nil), teamIDs...)
+		rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		return order, nil
+	}
+
+	if len(requested) != len(teamIDs) {
+		return nil, invalidField("invalid_draft_order", "order", "The draft order must list every team in the league")
+	}
+
+	remaining := make(map[int]bool, len(teamIDs))
+	for _, teamID := range teamIDs {
+		remaining[teamID] = true
+	}
+	for _, teamID := range requested {
+		if !remaining[teamID] {
+			return nil, invalidField("invalid_draft_order", "order", "The draft order must list every team in the league exactly once")
+		}
+		delete(remaining, teamID)
+	}
+
+	return requested, nil
+}
+
+// snakePickTeam returns the team making overall pick number pick (1-based). Odd rounds
+// follow the order, even rounds run it in reverse.
+func snakePickTeam(order []int, pick int) int {
+	n := len(order)
+	round := (pick - 1) / n
+	slot := (pick - 1) % n
+	if round%2 == 1 {
+		slot = n - 1 - slot
+	}
+
+	return order[slot]
+}
+
+// lockDraft reads a draft and its order, holding the draft row until tx ends
+func lockDraft(tx *sql.Tx, draftID int) (*draftState, error) {
+	var d draftState
+	var deadline sql.NullTime
+	err := tx.QueryRow(
+		"SELECT id, league_id, type, status, rounds, pick_seconds, current_pick, budget, min_bid, bid_seconds, anti_snipe_seconds, IFNULL(lot_player_id, 0), lot_bid, IFNULL(lot_team_id, 0), pick_deadline FROM draft WHERE id = ? FOR UPDATE",
+		draftID,
+	).Scan(&d.ID, &d.LeagueID, &d.Type, &d.Status, &d.Rounds, &d.PickSeconds, &d.CurrentPick, &d.Budget, &d.MinBid, &d.BidSeconds, &d.AntiSnipeSeconds, &d.LotPlayerID, &d.LotBid, &d.LotTeamID, &deadline)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errDraftNotFound
+		}
+		return nil, err
+	}
+	if deadline.Valid {
+		d.Deadline = &deadline.Time
+	}
+
+	d.Order, err = getDraftOrder(tx, draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// getDraftOrder returns the draft's teams by first-round slot
+func getDraftOrder(q queryer, draftID int) ([]int, error) {
+	rows, err := q.Query("SELECT team_id FROM draft_order WHERE draft_id = ? ORDER BY slot", draftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	order := []int{}
+	for rows.Next() {
+		var teamID int
+		if err := rows.Scan(&teamID); err != nil {
+			return nil, err
+		}
+		order = append(order, teamID)
+	}
+
+	return order, rows.Err()
+}
+
+// getDraft returns a draft with its order and every pick made so far
+func getDraft(q queryer, draftID int) (*Draft, error) {
+	var d Draft
+	var deadline sql.NullTime
+	var lot AuctionLot
+	err := q.QueryRow(
+		"SELECT id, league_id, type, status, rounds, pick_seconds, current_pick, pick_deadline, created_at, budget, min_bid, bid_seconds, anti_snipe_seconds, IFNULL(lot_player_id, 0), lot_bid, IFNULL(lot_team_id, 0) FROM draft WHERE id = ?",
+		draftID,
+	).Scan(&d.ID, &d.LeagueID, &d.Type, &d.Status, &d.Rounds, &d.PickSeconds, &d.CurrentPick, &deadline, &d.CreatedAt, &d.Budget, &d.MinBid, &d.BidSeconds, &d.AntiSnipeSeconds, &lot.PlayerID, &lot.HighBid, &lot.HighBidTeamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errDraftNotFound
+		}
+		return nil, err
+	}
+	if deadline.Valid {
+		d.PickDeadline = &deadline.Time
+	}
+
+	d.Order, err = getDraftOrder(q, draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query("SELECT pick_number, round, team_id, player_id, auto, price, created_at FROM draft_pick WHERE draft_id = ? ORDER BY pick_number", draftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	d.Picks = []DraftPick{}
+	filled := make(map[int]int, len(d.Order))
+	for rows.Next() {
+		var p DraftPick
+		if err := rows.Scan(&p.PickNumber, &p.Round, &p.TeamID, &p.PlayerID, &p.Auto, &p.Price, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.Picks = append(d.Picks, p)
+		filled[p.TeamID]++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if d.Type == draftAuction {
+		d.Budgets, _, err = auctionTeams(q, draftID)
+		if err != nil {
+			return nil, err
+		}
+
+		if lot.PlayerID != 0 {
+			lot.Deadline = d.PickDeadline
+			d.Lot = &lot
+		}
+	}
+
+	if d.Status == draftInProgress {
+		if d.Type == draftSnake {
+			d.OnTheClock = snakePickTeam(d.Order, d.CurrentPick)
+		} else if d.Lot == nil {
+			d.OnTheClock = auctionNominator(d.Order, filled, d.Rounds, d.CurrentPick)
+		}
+	}
+
+	return &d, nil
+}
+
+// start puts a scheduled draft on the clock for its first pick
+func (m *draftManager) start(draftID int, userID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	d, err := lockDraft(tx, draftID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var commissionerID int
+	err = tx.QueryRow("SELECT commissioner_id FROM league WHERE id = ?", d.LeagueID).Scan(&commissionerID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if commissionerID != userID {
+		tx.Rollback()
+		return forbiddenError("not_commissioner", "Only the commissioner can start the draft")
+	}
+
+	if d.Status != draftScheduled {
+		tx.Rollback()
+		return conflictError("draft_started", "The draft has already started")
+	}
+
+	deadline := time.Now().Add(time.Duration(d.PickSeconds) * time.Second)
+	_, err = tx.Exec("UPDATE draft SET status = ?, current_pick = 1, pick_deadline = ?, started_at = NOW() WHERE id = ?", draftInProgress, deadline, draftID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	// The first team in the order makes the first pick, or the first nomination
+	if d.Type == draftAuction {
+		m.onTheNomination(draftID, 1, d.Order[0], deadline)
+	} else {
+		m.onTheClock(draftID, 1, snakePickTeam(d.Order, 1), deadline)
+	}
+
+	return nil
+}
+
+// pick makes the pick on the clock for the team owned by userID
+func (m *draftManager) pick(draftID int, userID int, playerID int) (*DraftPick, error) {
+	// Start a transaction to ensure consistency
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	d, err := lockDraft(tx, draftID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if d.Status != draftInProgress || d.Type != draftSnake {
+		tx.Rollback()
+		return nil, conflictError("draft_not_picking", "The draft is not taking picks")
+	}
+
+	teamID := snakePickTeam(d.Order, d.CurrentPick)
+	ownerID, err := teamOwner(tx, teamID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if ownerID != userID {
+		tx.Rollback()
+		return nil, conflictError("not_your_turn", "It is not your turn to pick")
+	}
+
+	pick, err := recordPick(tx, d, teamID, playerID, false, 0)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	deadline, err := advanceDraft(tx, d)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	m.afterPick(d, pick, deadline)
+
+	return pick, nil
+}
+
+// autoPick makes pick pickNumber for the team on the clock once its timer runs out,
+// taking the first available player from the team's queue, or else the best-ranked
+// available player. It does nothing if that pick was already made.
+func (m *draftManager) autoPick(draftID int, pickNumber int) error {
+	// Start a transaction to ensure consistency
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	d, err := lockDraft(tx, draftID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// The team picked in time, or another timer already handled it
+	if d.Status != draftInProgress || d.Type != draftSnake || d.CurrentPick != pickNumber {
+		tx.Rollback()
+		return nil
+	}
+
+	teamID := snakePickTeam(d.Order, d.CurrentPick)
+	playerID, err := nextAutoPick(tx, draftID, teamID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	pick, err := recordPick(tx, d, teamID, playerID, true, 0)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	deadline, err := advanceDraft(tx, d)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	m.afterPick(d, pick, deadline)
+
+	return nil
+}
+
+// nextAutoPick returns the team's best queued player still available, falling back to
+// the best-ranked available player. Unranked players go last.
+func nextAutoPick(tx *sql.Tx, draftID int, teamID int) (int, error) {
+	var playerID int
+	err := tx.QueryRow(
+		"SELECT q.player_id FROM draft_queue q WHERE q.draft_id = ? AND q.team_id = ? AND q.player_id NOT IN (SELECT player_id FROM draft_pick WHERE draft_id = ?) ORDER BY q.position LIMIT 1",
+		draftID, teamID, draftID,
+	).Scan(&playerID)
+	if err == nil {
+		return playerID, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	err = tx.QueryRow(
+		"SELECT id FROM player WHERE id NOT IN (SELECT player_id FROM draft_pick WHERE draft_id = ?) ORDER BY adp_rank IS NULL, adp_rank, id LIMIT 1",
+		draftID,
+	).Scan(&playerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, conflictError("no_players_left", "No players left to draft")
+		}
+		return 0, err
+	}
+
+	return playerID, nil
+}
+
+// checkDraftable makes sure the player exists and has not been drafted yet
+func checkDraftable(tx *sql.Tx, draftID int, playerID int) error {
+	var exists int
+	err := tx.QueryRow("SELECT COUNT(*) FROM player WHERE id = ?", playerID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return errPlayerNotFound
+	}
+
+	var taken int
+	err = tx.QueryRow("SELECT COUNT(*) FROM draft_pick WHERE draft_id = ? AND player_id = ?", draftID, playerID).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken > 0 {
+		return conflictError("player_drafted", "The player has already been drafted")
+	}
+
+	return nil
+}
+
+// recordPick stores the current pick, bought for price in an auction, and adds the
+// player to the team's roster
+func recordPick(tx *sql.Tx, d *draftState, teamID int, playerID int, auto bool, price int) (*DraftPick, error) {
+	if err := checkDraftable(tx, d.ID, playerID); err != nil {
+		return nil, err
+	}
+
+	pick := &DraftPick{
+		PickNumber: d.CurrentPick,
+		Round:      (d.CurrentPick-1)/len(d.Order) + 1,
+		TeamID:     teamID,
+		PlayerID:   playerID,
+		Auto:       auto,
+		Price:      price,
+		CreatedAt:  time.Now(),
+	}
+
+	_, err := tx.Exec(
+		"INSERT INTO draft_pick (draft_id, pick_number, round, team_id, player_id, auto, price, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		d.ID, pick.PickNumber, pick.Round, pick.TeamID, pick.PlayerID, pick.Auto, pick.Price, pick.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("INSERT INTO team_player (team_id, player_id, acquired_via, acquired_at) VALUES (?, ?, ?, ?)", teamID, playerID, acquiredDraft, pick.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// The player is gone, so take them out of every queue
+	_, err = tx.Exec("DELETE FROM draft_queue WHERE draft_id = ? AND player_id = ?", d.ID, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pick, nil
+}
+
+// advanceDraft moves the draft to its next pick and returns that pick's deadline,
+// or completes the draft and returns nil after the last pick
+func advanceDraft(tx *sql.Tx, d *draftState) (*time.Time, error) {
+	next := d.CurrentPick + 1
+	if next > d.Rounds*len(d.Order) {
+		_, err := tx.Exec("UPDATE draft SET status = ?, current_pick = ?, pick_deadline = NULL, completed_at = NOW() WHERE id = ?", draftCompleted, next, d.ID)
+		return nil, err
+	}
+
+	deadline := time.Now().Add(time.Duration(d.PickSeconds) * time.Second)
+	_, err := tx.Exec("UPDATE draft SET current_pick = ?, pick_deadline = ? WHERE id = ?", next, deadline, d.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deadline, nil
+}
+
+// afterPick announces a committed pick and starts the clock for the next one
+func (m *draftManager) afterPick(d *draftState, pick *DraftPick, deadline *time.Time) {
+	m.hub.broadcast(d.ID, draftEvent{Type: draftEventPick, Pick: pick})
+
+	if deadline == nil {
+		m.stopTimer(d.ID)
+		m.hub.broadcast(d.ID, draftEvent{Type: draftEventCompleted})
+		return
+	}
+
+	next := d.CurrentPick + 1
+	m.onTheClock(d.ID, next, snakePickTeam(d.Order, next), *deadline)
+}
+
+// onTheClock announces the team on the clock and auto-picks for it at the deadline
+func (m *draftManager) onTheClock(draftID int, pickNumber int, teamID int, deadline time.Time) {
+	m.hub.broadcast(draftID, draftEvent{Type: draftEventOnTheClock, PickNumber: pickNumber, TeamID: teamID, Deadline: &deadline})
+
+	m.startTimer(draftID, deadline, m.retrying(draftID, pickNumber, "auto-pick", func() error {
+		return m.autoPick(draftID, pickNumber)
+	}))
+}
+
+// retrying returns a timer callback running action. When action fails with an error that
+// may pass, such as a lost database connection, it tries again after draftRetryDelay so
+// the draft does not stall. Retries are not tracked as the draft's timer: once the draft
+// has moved on, action finds its pick stale and returns nil, which ends them.
+func (m *draftManager) retrying(draftID int, number int, what string, action func() error) func() {
+	var fire func()
+	fire = func() {
+		err := action()
+		if err == nil {
+			return
+		}
+
+		if kind := apiError(err, "").Kind; kind != kindInternal && kind != kindUnavailable {
+			log.Printf("draft: %s %d of draft %d failed: %v", what, number, draftID, err)
+			return
+		}
+		log.Printf("draft: %s %d of draft %d failed, retrying in %s: %v", what, number, draftID, draftRetryDelay, err)
+		time.AfterFunc(draftRetryDelay, fire)
+	}
+	return fire
+}
+
+// startTimer runs fire at deadline, replacing the draft's previous timer
+func (m *draftManager) startTimer(draftID int, deadline time.Time, fire func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.timers[draftID]; ok {
+		t.Stop()
+	}
+	m.timers[draftID] = time.AfterFunc(time.Until(deadline), fire)
+}
+
+// stopTimer stops the draft's timer, if any
+func (m *draftManager) stopTimer(draftID int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.timers[draftID]; ok {
+		t.Stop()
+		delete(m.timers, draftID)
+	}
+}
+
+// resume restarts the timers of drafts that were running when the server stopped.
+// Each pick, nomination or bidding clock keeps its persisted deadline; one that passed
+// during the downtime is acted on straight away.
+func (m *draftManager) resume() error {
+	rows, err := m.db.Query("SELECT id, type, current_pick, pick_deadline, lot_player_id IS NOT NULL FROM draft WHERE status = ?", draftInProgress)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var draftID, pickNumber int
+		var draftType string
+		var deadline sql.NullTime
+		var lotOpen bool
+		if err := rows.Scan(&draftID, &draftType, &pickNumber, &deadline, &lotOpen); err != nil {
+			return err
+		}
+		if !deadline.Valid {
+			deadline.Time = time.Now()
+		}
+
+		switch {
+		case draftType == draftAuction && lotOpen:
+			m.startTimer(draftID, deadline.Time, m.retrying(draftID, pickNumber, "closing lot", func() error {
+				return m.closeLot(draftID, pickNumber)
+			}))
+		case draftType == draftAuction:
+			m.startTimer(draftID, deadline.Time, m.retrying(draftID, pickNumber, "auto-nomination", func() error {
+				return m.autoNominate(draftID, pickNumber)
+			}))
+		default:
+			m.startTimer(draftID, deadline.Time, m.retrying(draftID, pickNumber, "auto-pick", func() error {
+				return m.autoPick(draftID, pickNumber)
+			}))
+		}
+	}
+
+	return rows.Err()
+}
+
+// setDraftQueue replaces the pick queue of the user's team in the draft
+func setDraftQueue(db *sql.DB, draftID int, userID int, playerIDs []int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var teamID int
+	var status string
+	err = tx.QueryRow(
+		"SELECT o.team_id, d.status FROM draft d JOIN draft_order o ON o.draft_id = d.id JOIN team t ON t.id = o.team_id WHERE d.id = ? AND t.owner_id = ?",
+		draftID, userID,
+	).Scan(&teamID, &status)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return errNotInDraft
+		}
+		return err
+	}
+
+	if status == draftCompleted {
+		tx.Rollback()
+		return conflictError("draft_finished", "The draft has finished")
+	}
+
+	_, err = tx.Exec("DELETE FROM draft_queue WHERE draft_id = ? AND team_id = ?", draftID, teamID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	seen := make(map[int]bool, len(playerIDs))
+	for i, playerID := range playerIDs {
+		if seen[playerID] {
+			tx.Rollback()
+			return invalidField("duplicate_player", "player_ids", "A player can only be queued once")
+		}
+		seen[playerID] = true
+
+		_, err = tx.Exec("INSERT INTO draft_queue (draft_id, team_id, player_id, position) VALUES (?, ?, ?, ?)", draftID, teamID, playerID, i+1)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
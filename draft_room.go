@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Draft room event types
+const (
+	draftEventState      = "state"
+	draftEventOnTheClock = "on_the_clock"
+	draftEventPick       = "pick"
+	draftEventNominated  = "nominated"
+	draftEventBid        = "bid"
+	draftEventCompleted  = "completed"
+	draftEventError      = "error"
+)
+
+const (
+	// Time allowed to write one message to a draft room client
+	draftWriteWait = 10 * time.Second
+
+	// Time allowed between pongs before a client is considered gone
+	draftPongWait = 60 * time.Second
+
+	// How often clients are pinged; must be shorter than draftPongWait
+	draftPingPeriod = 50 * time.Second
+
+	// Messages buffered for a slow client before it is dropped
+	draftSendBuffer = 32
+)
+
+// draftEvent is a message pushed to everyone in a draft room
+type draftEvent struct {
+	Type       string      `json:"type"`
+	Draft      *Draft      `json:"draft,omitempty"`
+	Pick       *DraftPick  `json:"pick,omitempty"`
+	PickNumber int         `json:"pick_number,omitempty"`
+	TeamID     int         `json:"team_id,omitempty"`
+	Deadline   *time.Time  `json:"deadline,omitempty"`
+	Lot        *AuctionLot `json:"lot,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// draftMessage is a message sent by a client in a draft room
+type draftMessage struct {
+	Type     string `json:"type"`
+	PlayerID int    `json:"player_id"`
+	Amount   int    `json:"amount"`
+}
+
+var draftUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// draftClient is one connection to a draft room. Only its write loop writes to conn.
+type draftClient struct {
+	conn   *websocket.Conn
+	userID int
+	send   chan []byte
+}
+
+// draftHub keeps track of the clients connected to each draft room
+type draftHub struct {
+	mu    sync.Mutex
+	rooms map[int]map[*draftClient]bool
+}
+
+func newDraftHub() *draftHub {
+	return &draftHub{rooms: make(map[int]map[*draftClient]bool)}
+}
+
+func (h *draftHub) join(draftID int, client *draftClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.rooms[draftID] == nil {
+		h.rooms[draftID] = make(map[*draftClient]bool)
+	}
+	h.rooms[draftID][client] = true
+}
+
+func (h *draftHub) leave(draftID int, client *draftClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.rooms[draftID][client]; ok {
+		delete(h.rooms[draftID], client)
+		close(client.send)
+	}
+	if len(h.rooms[draftID]) == 0 {
+		delete(h.rooms, draftID)
+	}
+}
+
+// broadcast sends an event to every client in the draft room. Clients that cannot
+// keep up are disconnected rather than holding up the draft.
+func (h *draftHub) broadcast(draftID int, event draftEvent) {
+	msg, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("draft: failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.rooms[draftID] {
+		select {
+		case client.send <- msg:
+		default:
+			delete(h.rooms[draftID], client)
+			close(client.send)
+		}
+	}
+}
+
+// sendTo sends an event to one client, if it is still in the room
+func (h *draftHub) sendTo(draftID int, client *draftClient, event draftEvent) {
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.rooms[draftID][client] {
+		select {
+		case client.send <- msg:
+		default:
+		}
+	}
+}
+
+// serveRoom upgrades the request to a WebSocket and joins the draft room. The client
+// gets the full draft state first and then every event as it happens; a team owner
+// can also act by sending {"type": "pick", "player_id": ...}, or in an auction
+// {"type": "nominate", "player_id": ..., "amount": ...} and {"type": "bid", "amount": ...}.
+func (m *draftManager) serveRoom(c *gin.Context, draftID int, userID int) {
+	draft, err := getDraft(m.db, draftID)
+	if err != nil {
+		c.Error(apiError(err, "Failed to fetch the draft"))
+		return
+	}
+
+	conn, err := draftUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has already written the error response
+		return
+	}
+
+	client := &draftClient{conn: conn, userID: userID, send: make(chan []byte, draftSendBuffer)}
+
+	state, err := json.Marshal(draftEvent{Type: draftEventState, Draft: draft})
+	if err != nil {
+		conn.Close()
+		return
+	}
+	client.send <- state
+
+	m.hub.join(draftID, client)
+	go client.writeLoop()
+	m.readLoop(draftID, client)
+}
+
+// readLoop handles messages from a client until it disconnects
+func (m *draftManager) readLoop(draftID int, client *draftClient) {
+	defer func() {
+		m.hub.leave(draftID, client)
+		client.conn.Close()
+	}()
+
+	client.conn.SetReadLimit(4096)
+	client.conn.SetReadDeadline(time.Now().Add(draftPongWait))
+	client.conn.SetPongHandler(func(string) error {
+		return client.conn.SetReadDeadline(time.Now().Add(draftPongWait))
+	})
+
+	for {
+		var msg draftMessage
+		if err := client.conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				log.Printf("draft: room %d connection closed: %v", draftID, err)
+			}
+			return
+		}
+
+		// Successful actions reach everyone through the broadcast; errors go only to the sender
+		var err error
+		switch msg.Type {
+		case "pick":
+			_, err = m.pick(draftID, client.userID, msg.PlayerID)
+		case "nominate":
+			_, err = m.nominate(draftID, client.userID, msg.PlayerID, msg.Amount)
+		case "bid":
+			_, err = m.bid(draftID, client.userID, msg.Amount)
+		default:
+			continue
+		}
+		if err != nil {
+			m.hub.sendTo(draftID, client, draftEvent{Type: draftEventError, Error: err.Error()})
+		}
+	}
+}
+
+// writeLoop writes queued messages to the client and keeps the connection alive
+func (client *draftClient) writeLoop() {
+	ticker := time.NewTicker(draftPingPeriod)
+	defer func() {
+		ticker.Stop()
+		client.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-client.send:
+			client.conn.SetWriteDeadline(time.Now().Add(draftWriteWait))
+			if !ok {
+				// The hub closed the room or dropped this client
+				client.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := client.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			client.conn.SetWriteDeadline(time.Now().Add(draftWriteWait))
+			if err := client.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
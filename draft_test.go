@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnakePickTeam(t *testing.T) {
+	tests := []struct {
+		order []int
+		picks []int // teams making picks 1, 2, ...
+	}{
+		{[]int{10}, []int{10, 10, 10}},
+		{[]int{10, 20}, []int{10, 20, 20, 10, 10, 20}},
+		// Odd rounds follow the order, even rounds reverse it, so the turn sits with the
+		// same team across each round break
+		{[]int{10, 20, 30}, []int{10, 20, 30, 30, 20, 10, 10, 20, 30}},
+		{[]int{10, 20, 30, 40}, []int{10, 20, 30, 40, 40, 30, 20, 10, 10, 20, 30, 40}},
+	}
+
+	for _, tt := range tests {
+		picks := make([]int, len(tt.picks))
+		for i := range picks {
+			picks[i] = snakePickTeam(tt.order, i+1)
+		}
+		if !reflect.DeepEqual(picks, tt.picks) {
+			t.Errorf("order %v: picks %v, want %v", tt.order, picks, tt.picks)
+		}
+	}
+}
+
+func TestAuctionNominator(t *testing.T) {
+	order := []int{10, 20, 30}
+
+	tests := []struct {
+		filled     map[int]int
+		nomination int
+		team       int
+	}{
+		{map[int]int{}, 1, 10},
+		{map[int]int{}, 2, 20},
+		{map[int]int{}, 4, 10},
+		// Teams with a full roster lose their turn to the next team in the order
+		{map[int]int{20: 2}, 2, 30},
+		{map[int]int{30: 2}, 3, 10},
+		{map[int]int{10: 2, 30: 2}, 1, 20},
+		{map[int]int{10: 2, 20: 2, 30: 1}, 2, 30},
+		// Nobody nominates once every roster is full
+		{map[int]int{10: 2, 20: 2, 30: 2}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		if team := auctionNominator(order, tt.filled, 2, tt.nomination); team != tt.team {
+			t.Errorf("nomination %d with %v filled: team %d, want %d", tt.nomination, tt.filled, team, tt.team)
+		}
+	}
+}
+
+func TestMaxBid(t *testing.T) {
+	tests := []struct {
+		budget, filled, rosterSpots, minBid int
+		want                                int
+	}{
+		{200, 0, 15, 1, 186},
+		{200, 14, 15, 1, 200},
+		{50, 10, 15, 2, 42},
+	}
+
+	for _, tt := range tests {
+		if bid := maxBid(tt.budget, tt.filled, tt.rosterSpots, tt.minBid); bid != tt.want {
+			t.Errorf("maxBid(%d, %d, %d, %d) = %d, want %d", tt.budget, tt.filled, tt.rosterSpots, tt.minBid, bid, tt.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import "time"
+
+// Request and response types of the contest and team endpoints. Contest and Team are the
+// persistence models scanned from the database; the API never exposes them directly, so
+// columns can change without changing the JSON clients see. Times are formatted as
+// RFC 3339 in UTC.
+
+// CreateContestRequest is the request body used to create a contest. Status, the
+// active date and the remaining slots are set by the server.
+type CreateContestRequest struct {
+	Name              string    `json:"name" binding:"required,min=3,max=100,name_charset"`
+	Prize             float64   `json:"prize" binding:"gte=0"`
+	EntryFee          float64   `json:"entry_fee" binding:"gte=0"`
+	TotalSlots        int       `json:"total_slots" binding:"required,gte=2,lte=100000"`
+	MaxEntriesPerUser int       `json:"max_entries_per_user" binding:"omitempty,gte=1,lte=150"`
+	RosterSize        int       `json:"roster_size" binding:"gte=0,lte=50"`
+	IsGuaranteed      bool      `json:"is_guaranteed"`
+	MinFillPercent    int       `json:"min_fill_percent" binding:"gte=0,lte=100"`
+	AutoClone         bool      `json:"auto_clone"`
+	MaxClones         int       `json:"max_clones" binding:"gte=0,lte=1000"`
+	StartDate         time.Time `json:"start_date" binding:"required,future"`
+	EndDate           time.Time `json:"end_date" binding:"required"`
+}
+
+// CreateTeamRequest is the request body used to create a team
+type CreateTeamRequest struct {
+	Name        string `json:"name" binding:"required,min=2,max=50,name_charset"`
+	DisplayName string `json:"display_name" binding:"omitempty,max=100"`
+}
+
+// ContestResponse is a contest as returned by the API, with the fields clients would
+// otherwise compute themselves: how full it is and how long until it locks
+type ContestResponse struct {
+	ID                int     `json:"id"`
+	Name              string  `json:"name"`
+	Prize             float64 `json:"prize"`
+	PrizeStructure    string  `json:"prize_structure"`
+	EntryFee          float64 `json:"entry_fee"`
+	TotalSlots        int     `json:"total_slots"`
+	RemainingSlots    int     `json:"remaining_slots"`
+	FilledSlots       int     `json:"filled_slots"`
+	FillPercent       float64 `json:"fill_percent"`
+	MaxEntriesPerUser int     `json:"max_entries_per_user"`
+	RosterSize        int     `json:"roster_size"`
+	Status            string  `json:"status"`
+	IsPrivate         bool    `json:"is_private"`
+	IsLocked          bool    `json:"is_locked"`
+	IsGuaranteed      bool    `json:"is_guaranteed"`
+	MinFillPercent    int     `json:"min_fill_percent"`
+	SlateID           *int    `json:"slate_id,omitempty"`
+	SeriesID          int     `json:"series_id"`
+	CloneNumber       int     `json:"clone_number"`
+	StartDate         string  `json:"start_date"`
+	EndDate           string  `json:"end_date"`
+	LocksAt           string  `json:"locks_at"`
+	SecondsToLock     int64   `json:"seconds_to_lock"`
+	CreatedAt         string  `json:"created_at"`
+}
+
+// LobbyContestResponse is one series row of the lobby
+type LobbyContestResponse struct {
+	ContestResponse
+	SeriesContests int `json:"series_contests"`
+	SeriesEntries  int `json:"series_entries"`
+}
+
+// TeamResponse is a team as returned by the API
+type TeamResponse struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// formatTime renders a time for API responses
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// toContest maps a create request onto a new contest. Contests open for entries at
+// once and lock when they start.
+func (req CreateContestRequest) toContest() *Contest {
+	return &Contest{
+		Name:              req.Name,
+		Prize:             req.Prize,
+		EntryFee:          req.EntryFee,
+		TotalSlots:        req.TotalSlots,
+		RemainingSlots:    req.TotalSlots,
+		MaxEntriesPerUser: req.MaxEntriesPerUser,
+		RosterSize:        req.RosterSize,
+		IsGuaranteed:      req.IsGuaranteed,
+		MinFillPercent:    req.MinFillPercent,
+		AutoClone:         req.AutoClone,
+		MaxClones:         req.MaxClones,
+		StartDate:         req.StartDate,
+		EndDate:           req.EndDate,
+		Status:            contestStatusActive,
+	}
+}
+
+// newContestResponse maps a contest onto its API shape as seen at now
+func newContestResponse(contest *Contest, now time.Time) ContestResponse {
+	filled := contest.TotalSlots - contest.RemainingSlots
+
+	var fillPercent float64
+	if contest.TotalSlots > 0 {
+		fillPercent = float64(filled) * 100 / float64(contest.TotalSlots)
+	}
+
+	var secondsToLock int64
+	if now.Before(contest.StartDate) {
+		secondsToLock = int64(contest.StartDate.Sub(now) / time.Second)
+	}
+
+	var slateID *int
+	if contest.SlateID != 0 {
+		id := contest.SlateID
+		slateID = &id
+	}
+
+	return ContestResponse{
+		ID:                contest.ID,
+		Name:              contest.Name,
+		Prize:             contest.Prize,
+		PrizeStructure:    contest.PrizeStructure,
+		EntryFee:          contest.EntryFee,
+		TotalSlots:        contest.TotalSlots,
+		RemainingSlots:    contest.RemainingSlots,
+		FilledSlots:       filled,
+		FillPercent:       fillPercent,
+		MaxEntriesPerUser: contest.MaxEntriesPerUser,
+		RosterSize:        contest.RosterSize,
+		Status:            contest.Status,
+		IsPrivate:         contest.IsPrivate,
+		IsLocked:          contest.IsLocked,
+		IsGuaranteed:      contest.IsGuaranteed,
+		MinFillPercent:    contest.MinFillPercent,
+		SlateID:           slateID,
+		SeriesID:          contest.SeriesID,
+		CloneNumber:       contest.CloneNumber,
+		StartDate:         formatTime(contest.StartDate),
+		EndDate:           formatTime(contest.EndDate),
+		LocksAt:           formatTime(contest.StartDate),
+		SecondsToLock:     secondsToLock,
+		CreatedAt:         formatTime(contest.CreatedAt),
+	}
+}
+
+// newLobbyContestResponse maps a lobby row onto its API shape as seen at now
+func newLobbyContestResponse(row *LobbyContest, now time.Time) LobbyContestResponse {
+	return LobbyContestResponse{
+		ContestResponse: newContestResponse(row.Contest, now),
+		SeriesContests:  row.SeriesContests,
+		SeriesEntries:   row.SeriesEntries,
+	}
+}
+
+// newTeamResponse maps a team onto its API shape
+func newTeamResponse(team *Team) TeamResponse {
+	return TeamResponse{
+		ID:          team.ID,
+		Name:        team.Name,
+		DisplayName: team.DisplayName,
+		CreatedAt:   formatTime(team.CreatedAt),
+	}
+}
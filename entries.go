@@ -0,0 +1,435 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Entry statuses, derived from the contest the entry belongs to
+const (
+	entryStatusUpcoming  = "upcoming"
+	entryStatusLive      = "live"
+	entryStatusCompleted = "completed"
+)
+
+var errEntryNotFound = notFoundError("entry_not_found", "Entry not found")
+
+// Entry is a single user_contest row. A user can hold several entries in the
+// same contest and in any number of contests, each with its own lineup.
+type Entry struct {
+	ID          int         `json:"id"`
+	ContestID   int         `json:"contest_id"`
+	ContestName string      `json:"contest_name"`
+	UserID      int         `json:"user_id"`
+	Status      string      `json:"status"`
+	Lineup      []int       `json:"lineup"`
+	Roles       LineupRoles `json:"roles"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx, so reads can run inside or outside a transaction
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// LineupChange is the request body used to edit the lineup of one entry
+type LineupChange struct {
+	EntryID int         `json:"entry_id"`
+	Lineup  []int       `json:"lineup"`
+	Roles   LineupRoles `json:"roles"`
+}
+
+func setupEntryRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to list every entry of the current user across all contests
+	r.GET("/me/entries", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		entries, err := getUserEntries(db, userID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch entries"))
+			return
+		}
+
+		c.JSON(http.StatusOK, entries)
+	})
+
+	// Route to show an entry's lineup with the lock state of each player
+	r.GET("/me/entries/:id/lineup", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		entryID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid entry ID"))
+			return
+		}
+
+		var owned bool
+		err = db.QueryRow("SELECT EXISTS (SELECT 1 FROM user_contest WHERE id = ? AND user_id = ?)", entryID, userID).Scan(&owned)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch lineup"))
+			return
+		}
+		if !owned {
+			c.Error(errEntryNotFound)
+			return
+		}
+
+		lineup, err := getEntryLineup(db, entryID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch lineup"))
+			return
+		}
+
+		players, err := getLineupPlayers(db, lineup, time.Now())
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch lineup"))
+			return
+		}
+
+		roles, err := getEntryRoles(db, entryID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch lineup"))
+			return
+		}
+
+		points, err := computeEntryPoints(db, entryID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch lineup"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"entry_id": entryID, "server_time": time.Now().UTC(), "players": players, "roles": roles, "points": points})
+	})
+
+	// Route to edit the lineup of a specific entry
+	r.PUT("/contests/lineup", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		var change LineupChange
+
+		if err := c.ShouldBindJSON(&change); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		if err := updateEntryLineup(db, userID, change.EntryID, change.Lineup, change.Roles); err != nil {
+			c.Error(apiError(err, "Failed to update the lineup"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Lineup updated successfully"})
+	})
+}
+
+// saveEntryLineup writes the players of an entry's lineup and their roles inside an
+// existing transaction
+func saveEntryLineup(tx *sql.Tx, entryID int, lineup []int, roles LineupRoles) error {
+	if err := validateLineupRoles(lineup, roles); err != nil {
+		return err
+	}
+
+	roleOf := make(map[int]string, len(roles))
+	for role, playerID := range roles {
+		roleOf[playerID] = role
+	}
+
+	seen := make(map[int]bool, len(lineup))
+	for _, playerID := range lineup {
+		if seen[playerID] {
+			return invalidField("duplicate_player", "lineup", "A player can only appear once in a lineup")
+		}
+		seen[playerID] = true
+
+		var role sql.NullString
+		if r, ok := roleOf[playerID]; ok {
+			role = sql.NullString{String: r, Valid: true}
+		}
+
+		_, err := tx.Exec("INSERT INTO entry_lineup (entry_id, player_id, role) VALUES (?, ?, ?)", entryID, playerID, role)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateEntryLineup replaces the lineup of one of the user's entries. Once the contest has
+// started only players whose games have not kicked off can be swapped (late swap); players
+// already in play stay frozen, and so do their captain and vice-captain roles. The server
+// clock decides what has kicked off.
+func updateEntryLineup(db *sql.DB, userID int, entryID int, lineup []int, roles LineupRoles) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Make sure the entry belongs to the user before touching it
+	var contestID int
+	var contestStatus string
+	var endDate time.Time
+	err = tx.QueryRow(
+		"SELECT uc.contest_id, c.status, c.end_date FROM user_contest uc JOIN contest c ON c.id = uc.contest_id WHERE uc.id = ? AND uc.user_id = ? FOR UPDATE",
+		entryID, userID,
+	).Scan(&contestID, &contestStatus, &endDate)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return errEntryNotFound
+		}
+		return err
+	}
+
+	now := time.Now()
+	if contestStatus == contestStatusCancelled || !now.Before(endDate) {
+		tx.Rollback()
+		return conflictError("contest_over", "The contest is over and its lineups can no longer change")
+	}
+
+	if err := validateLineup(tx, contestID, lineup); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Every player going in or out must still be before kickoff
+	current, err := getEntryLineup(tx, entryID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	currentRoles, err := getEntryRoles(tx, entryID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	locked, err := lockedLineupChanges(tx, current, lineup, changedRoleHolders(currentRoles, roles), now)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(locked) > 0 {
+		tx.Rollback()
+		return conflictError("players_locked", fmt.Sprintf("Players %v are locked because their games have started", locked))
+	}
+
+	// Replace the previous lineup
+	_, err = tx.Exec("DELETE FROM entry_lineup WHERE entry_id = ?", entryID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := saveEntryLineup(tx, entryID, lineup, roles); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// lockedLineupChanges returns the players added or removed between current and next, or
+// whose role changes, whose games have already kicked off at now
+func lockedLineupChanges(tx *sql.Tx, current, next []int, roleChanges []int, now time.Time) ([]int, error) {
+	inCurrent := make(map[int]bool, len(current))
+	for _, id := range current {
+		inCurrent[id] = true
+	}
+	inNext := make(map[int]bool, len(next))
+	for _, id := range next {
+		inNext[id] = true
+	}
+
+	var changed []int
+	for _, id := range current {
+		if !inNext[id] {
+			changed = append(changed, id)
+		}
+	}
+	for _, id := range next {
+		if !inCurrent[id] {
+			changed = append(changed, id)
+		}
+	}
+	for _, id := range roleChanges {
+		if inCurrent[id] && inNext[id] {
+			changed = append(changed, id)
+		}
+	}
+
+	players, err := getLineupPlayers(tx, changed, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked []int
+	for _, p := range players {
+		if p.Locked {
+			locked = append(locked, p.ID)
+		}
+	}
+
+	return locked, nil
+}
+
+// validateLineup checks a lineup against the rules of the contest it is entered in
+func validateLineup(tx *sql.Tx, contestID int, lineup []int) error {
+	var rosterSize int
+	err := tx.QueryRow("SELECT roster_size FROM contest WHERE id = ?", contestID).Scan(&rosterSize)
+	if err != nil {
+		return err
+	}
+
+	// A roster size of 0 means the contest does not constrain lineups
+	if rosterSize > 0 && len(lineup) != rosterSize {
+		return invalidField("invalid_lineup_size", "lineup", fmt.Sprintf("Lineup must have exactly %d players", rosterSize))
+	}
+
+	// Contests built on a slate only accept players from that slate's games
+	if len(lineup) > 0 {
+		args := []interface{}{contestID}
+		for _, id := range lineup {
+			args = append(args, id)
+		}
+
+		var offSlate int
+		err = tx.QueryRow(
+			"SELECT COUNT(*) FROM player p JOIN contest c ON c.id = ? LEFT JOIN game g ON g.id = p.game_id WHERE c.slate_id IS NOT NULL AND p.id IN ("+placeholders(len(lineup))+") AND (g.slate_id IS NULL OR g.slate_id <> c.slate_id)",
+			args...,
+		).Scan(&offSlate)
+		if err != nil {
+			return err
+		}
+
+		if offSlate > 0 {
+			return invalidField("off_slate_player", "lineup", "Lineup contains players who are not playing on this contest's slate")
+		}
+	}
+
+	return nil
+}
+
+// getEntryLineup returns the player IDs in an entry's lineup
+func getEntryLineup(q queryer, entryID int) ([]int, error) {
+	rows, err := q.Query("SELECT player_id FROM entry_lineup WHERE entry_id = ? ORDER BY player_id", entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lineup := []int{}
+	for rows.Next() {
+		var playerID int
+		if err := rows.Scan(&playerID); err != nil {
+			return nil, err
+		}
+		lineup = append(lineup, playerID)
+	}
+
+	return lineup, rows.Err()
+}
+
+// entryColumns lists the entry columns in the order scanEntry reads them, selected from
+// user_contest uc JOIN contest c
+const entryColumns = "uc.id, uc.contest_id, c.name, uc.user_id, c.status, c.start_date, c.end_date, uc.created_at"
+
+// scanEntry reads one entry selected with entryColumns, without its lineup
+func scanEntry(row rowScanner, now time.Time) (*Entry, error) {
+	var entry Entry
+	var contestStatus string
+	var startDate, endDate time.Time
+	err := row.Scan(&entry.ID, &entry.ContestID, &entry.ContestName, &entry.UserID, &contestStatus, &startDate, &endDate, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	entry.Status = entryStatus(contestStatus, startDate, endDate, now)
+
+	return &entry, nil
+}
+
+// getUserEntries returns all entries held by the user, newest first
+func getUserEntries(db *sql.DB, userID int) ([]Entry, error) {
+	rows, err := db.Query(
+		"SELECT "+entryColumns+" FROM user_contest uc JOIN contest c ON c.id = uc.contest_id WHERE uc.user_id = ? ORDER BY uc.created_at DESC, uc.id DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	now := time.Now()
+	for rows.Next() {
+		entry, err := scanEntry(rows, now)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		lineup, err := getEntryLineup(db, entries[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		entries[i].Lineup = lineup
+
+		roles, err := getEntryRoles(db, entries[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		entries[i].Roles = roles
+	}
+
+	return entries, nil
+}
+
+// entryStatus reports where an entry stands given its contest's status and schedule
+func entryStatus(contestStatus string, startDate, endDate, now time.Time) string {
+	if contestStatus == contestStatusCancelled {
+		return contestStatus
+	}
+	if now.Before(startDate) {
+		return entryStatusUpcoming
+	}
+	if now.Before(endDate) {
+		return entryStatusLive
+	}
+	return entryStatusCompleted
+}
@@ -0,0 +1,1750 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: fantasy.proto
+
+// The gRPC API of the contest, team and entry endpoints. It is served next to the HTTP
+// API and runs the same domain code, so both return the same data and the same errors.
+//
+// Calls that act for a user send the user's ID in the x-user-id metadata key, as the
+// API gateway does with the X-User-ID header. Failed calls carry a google.rpc.ErrorInfo
+// detail whose reason is the error code the HTTP API returns, e.g. "contest_full".
+//
+// Regenerate fantasy.pb.go and fantasy_grpc.pb.go with go generate after editing.
+
+package main
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ContestInfo struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name              string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Prize             float64                `protobuf:"fixed64,3,opt,name=prize,proto3" json:"prize,omitempty"`
+	PrizeStructure    string                 `protobuf:"bytes,4,opt,name=prize_structure,json=prizeStructure,proto3" json:"prize_structure,omitempty"`
+	EntryFee          float64                `protobuf:"fixed64,5,opt,name=entry_fee,json=entryFee,proto3" json:"entry_fee,omitempty"`
+	TotalSlots        int32                  `protobuf:"varint,6,opt,name=total_slots,json=totalSlots,proto3" json:"total_slots,omitempty"`
+	RemainingSlots    int32                  `protobuf:"varint,7,opt,name=remaining_slots,json=remainingSlots,proto3" json:"remaining_slots,omitempty"`
+	FilledSlots       int32                  `protobuf:"varint,8,opt,name=filled_slots,json=filledSlots,proto3" json:"filled_slots,omitempty"`
+	FillPercent       float64                `protobuf:"fixed64,9,opt,name=fill_percent,json=fillPercent,proto3" json:"fill_percent,omitempty"`
+	MaxEntriesPerUser int32                  `protobuf:"varint,10,opt,name=max_entries_per_user,json=maxEntriesPerUser,proto3" json:"max_entries_per_user,omitempty"`
+	RosterSize        int32                  `protobuf:"varint,11,opt,name=roster_size,json=rosterSize,proto3" json:"roster_size,omitempty"`
+	Status            string                 `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`
+	IsPrivate         bool                   `protobuf:"varint,13,opt,name=is_private,json=isPrivate,proto3" json:"is_private,omitempty"`
+	IsLocked          bool                   `protobuf:"varint,14,opt,name=is_locked,json=isLocked,proto3" json:"is_locked,omitempty"`
+	IsGuaranteed      bool                   `protobuf:"varint,15,opt,name=is_guaranteed,json=isGuaranteed,proto3" json:"is_guaranteed,omitempty"`
+	MinFillPercent    int32                  `protobuf:"varint,16,opt,name=min_fill_percent,json=minFillPercent,proto3" json:"min_fill_percent,omitempty"`
+	SlateId           int64                  `protobuf:"varint,17,opt,name=slate_id,json=slateId,proto3" json:"slate_id,omitempty"`
+	SeriesId          int64                  `protobuf:"varint,18,opt,name=series_id,json=seriesId,proto3" json:"series_id,omitempty"`
+	CloneNumber       int32                  `protobuf:"varint,19,opt,name=clone_number,json=cloneNumber,proto3" json:"clone_number,omitempty"`
+	StartDate         *timestamppb.Timestamp `protobuf:"bytes,20,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate           *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	LocksAt           *timestamppb.Timestamp `protobuf:"bytes,22,opt,name=locks_at,json=locksAt,proto3" json:"locks_at,omitempty"`
+	SecondsToLock     int64                  `protobuf:"varint,23,opt,name=seconds_to_lock,json=secondsToLock,proto3" json:"seconds_to_lock,omitempty"`
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,24,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Only set in the lobby: the number of contests and entries across the series
+	SeriesContests int32 `protobuf:"varint,25,opt,name=series_contests,json=seriesContests,proto3" json:"series_contests,omitempty"`
+	SeriesEntries  int32 `protobuf:"varint,26,opt,name=series_entries,json=seriesEntries,proto3" json:"series_entries,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ContestInfo) Reset() {
+	*x = ContestInfo{}
+	mi := &file_fantasy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContestInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContestInfo) ProtoMessage() {}
+
+func (x *ContestInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContestInfo.ProtoReflect.Descriptor instead.
+func (*ContestInfo) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ContestInfo) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ContestInfo) GetPrize() float64 {
+	if x != nil {
+		return x.Prize
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetPrizeStructure() string {
+	if x != nil {
+		return x.PrizeStructure
+	}
+	return ""
+}
+
+func (x *ContestInfo) GetEntryFee() float64 {
+	if x != nil {
+		return x.EntryFee
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetTotalSlots() int32 {
+	if x != nil {
+		return x.TotalSlots
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetRemainingSlots() int32 {
+	if x != nil {
+		return x.RemainingSlots
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetFilledSlots() int32 {
+	if x != nil {
+		return x.FilledSlots
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetFillPercent() float64 {
+	if x != nil {
+		return x.FillPercent
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetMaxEntriesPerUser() int32 {
+	if x != nil {
+		return x.MaxEntriesPerUser
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetRosterSize() int32 {
+	if x != nil {
+		return x.RosterSize
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ContestInfo) GetIsPrivate() bool {
+	if x != nil {
+		return x.IsPrivate
+	}
+	return false
+}
+
+func (x *ContestInfo) GetIsLocked() bool {
+	if x != nil {
+		return x.IsLocked
+	}
+	return false
+}
+
+func (x *ContestInfo) GetIsGuaranteed() bool {
+	if x != nil {
+		return x.IsGuaranteed
+	}
+	return false
+}
+
+func (x *ContestInfo) GetMinFillPercent() int32 {
+	if x != nil {
+		return x.MinFillPercent
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetSlateId() int64 {
+	if x != nil {
+		return x.SlateId
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetSeriesId() int64 {
+	if x != nil {
+		return x.SeriesId
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetCloneNumber() int32 {
+	if x != nil {
+		return x.CloneNumber
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *ContestInfo) GetEndDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+func (x *ContestInfo) GetLocksAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LocksAt
+	}
+	return nil
+}
+
+func (x *ContestInfo) GetSecondsToLock() int64 {
+	if x != nil {
+		return x.SecondsToLock
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ContestInfo) GetSeriesContests() int32 {
+	if x != nil {
+		return x.SeriesContests
+	}
+	return 0
+}
+
+func (x *ContestInfo) GetSeriesEntries() int32 {
+	if x != nil {
+		return x.SeriesEntries
+	}
+	return 0
+}
+
+type ListContestsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListContestsRequest) Reset() {
+	*x = ListContestsRequest{}
+	mi := &file_fantasy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListContestsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListContestsRequest) ProtoMessage() {}
+
+func (x *ListContestsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListContestsRequest.ProtoReflect.Descriptor instead.
+func (*ListContestsRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{1}
+}
+
+type ListContestsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Contests      []*ContestInfo         `protobuf:"bytes,1,rep,name=contests,proto3" json:"contests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListContestsResponse) Reset() {
+	*x = ListContestsResponse{}
+	mi := &file_fantasy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListContestsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListContestsResponse) ProtoMessage() {}
+
+func (x *ListContestsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListContestsResponse.ProtoReflect.Descriptor instead.
+func (*ListContestsResponse) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListContestsResponse) GetContests() []*ContestInfo {
+	if x != nil {
+		return x.Contests
+	}
+	return nil
+}
+
+type GetContestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContestId     int64                  `protobuf:"varint,1,opt,name=contest_id,json=contestId,proto3" json:"contest_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetContestRequest) Reset() {
+	*x = GetContestRequest{}
+	mi := &file_fantasy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetContestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetContestRequest) ProtoMessage() {}
+
+func (x *GetContestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetContestRequest.ProtoReflect.Descriptor instead.
+func (*GetContestRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetContestRequest) GetContestId() int64 {
+	if x != nil {
+		return x.ContestId
+	}
+	return 0
+}
+
+type NewContestRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Prize             float64                `protobuf:"fixed64,2,opt,name=prize,proto3" json:"prize,omitempty"`
+	EntryFee          float64                `protobuf:"fixed64,3,opt,name=entry_fee,json=entryFee,proto3" json:"entry_fee,omitempty"`
+	TotalSlots        int32                  `protobuf:"varint,4,opt,name=total_slots,json=totalSlots,proto3" json:"total_slots,omitempty"`
+	MaxEntriesPerUser int32                  `protobuf:"varint,5,opt,name=max_entries_per_user,json=maxEntriesPerUser,proto3" json:"max_entries_per_user,omitempty"`
+	RosterSize        int32                  `protobuf:"varint,6,opt,name=roster_size,json=rosterSize,proto3" json:"roster_size,omitempty"`
+	IsGuaranteed      bool                   `protobuf:"varint,7,opt,name=is_guaranteed,json=isGuaranteed,proto3" json:"is_guaranteed,omitempty"`
+	MinFillPercent    int32                  `protobuf:"varint,8,opt,name=min_fill_percent,json=minFillPercent,proto3" json:"min_fill_percent,omitempty"`
+	AutoClone         bool                   `protobuf:"varint,9,opt,name=auto_clone,json=autoClone,proto3" json:"auto_clone,omitempty"`
+	MaxClones         int32                  `protobuf:"varint,10,opt,name=max_clones,json=maxClones,proto3" json:"max_clones,omitempty"`
+	StartDate         *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate           *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *NewContestRequest) Reset() {
+	*x = NewContestRequest{}
+	mi := &file_fantasy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewContestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewContestRequest) ProtoMessage() {}
+
+func (x *NewContestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewContestRequest.ProtoReflect.Descriptor instead.
+func (*NewContestRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NewContestRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NewContestRequest) GetPrize() float64 {
+	if x != nil {
+		return x.Prize
+	}
+	return 0
+}
+
+func (x *NewContestRequest) GetEntryFee() float64 {
+	if x != nil {
+		return x.EntryFee
+	}
+	return 0
+}
+
+func (x *NewContestRequest) GetTotalSlots() int32 {
+	if x != nil {
+		return x.TotalSlots
+	}
+	return 0
+}
+
+func (x *NewContestRequest) GetMaxEntriesPerUser() int32 {
+	if x != nil {
+		return x.MaxEntriesPerUser
+	}
+	return 0
+}
+
+func (x *NewContestRequest) GetRosterSize() int32 {
+	if x != nil {
+		return x.RosterSize
+	}
+	return 0
+}
+
+func (x *NewContestRequest) GetIsGuaranteed() bool {
+	if x != nil {
+		return x.IsGuaranteed
+	}
+	return false
+}
+
+func (x *NewContestRequest) GetMinFillPercent() int32 {
+	if x != nil {
+		return x.MinFillPercent
+	}
+	return 0
+}
+
+func (x *NewContestRequest) GetAutoClone() bool {
+	if x != nil {
+		return x.AutoClone
+	}
+	return false
+}
+
+func (x *NewContestRequest) GetMaxClones() int32 {
+	if x != nil {
+		return x.MaxClones
+	}
+	return 0
+}
+
+func (x *NewContestRequest) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *NewContestRequest) GetEndDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+type LeaderboardRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rank          int32                  `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"`
+	EntryId       int64                  `protobuf:"varint,2,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Points        float64                `protobuf:"fixed64,4,opt,name=points,proto3" json:"points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaderboardRow) Reset() {
+	*x = LeaderboardRow{}
+	mi := &file_fantasy_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaderboardRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaderboardRow) ProtoMessage() {}
+
+func (x *LeaderboardRow) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaderboardRow.ProtoReflect.Descriptor instead.
+func (*LeaderboardRow) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *LeaderboardRow) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *LeaderboardRow) GetEntryId() int64 {
+	if x != nil {
+		return x.EntryId
+	}
+	return 0
+}
+
+func (x *LeaderboardRow) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *LeaderboardRow) GetPoints() float64 {
+	if x != nil {
+		return x.Points
+	}
+	return 0
+}
+
+type Leaderboard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContestId     int64                  `protobuf:"varint,1,opt,name=contest_id,json=contestId,proto3" json:"contest_id,omitempty"`
+	Rows          []*LeaderboardRow      `protobuf:"bytes,2,rep,name=rows,proto3" json:"rows,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Leaderboard) Reset() {
+	*x = Leaderboard{}
+	mi := &file_fantasy_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Leaderboard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Leaderboard) ProtoMessage() {}
+
+func (x *Leaderboard) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Leaderboard.ProtoReflect.Descriptor instead.
+func (*Leaderboard) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Leaderboard) GetContestId() int64 {
+	if x != nil {
+		return x.ContestId
+	}
+	return 0
+}
+
+func (x *Leaderboard) GetRows() []*LeaderboardRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+func (x *Leaderboard) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type GetLeaderboardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContestId     int64                  `protobuf:"varint,1,opt,name=contest_id,json=contestId,proto3" json:"contest_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLeaderboardRequest) Reset() {
+	*x = GetLeaderboardRequest{}
+	mi := &file_fantasy_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLeaderboardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLeaderboardRequest) ProtoMessage() {}
+
+func (x *GetLeaderboardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLeaderboardRequest.ProtoReflect.Descriptor instead.
+func (*GetLeaderboardRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetLeaderboardRequest) GetContestId() int64 {
+	if x != nil {
+		return x.ContestId
+	}
+	return 0
+}
+
+type WatchLeaderboardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContestId     int64                  `protobuf:"varint,1,opt,name=contest_id,json=contestId,proto3" json:"contest_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchLeaderboardRequest) Reset() {
+	*x = WatchLeaderboardRequest{}
+	mi := &file_fantasy_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchLeaderboardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchLeaderboardRequest) ProtoMessage() {}
+
+func (x *WatchLeaderboardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchLeaderboardRequest.ProtoReflect.Descriptor instead.
+func (*WatchLeaderboardRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *WatchLeaderboardRequest) GetContestId() int64 {
+	if x != nil {
+		return x.ContestId
+	}
+	return 0
+}
+
+type TeamInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,3,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TeamInfo) Reset() {
+	*x = TeamInfo{}
+	mi := &file_fantasy_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TeamInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TeamInfo) ProtoMessage() {}
+
+func (x *TeamInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TeamInfo.ProtoReflect.Descriptor instead.
+func (*TeamInfo) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TeamInfo) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TeamInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TeamInfo) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *TeamInfo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type NewTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewTeamRequest) Reset() {
+	*x = NewTeamRequest{}
+	mi := &file_fantasy_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewTeamRequest) ProtoMessage() {}
+
+func (x *NewTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewTeamRequest.ProtoReflect.Descriptor instead.
+func (*NewTeamRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *NewTeamRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NewTeamRequest) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+type ListTeamsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTeamsRequest) Reset() {
+	*x = ListTeamsRequest{}
+	mi := &file_fantasy_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTeamsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTeamsRequest) ProtoMessage() {}
+
+func (x *ListTeamsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTeamsRequest.ProtoReflect.Descriptor instead.
+func (*ListTeamsRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{11}
+}
+
+type ListTeamsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Teams         []*TeamInfo            `protobuf:"bytes,1,rep,name=teams,proto3" json:"teams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTeamsResponse) Reset() {
+	*x = ListTeamsResponse{}
+	mi := &file_fantasy_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTeamsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTeamsResponse) ProtoMessage() {}
+
+func (x *ListTeamsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTeamsResponse.ProtoReflect.Descriptor instead.
+func (*ListTeamsResponse) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListTeamsResponse) GetTeams() []*TeamInfo {
+	if x != nil {
+		return x.Teams
+	}
+	return nil
+}
+
+type GetTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TeamId        int64                  `protobuf:"varint,1,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTeamRequest) Reset() {
+	*x = GetTeamRequest{}
+	mi := &file_fantasy_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTeamRequest) ProtoMessage() {}
+
+func (x *GetTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTeamRequest.ProtoReflect.Descriptor instead.
+func (*GetTeamRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetTeamRequest) GetTeamId() int64 {
+	if x != nil {
+		return x.TeamId
+	}
+	return 0
+}
+
+type EntryInfo struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ContestId   int64                  `protobuf:"varint,2,opt,name=contest_id,json=contestId,proto3" json:"contest_id,omitempty"`
+	ContestName string                 `protobuf:"bytes,3,opt,name=contest_name,json=contestName,proto3" json:"contest_name,omitempty"`
+	UserId      int64                  `protobuf:"varint,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Status      string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Lineup      []int64                `protobuf:"varint,6,rep,packed,name=lineup,proto3" json:"lineup,omitempty"`
+	// Maps a role such as "captain" to the player holding it
+	Roles         map[string]int64       `protobuf:"bytes,7,rep,name=roles,proto3" json:"roles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryInfo) Reset() {
+	*x = EntryInfo{}
+	mi := &file_fantasy_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryInfo) ProtoMessage() {}
+
+func (x *EntryInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryInfo.ProtoReflect.Descriptor instead.
+func (*EntryInfo) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *EntryInfo) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *EntryInfo) GetContestId() int64 {
+	if x != nil {
+		return x.ContestId
+	}
+	return 0
+}
+
+func (x *EntryInfo) GetContestName() string {
+	if x != nil {
+		return x.ContestName
+	}
+	return ""
+}
+
+func (x *EntryInfo) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *EntryInfo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *EntryInfo) GetLineup() []int64 {
+	if x != nil {
+		return x.Lineup
+	}
+	return nil
+}
+
+func (x *EntryInfo) GetRoles() map[string]int64 {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *EntryInfo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type EnterContestRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	ContestId int64                  `protobuf:"varint,1,opt,name=contest_id,json=contestId,proto3" json:"contest_id,omitempty"`
+	Lineup    []int64                `protobuf:"varint,2,rep,packed,name=lineup,proto3" json:"lineup,omitempty"`
+	Roles     map[string]int64       `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// Only needed for private contests
+	InviteCode    string `protobuf:"bytes,4,opt,name=invite_code,json=inviteCode,proto3" json:"invite_code,omitempty"`
+	Password      string `protobuf:"bytes,5,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnterContestRequest) Reset() {
+	*x = EnterContestRequest{}
+	mi := &file_fantasy_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnterContestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnterContestRequest) ProtoMessage() {}
+
+func (x *EnterContestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnterContestRequest.ProtoReflect.Descriptor instead.
+func (*EnterContestRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *EnterContestRequest) GetContestId() int64 {
+	if x != nil {
+		return x.ContestId
+	}
+	return 0
+}
+
+func (x *EnterContestRequest) GetLineup() []int64 {
+	if x != nil {
+		return x.Lineup
+	}
+	return nil
+}
+
+func (x *EnterContestRequest) GetRoles() map[string]int64 {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *EnterContestRequest) GetInviteCode() string {
+	if x != nil {
+		return x.InviteCode
+	}
+	return ""
+}
+
+func (x *EnterContestRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type EnterContestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntryId       int64                  `protobuf:"varint,1,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnterContestResponse) Reset() {
+	*x = EnterContestResponse{}
+	mi := &file_fantasy_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnterContestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnterContestResponse) ProtoMessage() {}
+
+func (x *EnterContestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnterContestResponse.ProtoReflect.Descriptor instead.
+func (*EnterContestResponse) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *EnterContestResponse) GetEntryId() int64 {
+	if x != nil {
+		return x.EntryId
+	}
+	return 0
+}
+
+type ListEntriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEntriesRequest) Reset() {
+	*x = ListEntriesRequest{}
+	mi := &file_fantasy_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEntriesRequest) ProtoMessage() {}
+
+func (x *ListEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEntriesRequest.ProtoReflect.Descriptor instead.
+func (*ListEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{17}
+}
+
+type ListEntriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*EntryInfo           `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEntriesResponse) Reset() {
+	*x = ListEntriesResponse{}
+	mi := &file_fantasy_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEntriesResponse) ProtoMessage() {}
+
+func (x *ListEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEntriesResponse.ProtoReflect.Descriptor instead.
+func (*ListEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListEntriesResponse) GetEntries() []*EntryInfo {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type UpdateLineupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntryId       int64                  `protobuf:"varint,1,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	Lineup        []int64                `protobuf:"varint,2,rep,packed,name=lineup,proto3" json:"lineup,omitempty"`
+	Roles         map[string]int64       `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateLineupRequest) Reset() {
+	*x = UpdateLineupRequest{}
+	mi := &file_fantasy_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLineupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLineupRequest) ProtoMessage() {}
+
+func (x *UpdateLineupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLineupRequest.ProtoReflect.Descriptor instead.
+func (*UpdateLineupRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UpdateLineupRequest) GetEntryId() int64 {
+	if x != nil {
+		return x.EntryId
+	}
+	return 0
+}
+
+func (x *UpdateLineupRequest) GetLineup() []int64 {
+	if x != nil {
+		return x.Lineup
+	}
+	return nil
+}
+
+func (x *UpdateLineupRequest) GetRoles() map[string]int64 {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+type UpdateLineupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateLineupResponse) Reset() {
+	*x = UpdateLineupResponse{}
+	mi := &file_fantasy_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLineupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLineupResponse) ProtoMessage() {}
+
+func (x *UpdateLineupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLineupResponse.ProtoReflect.Descriptor instead.
+func (*UpdateLineupResponse) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{20}
+}
+
+type LeaveContestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContestId     int64                  `protobuf:"varint,1,opt,name=contest_id,json=contestId,proto3" json:"contest_id,omitempty"`
+	EntryId       int64                  `protobuf:"varint,2,opt,name=entry_id,json=entryId,proto3" json:"entry_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveContestRequest) Reset() {
+	*x = LeaveContestRequest{}
+	mi := &file_fantasy_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaveContestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaveContestRequest) ProtoMessage() {}
+
+func (x *LeaveContestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaveContestRequest.ProtoReflect.Descriptor instead.
+func (*LeaveContestRequest) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *LeaveContestRequest) GetContestId() int64 {
+	if x != nil {
+		return x.ContestId
+	}
+	return 0
+}
+
+func (x *LeaveContestRequest) GetEntryId() int64 {
+	if x != nil {
+		return x.EntryId
+	}
+	return 0
+}
+
+type LeaveContestResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaveContestResponse) Reset() {
+	*x = LeaveContestResponse{}
+	mi := &file_fantasy_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaveContestResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaveContestResponse) ProtoMessage() {}
+
+func (x *LeaveContestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fantasy_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaveContestResponse.ProtoReflect.Descriptor instead.
+func (*LeaveContestResponse) Descriptor() ([]byte, []int) {
+	return file_fantasy_proto_rawDescGZIP(), []int{22}
+}
+
+var File_fantasy_proto protoreflect.FileDescriptor
+
+const file_fantasy_proto_rawDesc = "" +
+	"\n" +
+	"\rfantasy.proto\x12\n" +
+	"fantasy.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc9\a\n" +
+	"\vContestInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05prize\x18\x03 \x01(\x01R\x05prize\x12'\n" +
+	"\x0fprize_structure\x18\x04 \x01(\tR\x0eprizeStructure\x12\x1b\n" +
+	"\tentry_fee\x18\x05 \x01(\x01R\bentryFee\x12\x1f\n" +
+	"\vtotal_slots\x18\x06 \x01(\x05R\n" +
+	"totalSlots\x12'\n" +
+	"\x0fremaining_slots\x18\a \x01(\x05R\x0eremainingSlots\x12!\n" +
+	"\ffilled_slots\x18\b \x01(\x05R\vfilledSlots\x12!\n" +
+	"\ffill_percent\x18\t \x01(\x01R\vfillPercent\x12/\n" +
+	"\x14max_entries_per_user\x18\n" +
+	" \x01(\x05R\x11maxEntriesPerUser\x12\x1f\n" +
+	"\vroster_size\x18\v \x01(\x05R\n" +
+	"rosterSize\x12\x16\n" +
+	"\x06status\x18\f \x01(\tR\x06status\x12\x1d\n" +
+	"\n" +
+	"is_private\x18\r \x01(\bR\tisPrivate\x12\x1b\n" +
+	"\tis_locked\x18\x0e \x01(\bR\bisLocked\x12#\n" +
+	"\ris_guaranteed\x18\x0f \x01(\bR\fisGuaranteed\x12(\n" +
+	"\x10min_fill_percent\x18\x10 \x01(\x05R\x0eminFillPercent\x12\x19\n" +
+	"\bslate_id\x18\x11 \x01(\x03R\aslateId\x12\x1b\n" +
+	"\tseries_id\x18\x12 \x01(\x03R\bseriesId\x12!\n" +
+	"\fclone_number\x18\x13 \x01(\x05R\vcloneNumber\x129\n" +
+	"\n" +
+	"start_date\x18\x14 \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x125\n" +
+	"\bend_date\x18\x15 \x01(\v2\x1a.google.protobuf.TimestampR\aendDate\x125\n" +
+	"\blocks_at\x18\x16 \x01(\v2\x1a.google.protobuf.TimestampR\alocksAt\x12&\n" +
+	"\x0fseconds_to_lock\x18\x17 \x01(\x03R\rsecondsToLock\x129\n" +
+	"\n" +
+	"created_at\x18\x18 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12'\n" +
+	"\x0fseries_contests\x18\x19 \x01(\x05R\x0eseriesContests\x12%\n" +
+	"\x0eseries_entries\x18\x1a \x01(\x05R\rseriesEntries\"\x15\n" +
+	"\x13ListContestsRequest\"K\n" +
+	"\x14ListContestsResponse\x123\n" +
+	"\bcontests\x18\x01 \x03(\v2\x17.fantasy.v1.ContestInfoR\bcontests\"2\n" +
+	"\x11GetContestRequest\x12\x1d\n" +
+	"\n" +
+	"contest_id\x18\x01 \x01(\x03R\tcontestId\"\xcc\x03\n" +
+	"\x11NewContestRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05prize\x18\x02 \x01(\x01R\x05prize\x12\x1b\n" +
+	"\tentry_fee\x18\x03 \x01(\x01R\bentryFee\x12\x1f\n" +
+	"\vtotal_slots\x18\x04 \x01(\x05R\n" +
+	"totalSlots\x12/\n" +
+	"\x14max_entries_per_user\x18\x05 \x01(\x05R\x11maxEntriesPerUser\x12\x1f\n" +
+	"\vroster_size\x18\x06 \x01(\x05R\n" +
+	"rosterSize\x12#\n" +
+	"\ris_guaranteed\x18\a \x01(\bR\fisGuaranteed\x12(\n" +
+	"\x10min_fill_percent\x18\b \x01(\x05R\x0eminFillPercent\x12\x1d\n" +
+	"\n" +
+	"auto_clone\x18\t \x01(\bR\tautoClone\x12\x1d\n" +
+	"\n" +
+	"max_clones\x18\n" +
+	" \x01(\x05R\tmaxClones\x129\n" +
+	"\n" +
+	"start_date\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x125\n" +
+	"\bend_date\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\aendDate\"p\n" +
+	"\x0eLeaderboardRow\x12\x12\n" +
+	"\x04rank\x18\x01 \x01(\x05R\x04rank\x12\x19\n" +
+	"\bentry_id\x18\x02 \x01(\x03R\aentryId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06points\x18\x04 \x01(\x01R\x06points\"\x97\x01\n" +
+	"\vLeaderboard\x12\x1d\n" +
+	"\n" +
+	"contest_id\x18\x01 \x01(\x03R\tcontestId\x12.\n" +
+	"\x04rows\x18\x02 \x03(\v2\x1a.fantasy.v1.LeaderboardRowR\x04rows\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"6\n" +
+	"\x15GetLeaderboardRequest\x12\x1d\n" +
+	"\n" +
+	"contest_id\x18\x01 \x01(\x03R\tcontestId\"8\n" +
+	"\x17WatchLeaderboardRequest\x12\x1d\n" +
+	"\n" +
+	"contest_id\x18\x01 \x01(\x03R\tcontestId\"\x8c\x01\n" +
+	"\bTeamInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\fdisplay_name\x18\x03 \x01(\tR\vdisplayName\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"G\n" +
+	"\x0eNewTeamRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\"\x12\n" +
+	"\x10ListTeamsRequest\"?\n" +
+	"\x11ListTeamsResponse\x12*\n" +
+	"\x05teams\x18\x01 \x03(\v2\x14.fantasy.v1.TeamInfoR\x05teams\")\n" +
+	"\x0eGetTeamRequest\x12\x17\n" +
+	"\ateam_id\x18\x01 \x01(\x03R\x06teamId\"\xd3\x02\n" +
+	"\tEntryInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"contest_id\x18\x02 \x01(\x03R\tcontestId\x12!\n" +
+	"\fcontest_name\x18\x03 \x01(\tR\vcontestName\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x16\n" +
+	"\x06lineup\x18\x06 \x03(\x03R\x06lineup\x126\n" +
+	"\x05roles\x18\a \x03(\v2 .fantasy.v1.EntryInfo.RolesEntryR\x05roles\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x1a8\n" +
+	"\n" +
+	"RolesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x85\x02\n" +
+	"\x13EnterContestRequest\x12\x1d\n" +
+	"\n" +
+	"contest_id\x18\x01 \x01(\x03R\tcontestId\x12\x16\n" +
+	"\x06lineup\x18\x02 \x03(\x03R\x06lineup\x12@\n" +
+	"\x05roles\x18\x03 \x03(\v2*.fantasy.v1.EnterContestRequest.RolesEntryR\x05roles\x12\x1f\n" +
+	"\vinvite_code\x18\x04 \x01(\tR\n" +
+	"inviteCode\x12\x1a\n" +
+	"\bpassword\x18\x05 \x01(\tR\bpassword\x1a8\n" +
+	"\n" +
+	"RolesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"1\n" +
+	"\x14EnterContestResponse\x12\x19\n" +
+	"\bentry_id\x18\x01 \x01(\x03R\aentryId\"\x14\n" +
+	"\x12ListEntriesRequest\"F\n" +
+	"\x13ListEntriesResponse\x12/\n" +
+	"\aentries\x18\x01 \x03(\v2\x15.fantasy.v1.EntryInfoR\aentries\"\xc4\x01\n" +
+	"\x13UpdateLineupRequest\x12\x19\n" +
+	"\bentry_id\x18\x01 \x01(\x03R\aentryId\x12\x16\n" +
+	"\x06lineup\x18\x02 \x03(\x03R\x06lineup\x12@\n" +
+	"\x05roles\x18\x03 \x03(\v2*.fantasy.v1.UpdateLineupRequest.RolesEntryR\x05roles\x1a8\n" +
+	"\n" +
+	"RolesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\x16\n" +
+	"\x14UpdateLineupResponse\"O\n" +
+	"\x13LeaveContestRequest\x12\x1d\n" +
+	"\n" +
+	"contest_id\x18\x01 \x01(\x03R\tcontestId\x12\x19\n" +
+	"\bentry_id\x18\x02 \x01(\x03R\aentryId\"\x16\n" +
+	"\x14LeaveContestResponse2\x9d\a\n" +
+	"\aFantasy\x12Q\n" +
+	"\fListContests\x12\x1f.fantasy.v1.ListContestsRequest\x1a .fantasy.v1.ListContestsResponse\x12D\n" +
+	"\n" +
+	"GetContest\x12\x1d.fantasy.v1.GetContestRequest\x1a\x17.fantasy.v1.ContestInfo\x12G\n" +
+	"\rCreateContest\x12\x1d.fantasy.v1.NewContestRequest\x1a\x17.fantasy.v1.ContestInfo\x12L\n" +
+	"\x0eGetLeaderboard\x12!.fantasy.v1.GetLeaderboardRequest\x1a\x17.fantasy.v1.Leaderboard\x12R\n" +
+	"\x10WatchLeaderboard\x12#.fantasy.v1.WatchLeaderboardRequest\x1a\x17.fantasy.v1.Leaderboard0\x01\x12>\n" +
+	"\n" +
+	"CreateTeam\x12\x1a.fantasy.v1.NewTeamRequest\x1a\x14.fantasy.v1.TeamInfo\x12H\n" +
+	"\tListTeams\x12\x1c.fantasy.v1.ListTeamsRequest\x1a\x1d.fantasy.v1.ListTeamsResponse\x12;\n" +
+	"\aGetTeam\x12\x1a.fantasy.v1.GetTeamRequest\x1a\x14.fantasy.v1.TeamInfo\x12Q\n" +
+	"\fEnterContest\x12\x1f.fantasy.v1.EnterContestRequest\x1a .fantasy.v1.EnterContestResponse\x12N\n" +
+	"\vListEntries\x12\x1e.fantasy.v1.ListEntriesRequest\x1a\x1f.fantasy.v1.ListEntriesResponse\x12Q\n" +
+	"\fUpdateLineup\x12\x1f.fantasy.v1.UpdateLineupRequest\x1a .fantasy.v1.UpdateLineupResponse\x12Q\n" +
+	"\fLeaveContest\x12\x1f.fantasy.v1.LeaveContestRequest\x1a .fantasy.v1.LeaveContestResponseB\tZ\a./;mainb\x06proto3"
+
+var (
+	file_fantasy_proto_rawDescOnce sync.Once
+	file_fantasy_proto_rawDescData []byte
+)
+
+func file_fantasy_proto_rawDescGZIP() []byte {
+	file_fantasy_proto_rawDescOnce.Do(func() {
+		file_fantasy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_fantasy_proto_rawDesc), len(file_fantasy_proto_rawDesc)))
+	})
+	return file_fantasy_proto_rawDescData
+}
+
+var file_fantasy_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_fantasy_proto_goTypes = []any{
+	(*ContestInfo)(nil),             // 0: fantasy.v1.ContestInfo
+	(*ListContestsRequest)(nil),     // 1: fantasy.v1.ListContestsRequest
+	(*ListContestsResponse)(nil),    // 2: fantasy.v1.ListContestsResponse
+	(*GetContestRequest)(nil),       // 3: fantasy.v1.GetContestRequest
+	(*NewContestRequest)(nil),       // 4: fantasy.v1.NewContestRequest
+	(*LeaderboardRow)(nil),          // 5: fantasy.v1.LeaderboardRow
+	(*Leaderboard)(nil),             // 6: fantasy.v1.Leaderboard
+	(*GetLeaderboardRequest)(nil),   // 7: fantasy.v1.GetLeaderboardRequest
+	(*WatchLeaderboardRequest)(nil), // 8: fantasy.v1.WatchLeaderboardRequest
+	(*TeamInfo)(nil),                // 9: fantasy.v1.TeamInfo
+	(*NewTeamRequest)(nil),          // 10: fantasy.v1.NewTeamRequest
+	(*ListTeamsRequest)(nil),        // 11: fantasy.v1.ListTeamsRequest
+	(*ListTeamsResponse)(nil),       // 12: fantasy.v1.ListTeamsResponse
+	(*GetTeamRequest)(nil),          // 13: fantasy.v1.GetTeamRequest
+	(*EntryInfo)(nil),               // 14: fantasy.v1.EntryInfo
+	(*EnterContestRequest)(nil),     // 15: fantasy.v1.EnterContestRequest
+	(*EnterContestResponse)(nil),    // 16: fantasy.v1.EnterContestResponse
+	(*ListEntriesRequest)(nil),      // 17: fantasy.v1.ListEntriesRequest
+	(*ListEntriesResponse)(nil),     // 18: fantasy.v1.ListEntriesResponse
+	(*UpdateLineupRequest)(nil),     // 19: fantasy.v1.UpdateLineupRequest
+	(*UpdateLineupResponse)(nil),    // 20: fantasy.v1.UpdateLineupResponse
+	(*LeaveContestRequest)(nil),     // 21: fantasy.v1.LeaveContestRequest
+	(*LeaveContestResponse)(nil),    // 22: fantasy.v1.LeaveContestResponse
+	nil,                             // 23: fantasy.v1.EntryInfo.RolesEntry
+	nil,                             // 24: fantasy.v1.EnterContestRequest.RolesEntry
+	nil,                             // 25: fantasy.v1.UpdateLineupRequest.RolesEntry
+	(*timestamppb.Timestamp)(nil),   // 26: google.protobuf.Timestamp
+}
+var file_fantasy_proto_depIdxs = []int32{
+	26, // 0: fantasy.v1.ContestInfo.start_date:type_name -> google.protobuf.Timestamp
+	26, // 1: fantasy.v1.ContestInfo.end_date:type_name -> google.protobuf.Timestamp
+	26, // 2: fantasy.v1.ContestInfo.locks_at:type_name -> google.protobuf.Timestamp
+	26, // 3: fantasy.v1.ContestInfo.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 4: fantasy.v1.ListContestsResponse.contests:type_name -> fantasy.v1.ContestInfo
+	26, // 5: fantasy.v1.NewContestRequest.start_date:type_name -> google.protobuf.Timestamp
+	26, // 6: fantasy.v1.NewContestRequest.end_date:type_name -> google.protobuf.Timestamp
+	5,  // 7: fantasy.v1.Leaderboard.rows:type_name -> fantasy.v1.LeaderboardRow
+	26, // 8: fantasy.v1.Leaderboard.updated_at:type_name -> google.protobuf.Timestamp
+	26, // 9: fantasy.v1.TeamInfo.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 10: fantasy.v1.ListTeamsResponse.teams:type_name -> fantasy.v1.TeamInfo
+	23, // 11: fantasy.v1.EntryInfo.roles:type_name -> fantasy.v1.EntryInfo.RolesEntry
+	26, // 12: fantasy.v1.EntryInfo.created_at:type_name -> google.protobuf.Timestamp
+	24, // 13: fantasy.v1.EnterContestRequest.roles:type_name -> fantasy.v1.EnterContestRequest.RolesEntry
+	14, // 14: fantasy.v1.ListEntriesResponse.entries:type_name -> fantasy.v1.EntryInfo
+	25, // 15: fantasy.v1.UpdateLineupRequest.roles:type_name -> fantasy.v1.UpdateLineupRequest.RolesEntry
+	1,  // 16: fantasy.v1.Fantasy.ListContests:input_type -> fantasy.v1.ListContestsRequest
+	3,  // 17: fantasy.v1.Fantasy.GetContest:input_type -> fantasy.v1.GetContestRequest
+	4,  // 18: fantasy.v1.Fantasy.CreateContest:input_type -> fantasy.v1.NewContestRequest
+	7,  // 19: fantasy.v1.Fantasy.GetLeaderboard:input_type -> fantasy.v1.GetLeaderboardRequest
+	8,  // 20: fantasy.v1.Fantasy.WatchLeaderboard:input_type -> fantasy.v1.WatchLeaderboardRequest
+	10, // 21: fantasy.v1.Fantasy.CreateTeam:input_type -> fantasy.v1.NewTeamRequest
+	11, // 22: fantasy.v1.Fantasy.ListTeams:input_type -> fantasy.v1.ListTeamsRequest
+	13, // 23: fantasy.v1.Fantasy.GetTeam:input_type -> fantasy.v1.GetTeamRequest
+	15, // 24: fantasy.v1.Fantasy.EnterContest:input_type -> fantasy.v1.EnterContestRequest
+	17, // 25: fantasy.v1.Fantasy.ListEntries:input_type -> fantasy.v1.ListEntriesRequest
+	19, // 26: fantasy.v1.Fantasy.UpdateLineup:input_type -> fantasy.v1.UpdateLineupRequest
+	21, // 27: fantasy.v1.Fantasy.LeaveContest:input_type -> fantasy.v1.LeaveContestRequest
+	2,  // 28: fantasy.v1.Fantasy.ListContests:output_type -> fantasy.v1.ListContestsResponse
+	0,  // 29: fantasy.v1.Fantasy.GetContest:output_type -> fantasy.v1.ContestInfo
+	0,  // 30: fantasy.v1.Fantasy.CreateContest:output_type -> fantasy.v1.ContestInfo
+	6,  // 31: fantasy.v1.Fantasy.GetLeaderboard:output_type -> fantasy.v1.Leaderboard
+	6,  // 32: fantasy.v1.Fantasy.WatchLeaderboard:output_type -> fantasy.v1.Leaderboard
+	9,  // 33: fantasy.v1.Fantasy.CreateTeam:output_type -> fantasy.v1.TeamInfo
+	12, // 34: fantasy.v1.Fantasy.ListTeams:output_type -> fantasy.v1.ListTeamsResponse
+	9,  // 35: fantasy.v1.Fantasy.GetTeam:output_type -> fantasy.v1.TeamInfo
+	16, // 36: fantasy.v1.Fantasy.EnterContest:output_type -> fantasy.v1.EnterContestResponse
+	18, // 37: fantasy.v1.Fantasy.ListEntries:output_type -> fantasy.v1.ListEntriesResponse
+	20, // 38: fantasy.v1.Fantasy.UpdateLineup:output_type -> fantasy.v1.UpdateLineupResponse
+	22, // 39: fantasy.v1.Fantasy.LeaveContest:output_type -> fantasy.v1.LeaveContestResponse
+	28, // [28:40] is the sub-list for method output_type
+	16, // [16:28] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_fantasy_proto_init() }
+func file_fantasy_proto_init() {
+	if File_fantasy_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_fantasy_proto_rawDesc), len(file_fantasy_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   26,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_fantasy_proto_goTypes,
+		DependencyIndexes: file_fantasy_proto_depIdxs,
+		MessageInfos:      file_fantasy_proto_msgTypes,
+	}.Build()
+	File_fantasy_proto = out.File
+	file_fantasy_proto_goTypes = nil
+	file_fantasy_proto_depIdxs = nil
+}
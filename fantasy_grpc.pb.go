@@ -0,0 +1,566 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: fantasy.proto
+
+// The gRPC API of the contest, team and entry endpoints. It is served next to the HTTP
+// API and runs the same domain code, so both return the same data and the same errors.
+//
+// Calls that act for a user send the user's ID in the x-user-id metadata key, as the
+// API gateway does with the X-User-ID header. Failed calls carry a google.rpc.ErrorInfo
+// detail whose reason is the error code the HTTP API returns, e.g. "contest_full".
+//
+// Regenerate fantasy.pb.go and fantasy_grpc.pb.go with go generate after editing.
+
+package main
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Fantasy_ListContests_FullMethodName     = "/fantasy.v1.Fantasy/ListContests"
+	Fantasy_GetContest_FullMethodName       = "/fantasy.v1.Fantasy/GetContest"
+	Fantasy_CreateContest_FullMethodName    = "/fantasy.v1.Fantasy/CreateContest"
+	Fantasy_GetLeaderboard_FullMethodName   = "/fantasy.v1.Fantasy/GetLeaderboard"
+	Fantasy_WatchLeaderboard_FullMethodName = "/fantasy.v1.Fantasy/WatchLeaderboard"
+	Fantasy_CreateTeam_FullMethodName       = "/fantasy.v1.Fantasy/CreateTeam"
+	Fantasy_ListTeams_FullMethodName        = "/fantasy.v1.Fantasy/ListTeams"
+	Fantasy_GetTeam_FullMethodName          = "/fantasy.v1.Fantasy/GetTeam"
+	Fantasy_EnterContest_FullMethodName     = "/fantasy.v1.Fantasy/EnterContest"
+	Fantasy_ListEntries_FullMethodName      = "/fantasy.v1.Fantasy/ListEntries"
+	Fantasy_UpdateLineup_FullMethodName     = "/fantasy.v1.Fantasy/UpdateLineup"
+	Fantasy_LeaveContest_FullMethodName     = "/fantasy.v1.Fantasy/LeaveContest"
+)
+
+// FantasyClient is the client API for Fantasy service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FantasyClient interface {
+	// Lists the lobby, one row per contest series
+	ListContests(ctx context.Context, in *ListContestsRequest, opts ...grpc.CallOption) (*ListContestsResponse, error)
+	GetContest(ctx context.Context, in *GetContestRequest, opts ...grpc.CallOption) (*ContestInfo, error)
+	CreateContest(ctx context.Context, in *NewContestRequest, opts ...grpc.CallOption) (*ContestInfo, error)
+	// Ranks a contest's entries by fantasy points
+	GetLeaderboard(ctx context.Context, in *GetLeaderboardRequest, opts ...grpc.CallOption) (*Leaderboard, error)
+	// Sends the leaderboard at once, then again every time it changes
+	WatchLeaderboard(ctx context.Context, in *WatchLeaderboardRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Leaderboard], error)
+	CreateTeam(ctx context.Context, in *NewTeamRequest, opts ...grpc.CallOption) (*TeamInfo, error)
+	// Lists the teams that are not part of a league
+	ListTeams(ctx context.Context, in *ListTeamsRequest, opts ...grpc.CallOption) (*ListTeamsResponse, error)
+	GetTeam(ctx context.Context, in *GetTeamRequest, opts ...grpc.CallOption) (*TeamInfo, error)
+	// Enters the calling user into a contest, paying its entry fee
+	EnterContest(ctx context.Context, in *EnterContestRequest, opts ...grpc.CallOption) (*EnterContestResponse, error)
+	// Lists the calling user's entries across all contests
+	ListEntries(ctx context.Context, in *ListEntriesRequest, opts ...grpc.CallOption) (*ListEntriesResponse, error)
+	UpdateLineup(ctx context.Context, in *UpdateLineupRequest, opts ...grpc.CallOption) (*UpdateLineupResponse, error)
+	// Withdraws one of the calling user's entries and refunds its entry fee
+	LeaveContest(ctx context.Context, in *LeaveContestRequest, opts ...grpc.CallOption) (*LeaveContestResponse, error)
+}
+
+type fantasyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFantasyClient(cc grpc.ClientConnInterface) FantasyClient {
+	return &fantasyClient{cc}
+}
+
+func (c *fantasyClient) ListContests(ctx context.Context, in *ListContestsRequest, opts ...grpc.CallOption) (*ListContestsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListContestsResponse)
+	err := c.cc.Invoke(ctx, Fantasy_ListContests_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fantasyClient) GetContest(ctx context.Context, in *GetContestRequest, opts ...grpc.CallOption) (*ContestInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ContestInfo)
+	err := c.cc.Invoke(ctx, Fantasy_GetContest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fantasyClient) CreateContest(ctx context.Context, in *NewContestRequest, opts ...grpc.CallOption) (*ContestInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ContestInfo)
+	err := c.cc.Invoke(ctx, Fantasy_CreateContest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fantasyClient) GetLeaderboard(ctx context.Context, in *GetLeaderboardRequest, opts ...grpc.CallOption) (*Leaderboard, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Leaderboard)
+	err := c.cc.Invoke(ctx, Fantasy_GetLeaderboard_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fantasyClient) WatchLeaderboard(ctx context.Context, in *WatchLeaderboardRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Leaderboard], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Fantasy_ServiceDesc.Streams[0], Fantasy_WatchLeaderboard_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchLeaderboardRequest, Leaderboard]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Fantasy_WatchLeaderboardClient = grpc.ServerStreamingClient[Leaderboard]
+
+func (c *fantasyClient) CreateTeam(ctx context.Context, in *NewTeamRequest, opts ...grpc.CallOption) (*TeamInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TeamInfo)
+	err := c.cc.Invoke(ctx, Fantasy_CreateTeam_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fantasyClient) ListTeams(ctx context.Context, in *ListTeamsRequest, opts ...grpc.CallOption) (*ListTeamsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTeamsResponse)
+	err := c.cc.Invoke(ctx, Fantasy_ListTeams_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fantasyClient) GetTeam(ctx context.Context, in *GetTeamRequest, opts ...grpc.CallOption) (*TeamInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TeamInfo)
+	err := c.cc.Invoke(ctx, Fantasy_GetTeam_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fantasyClient) EnterContest(ctx context.Context, in *EnterContestRequest, opts ...grpc.CallOption) (*EnterContestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnterContestResponse)
+	err := c.cc.Invoke(ctx, Fantasy_EnterContest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fantasyClient) ListEntries(ctx context.Context, in *ListEntriesRequest, opts ...grpc.CallOption) (*ListEntriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEntriesResponse)
+	err := c.cc.Invoke(ctx, Fantasy_ListEntries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fantasyClient) UpdateLineup(ctx context.Context, in *UpdateLineupRequest, opts ...grpc.CallOption) (*UpdateLineupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateLineupResponse)
+	err := c.cc.Invoke(ctx, Fantasy_UpdateLineup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fantasyClient) LeaveContest(ctx context.Context, in *LeaveContestRequest, opts ...grpc.CallOption) (*LeaveContestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaveContestResponse)
+	err := c.cc.Invoke(ctx, Fantasy_LeaveContest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FantasyServer is the server API for Fantasy service.
+// All implementations must embed UnimplementedFantasyServer
+// for forward compatibility.
+type FantasyServer interface {
+	// Lists the lobby, one row per contest series
+	ListContests(context.Context, *ListContestsRequest) (*ListContestsResponse, error)
+	GetContest(context.Context, *GetContestRequest) (*ContestInfo, error)
+	CreateContest(context.Context, *NewContestRequest) (*ContestInfo, error)
+	// Ranks a contest's entries by fantasy points
+	GetLeaderboard(context.Context, *GetLeaderboardRequest) (*Leaderboard, error)
+	// Sends the leaderboard at once, then again every time it changes
+	WatchLeaderboard(*WatchLeaderboardRequest, grpc.ServerStreamingServer[Leaderboard]) error
+	CreateTeam(context.Context, *NewTeamRequest) (*TeamInfo, error)
+	// Lists the teams that are not part of a league
+	ListTeams(context.Context, *ListTeamsRequest) (*ListTeamsResponse, error)
+	GetTeam(context.Context, *GetTeamRequest) (*TeamInfo, error)
+	// Enters the calling user into a contest, paying its entry fee
+	EnterContest(context.Context, *EnterContestRequest) (*EnterContestResponse, error)
+	// Lists the calling user's entries across all contests
+	ListEntries(context.Context, *ListEntriesRequest) (*ListEntriesResponse, error)
+	UpdateLineup(context.Context, *UpdateLineupRequest) (*UpdateLineupResponse, error)
+	// Withdraws one of the calling user's entries and refunds its entry fee
+	LeaveContest(context.Context, *LeaveContestRequest) (*LeaveContestResponse, error)
+	mustEmbedUnimplementedFantasyServer()
+}
+
+// UnimplementedFantasyServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFantasyServer struct{}
+
+func (UnimplementedFantasyServer) ListContests(context.Context, *ListContestsRequest) (*ListContestsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListContests not implemented")
+}
+func (UnimplementedFantasyServer) GetContest(context.Context, *GetContestRequest) (*ContestInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetContest not implemented")
+}
+func (UnimplementedFantasyServer) CreateContest(context.Context, *NewContestRequest) (*ContestInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateContest not implemented")
+}
+func (UnimplementedFantasyServer) GetLeaderboard(context.Context, *GetLeaderboardRequest) (*Leaderboard, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLeaderboard not implemented")
+}
+func (UnimplementedFantasyServer) WatchLeaderboard(*WatchLeaderboardRequest, grpc.ServerStreamingServer[Leaderboard]) error {
+	return status.Error(codes.Unimplemented, "method WatchLeaderboard not implemented")
+}
+func (UnimplementedFantasyServer) CreateTeam(context.Context, *NewTeamRequest) (*TeamInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateTeam not implemented")
+}
+func (UnimplementedFantasyServer) ListTeams(context.Context, *ListTeamsRequest) (*ListTeamsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTeams not implemented")
+}
+func (UnimplementedFantasyServer) GetTeam(context.Context, *GetTeamRequest) (*TeamInfo, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTeam not implemented")
+}
+func (UnimplementedFantasyServer) EnterContest(context.Context, *EnterContestRequest) (*EnterContestResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EnterContest not implemented")
+}
+func (UnimplementedFantasyServer) ListEntries(context.Context, *ListEntriesRequest) (*ListEntriesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListEntries not implemented")
+}
+func (UnimplementedFantasyServer) UpdateLineup(context.Context, *UpdateLineupRequest) (*UpdateLineupResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateLineup not implemented")
+}
+func (UnimplementedFantasyServer) LeaveContest(context.Context, *LeaveContestRequest) (*LeaveContestResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method LeaveContest not implemented")
+}
+func (UnimplementedFantasyServer) mustEmbedUnimplementedFantasyServer() {}
+func (UnimplementedFantasyServer) testEmbeddedByValue()                 {}
+
+// UnsafeFantasyServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FantasyServer will
+// result in compilation errors.
+type UnsafeFantasyServer interface {
+	mustEmbedUnimplementedFantasyServer()
+}
+
+func RegisterFantasyServer(s grpc.ServiceRegistrar, srv FantasyServer) {
+	// If the following call panics, it indicates UnimplementedFantasyServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Fantasy_ServiceDesc, srv)
+}
+
+func _Fantasy_ListContests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListContestsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).ListContests(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_ListContests_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).ListContests(ctx, req.(*ListContestsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fantasy_GetContest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetContestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).GetContest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_GetContest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).GetContest(ctx, req.(*GetContestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fantasy_CreateContest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewContestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).CreateContest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_CreateContest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).CreateContest(ctx, req.(*NewContestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fantasy_GetLeaderboard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLeaderboardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).GetLeaderboard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_GetLeaderboard_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).GetLeaderboard(ctx, req.(*GetLeaderboardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fantasy_WatchLeaderboard_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchLeaderboardRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FantasyServer).WatchLeaderboard(m, &grpc.GenericServerStream[WatchLeaderboardRequest, Leaderboard]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Fantasy_WatchLeaderboardServer = grpc.ServerStreamingServer[Leaderboard]
+
+func _Fantasy_CreateTeam_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewTeamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).CreateTeam(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_CreateTeam_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).CreateTeam(ctx, req.(*NewTeamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fantasy_ListTeams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTeamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).ListTeams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_ListTeams_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).ListTeams(ctx, req.(*ListTeamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fantasy_GetTeam_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTeamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).GetTeam(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_GetTeam_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).GetTeam(ctx, req.(*GetTeamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fantasy_EnterContest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnterContestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).EnterContest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_EnterContest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).EnterContest(ctx, req.(*EnterContestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fantasy_ListEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).ListEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_ListEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).ListEntries(ctx, req.(*ListEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fantasy_UpdateLineup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLineupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).UpdateLineup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_UpdateLineup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).UpdateLineup(ctx, req.(*UpdateLineupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Fantasy_LeaveContest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaveContestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FantasyServer).LeaveContest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Fantasy_LeaveContest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FantasyServer).LeaveContest(ctx, req.(*LeaveContestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Fantasy_ServiceDesc is the grpc.ServiceDesc for Fantasy service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Fantasy_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fantasy.v1.Fantasy",
+	HandlerType: (*FantasyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListContests",
+			Handler:    _Fantasy_ListContests_Handler,
+		},
+		{
+			MethodName: "GetContest",
+			Handler:    _Fantasy_GetContest_Handler,
+		},
+		{
+			MethodName: "CreateContest",
+			Handler:    _Fantasy_CreateContest_Handler,
+		},
+		{
+			MethodName: "GetLeaderboard",
+			Handler:    _Fantasy_GetLeaderboard_Handler,
+		},
+		{
+			MethodName: "CreateTeam",
+			Handler:    _Fantasy_CreateTeam_Handler,
+		},
+		{
+			MethodName: "ListTeams",
+			Handler:    _Fantasy_ListTeams_Handler,
+		},
+		{
+			MethodName: "GetTeam",
+			Handler:    _Fantasy_GetTeam_Handler,
+		},
+		{
+			MethodName: "EnterContest",
+			Handler:    _Fantasy_EnterContest_Handler,
+		},
+		{
+			MethodName: "ListEntries",
+			Handler:    _Fantasy_ListEntries_Handler,
+		},
+		{
+			MethodName: "UpdateLineup",
+			Handler:    _Fantasy_UpdateLineup_Handler,
+		},
+		{
+			MethodName: "LeaveContest",
+			Handler:    _Fantasy_LeaveContest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchLeaderboard",
+			Handler:       _Fantasy_WatchLeaderboard_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "fantasy.proto",
+}
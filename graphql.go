@@ -0,0 +1,786 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+const (
+	// maxQueryDepth is how deeply fields may be nested in a GraphQL query
+	maxQueryDepth = 7
+	// maxQueryComplexity bounds the estimated number of objects a query resolves. Every
+	// field costs 1 and the fields below a list count once per item it can return.
+	maxQueryComplexity = 1000
+	// maxListSize is the most items a list field returns. A list without a first
+	// argument returns up to this many and is costed as if it did.
+	maxListSize = 100
+	// maxQueryLength bounds the size of a query, persisted or not
+	maxQueryLength = 10000
+)
+
+// GraphQLRequest is the body of a GraphQL request
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    GraphQLExtensions      `json:"extensions"`
+}
+
+// GraphQLExtensions carries the protocol extensions of a GraphQL request
+type GraphQLExtensions struct {
+	PersistedQuery *PersistedQuery `json:"persistedQuery,omitempty"`
+}
+
+// PersistedQuery refers to a query by its SHA-256 hash, following Apollo's automatic
+// persisted queries: a client first sends only the hash and sends the full query once
+// when the server does not know it yet
+type PersistedQuery struct {
+	Version    int    `json:"version"`
+	SHA256Hash string `json:"sha256Hash"`
+}
+
+// graphQLError is an error of a GraphQL field. Its code and the request ID are reported
+// in the error's extensions.
+type graphQLError struct {
+	*APIError
+	requestID string
+}
+
+func (e *graphQLError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.Code, "request_id": e.requestID}
+	if len(e.Fields) > 0 {
+		ext["fields"] = e.Fields
+	}
+	return ext
+}
+
+// graphUser is the source of a User object
+type graphUser struct {
+	ID int
+}
+
+// graphRequest is the per-request state resolvers read from the context
+type graphRequest struct {
+	db        *sql.DB
+	userID    int
+	requestID string
+}
+
+type graphRequestKey struct{}
+
+func graphRequestFrom(ctx context.Context) *graphRequest {
+	return ctx.Value(graphRequestKey{}).(*graphRequest)
+}
+
+func setupGraphQLRoutes(r *gin.Engine, db *sql.DB) {
+	schema, err := newGraphQLSchema()
+	if err != nil {
+		panic(err)
+	}
+
+	// Route to run a GraphQL query sent as JSON
+	r.POST("/graphql", func(c *gin.Context) {
+		var req GraphQLRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		runGraphQL(c, db, schema, &req)
+	})
+
+	// Route to run a GraphQL query sent in the URL, which lets persisted queries be cached
+	r.GET("/graphql", func(c *gin.Context) {
+		req := GraphQLRequest{
+			Query:         c.Query("query"),
+			OperationName: c.Query("operationName"),
+		}
+		if v := c.Query("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				c.Error(invalidParam("variables", "Invalid variables"))
+				return
+			}
+		}
+		if v := c.Query("extensions"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Extensions); err != nil {
+				c.Error(invalidParam("extensions", "Invalid extensions"))
+				return
+			}
+		}
+
+		runGraphQL(c, db, schema, &req)
+	})
+}
+
+// runGraphQL resolves the persisted query, checks the query against the depth and
+// complexity limits and executes it. Failures before execution are reported in the
+// GraphQL errors format like execution errors, so clients handle a single format.
+func runGraphQL(c *gin.Context, db *sql.DB, schema graphql.Schema, req *GraphQLRequest) {
+	requestID := c.GetString(requestIDKey)
+
+	persist, err := resolvePersistedQuery(db, req)
+	if err != nil {
+		writeGraphQLError(c, requestID, err)
+		return
+	}
+
+	if len(req.Query) > maxQueryLength {
+		writeGraphQLError(c, requestID, validationError("query_too_long", fmt.Sprintf("The query is longer than %d characters", maxQueryLength)))
+		return
+	}
+
+	// Syntax errors are left to graphql.Do, which reports them with their location
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err == nil {
+		depth, complexity := queryCost(schema, doc, req.OperationName, req.Variables)
+		if depth > maxQueryDepth {
+			writeGraphQLError(c, requestID, validationError("query_too_deep", fmt.Sprintf("The query is nested %d levels deep; the limit is %d", depth, maxQueryDepth)))
+			return
+		}
+		if complexity > maxQueryComplexity {
+			writeGraphQLError(c, requestID, validationError("query_too_complex", fmt.Sprintf("The query has a complexity of %d; the limit is %d", complexity, maxQueryComplexity)))
+			return
+		}
+
+		if persist {
+			if err := savePersistedQuery(db, req.Extensions.PersistedQuery.SHA256Hash, req.Query); err != nil {
+				log.Printf("graphql: failed to persist query %s: %v", req.Extensions.PersistedQuery.SHA256Hash, err)
+			}
+		}
+	}
+
+	// Anonymous requests can read the public data; me and myEntries need a user
+	userID, _ := currentUserID(c)
+
+	ctx := context.WithValue(c.Request.Context(), graphRequestKey{}, &graphRequest{db: db, userID: userID, requestID: requestID})
+	ctx = context.WithValue(ctx, graphLoadersKey{}, newGraphLoaders(db))
+
+	result := graphql.Do(graphql.Params{
+		Schema:         schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        ctx,
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+// writeGraphQLError reports a request that failed before execution
+func writeGraphQLError(c *gin.Context, requestID string, err error) {
+	apiErr := apiError(err, "Failed to run the query")
+	if apiErr.Kind == kindInternal || apiErr.Kind == kindUnavailable {
+		log.Printf("%s %s [%s]: %s: %v", c.Request.Method, c.Request.URL.Path, requestID, apiErr.Message, apiErr.Err)
+	}
+
+	gqlErr := &graphQLError{APIError: apiErr, requestID: requestID}
+	c.JSON(http.StatusOK, gin.H{"errors": []gin.H{{"message": apiErr.Message, "extensions": gqlErr.Extensions()}}})
+}
+
+// errPersistedQueryNotFound asks the client to send the full query. The code and message
+// are the ones Apollo clients look for.
+var errPersistedQueryNotFound = notFoundError("PERSISTED_QUERY_NOT_FOUND", "PersistedQueryNotFound")
+
+// resolvePersistedQuery fills in the query of a request that only sent its hash. It
+// returns true when the request carries a query that is not stored yet and should be
+// persisted once it passes the limits.
+func resolvePersistedQuery(db *sql.DB, req *GraphQLRequest) (bool, error) {
+	pq := req.Extensions.PersistedQuery
+	if pq == nil {
+		if req.Query == "" {
+			return false, invalidField("query_required", "query", "A query is required")
+		}
+		return false, nil
+	}
+
+	if pq.Version != 1 {
+		return false, validationError("PERSISTED_QUERY_NOT_SUPPORTED", "Only version 1 of persisted queries is supported")
+	}
+	pq.SHA256Hash = strings.ToLower(pq.SHA256Hash)
+
+	if req.Query == "" {
+		err := db.QueryRow("SELECT query FROM persisted_query WHERE hash = ?", pq.SHA256Hash).Scan(&req.Query)
+		if err == sql.ErrNoRows {
+			return false, errPersistedQueryNotFound
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if _, err := db.Exec("UPDATE persisted_query SET last_used_at = NOW() WHERE hash = ?", pq.SHA256Hash); err != nil {
+			log.Printf("graphql: failed to touch persisted query %s: %v", pq.SHA256Hash, err)
+		}
+		return false, nil
+	}
+
+	sum := sha256.Sum256([]byte(req.Query))
+	if hex.EncodeToString(sum[:]) != pq.SHA256Hash {
+		return false, validationError("PERSISTED_QUERY_HASH_MISMATCH", "provided sha does not match query")
+	}
+
+	return true, nil
+}
+
+// savePersistedQuery stores a query under its hash; storing a known query again is a no-op
+func savePersistedQuery(db *sql.DB, hash, query string) error {
+	_, err := db.Exec("INSERT IGNORE INTO persisted_query (hash, query, created_at, last_used_at) VALUES (?, ?, NOW(), NOW())", hash, query)
+	return err
+}
+
+// queryCost returns the depth and complexity of the operation that will run. Fields are
+// looked up in the schema to tell lists apart; introspection fields are free so tools
+// can load the schema.
+func queryCost(schema graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}) (int, int) {
+	fragments := map[string]*ast.FragmentDefinition{}
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operation == nil || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		return 0, 0
+	}
+
+	var walk func(set *ast.SelectionSet, parent graphql.Type, visited map[string]bool) (int, int)
+	walk = func(set *ast.SelectionSet, parent graphql.Type, visited map[string]bool) (int, int) {
+		if set == nil {
+			return 0, 0
+		}
+
+		var depth, complexity int
+		add := func(d, c int) {
+			if d > depth {
+				depth = d
+			}
+			complexity += c
+		}
+
+		for _, sel := range set.Selections {
+			switch sel := sel.(type) {
+			case *ast.Field:
+				if strings.HasPrefix(sel.Name.Value, "__") {
+					continue
+				}
+
+				fieldType, isList := graphFieldType(parent, sel.Name.Value)
+				d, c := walk(sel.SelectionSet, fieldType, visited)
+				if isList {
+					c *= listSize(sel, variables)
+				}
+				add(d+1, c+1)
+			case *ast.InlineFragment:
+				typ := parent
+				if sel.TypeCondition != nil {
+					typ = schema.Type(sel.TypeCondition.Name.Value)
+				}
+				add(walk(sel.SelectionSet, typ, visited))
+			case *ast.FragmentSpread:
+				name := sel.Name.Value
+				fragment, ok := fragments[name]
+				if !ok || visited[name] {
+					continue
+				}
+				visited[name] = true
+				add(walk(fragment.SelectionSet, schema.Type(fragment.TypeCondition.Name.Value), visited))
+				delete(visited, name)
+			}
+		}
+
+		return depth, complexity
+	}
+
+	return walk(operation.SelectionSet, schema.QueryType(), map[string]bool{})
+}
+
+// graphFieldType returns the named type of a field of parent and whether it is a list
+func graphFieldType(parent graphql.Type, name string) (graphql.Type, bool) {
+	obj, ok := parent.(*graphql.Object)
+	if !ok {
+		return nil, false
+	}
+	field, ok := obj.Fields()[name]
+	if !ok {
+		return nil, false
+	}
+
+	var typ graphql.Type = field.Type
+	isList := false
+	for {
+		switch t := typ.(type) {
+		case *graphql.NonNull:
+			typ = t.OfType
+			continue
+		case *graphql.List:
+			isList = true
+			typ = t.OfType
+			continue
+		}
+		return typ, isList
+	}
+}
+
+// listSize returns the most items a list field can return: its first argument when set,
+// capped at maxListSize like the resolvers do
+func listSize(field *ast.Field, variables map[string]interface{}) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 && n < maxListSize {
+				return n
+			}
+		case *ast.Variable:
+			if n, ok := variables[v.Name.Value].(float64); ok && n > 0 && n < maxListSize {
+				return int(n)
+			}
+		}
+	}
+	return maxListSize
+}
+
+// resolveError reports a failed field. Internal errors are logged with their cause.
+func resolveError(ctx context.Context, err error, message string) error {
+	req := graphRequestFrom(ctx)
+	apiErr := apiError(err, message)
+	if apiErr.Kind == kindInternal || apiErr.Kind == kindUnavailable {
+		log.Printf("graphql [%s]: %s: %v", req.requestID, apiErr.Message, apiErr.Err)
+	}
+	return &graphQLError{APIError: apiErr, requestID: req.requestID}
+}
+
+// idArg parses an ID argument
+func idArg(p graphql.ResolveParams, name string) (int, error) {
+	id, err := strconv.Atoi(fmt.Sprint(p.Args[name]))
+	if err != nil {
+		return 0, resolveError(p.Context, invalidParam(name, "Invalid "+name), "")
+	}
+	return id, nil
+}
+
+// firstArgument is the first argument every list of objects takes
+var firstArgument = &graphql.ArgumentConfig{Type: graphql.Int, Description: fmt.Sprintf("Only return the first items, at most %d", maxListSize)}
+
+// listLimit returns how many items a list field may return: its first argument when
+// set, capped at maxListSize
+func listLimit(p graphql.ResolveParams) int {
+	n, _ := p.Args["first"].(int)
+	if n <= 0 || n > maxListSize {
+		return maxListSize
+	}
+	return n
+}
+
+// loadContest resolves a contest by ID through the request's loader; a missing contest is null
+func loadContest(p graphql.ResolveParams, contestID int) (interface{}, error) {
+	thunk := loadersFrom(p.Context).contests.load(contestID)
+	return func() (interface{}, error) {
+		contest, err := thunk()
+		if err != nil {
+			return nil, resolveError(p.Context, err, "Failed to fetch contest")
+		}
+		if contest == nil {
+			return nil, nil
+		}
+		return contest, nil
+	}, nil
+}
+
+// loadEntry resolves an entry by ID through the request's loader; a missing entry is null
+func loadEntry(p graphql.ResolveParams, entryID int) (interface{}, error) {
+	thunk := loadersFrom(p.Context).entries.load(entryID)
+	return func() (interface{}, error) {
+		entry, err := thunk()
+		if err != nil {
+			return nil, resolveError(p.Context, err, "Failed to fetch entry")
+		}
+		if entry == nil {
+			return nil, nil
+		}
+		return entry, nil
+	}, nil
+}
+
+// loadUserEntriesField resolves the entries of a user through the request's loader, keeping
+// those accepted by keep
+func loadUserEntriesField(p graphql.ResolveParams, userID int, keep func(*Entry) bool) (interface{}, error) {
+	thunk := loadersFrom(p.Context).userEntries.load(userID)
+	return func() (interface{}, error) {
+		entries, err := thunk()
+		if err != nil {
+			return nil, resolveError(p.Context, err, "Failed to fetch entries")
+		}
+
+		res := []*Entry{}
+		for _, entry := range entries {
+			if len(res) == listLimit(p) {
+				break
+			}
+			if keep == nil || keep(entry) {
+				res = append(res, entry)
+			}
+		}
+		return res, nil
+	}, nil
+}
+
+// contestField resolves a field computed from a contest
+func contestField(typ graphql.Output, get func(contest *Contest) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(*Contest)), nil
+		},
+	}
+}
+
+// contestDerived resolves a field of the contest's API shape, such as how full it is
+func contestDerived(typ graphql.Output, get func(res ContestResponse) interface{}) *graphql.Field {
+	return contestField(typ, func(contest *Contest) interface{} {
+		return get(newContestResponse(contest, time.Now()))
+	})
+}
+
+func newGraphQLSchema() (graphql.Schema, error) {
+	var contestType, entryType, userType *graphql.Object
+
+	teamType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Team",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*Team).ID, nil }},
+			"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*Team).Name, nil }},
+			"displayName": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*Team).DisplayName, nil }},
+			"createdAt":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*Team).CreatedAt, nil }},
+		},
+	})
+
+	lineupRoleType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "LineupRole",
+		Fields: graphql.Fields{
+			"role":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"playerId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	leaderboardEntryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "LeaderboardEntry",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"rank":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(LeaderboardEntry).Rank, nil }},
+				"points": &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(LeaderboardEntry).Points, nil }},
+				"entry": &graphql.Field{
+					Type: entryType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadEntry(p, p.Source.(LeaderboardEntry).EntryID)
+					},
+				},
+				"user": &graphql.Field{
+					Type: graphql.NewNonNull(userType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return &graphUser{ID: p.Source.(LeaderboardEntry).UserID}, nil
+					},
+				},
+			}
+		}),
+	})
+
+	// leaderboardField ranks the entries of the contest returned by contestID
+	leaderboardField := func(args graphql.FieldConfigArgument, contestID func(p graphql.ResolveParams) (int, error)) *graphql.Field {
+		args["first"] = firstArgument
+		return &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(leaderboardEntryType))),
+			Args: args,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				id, err := contestID(p)
+				if err != nil {
+					return nil, err
+				}
+
+				req := graphRequestFrom(p.Context)
+				leaderboard, err := getContestLeaderboard(req.db, id)
+				if err != nil {
+					return nil, resolveError(p.Context, err, "Failed to fetch the leaderboard")
+				}
+				if n := listLimit(p); n < len(leaderboard) {
+					leaderboard = leaderboard[:n]
+				}
+				return leaderboard, nil
+			},
+		}
+	}
+
+	contestType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Contest",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":                contestField(graphql.NewNonNull(graphql.ID), func(c *Contest) interface{} { return c.ID }),
+				"name":              contestField(graphql.NewNonNull(graphql.String), func(c *Contest) interface{} { return c.Name }),
+				"prize":             contestField(graphql.NewNonNull(graphql.Float), func(c *Contest) interface{} { return c.Prize }),
+				"prizeStructure":    contestField(graphql.NewNonNull(graphql.String), func(c *Contest) interface{} { return c.PrizeStructure }),
+				"entryFee":          contestField(graphql.NewNonNull(graphql.Float), func(c *Contest) interface{} { return c.EntryFee }),
+				"totalSlots":        contestField(graphql.NewNonNull(graphql.Int), func(c *Contest) interface{} { return c.TotalSlots }),
+				"remainingSlots":    contestField(graphql.NewNonNull(graphql.Int), func(c *Contest) interface{} { return c.RemainingSlots }),
+				"filledSlots":       contestDerived(graphql.NewNonNull(graphql.Int), func(res ContestResponse) interface{} { return res.FilledSlots }),
+				"fillPercent":       contestDerived(graphql.NewNonNull(graphql.Float), func(res ContestResponse) interface{} { return res.FillPercent }),
+				"maxEntriesPerUser": contestField(graphql.NewNonNull(graphql.Int), func(c *Contest) interface{} { return c.MaxEntriesPerUser }),
+				"rosterSize":        contestField(graphql.NewNonNull(graphql.Int), func(c *Contest) interface{} { return c.RosterSize }),
+				"status":            contestField(graphql.NewNonNull(graphql.String), func(c *Contest) interface{} { return c.Status }),
+				"isPrivate":         contestField(graphql.NewNonNull(graphql.Boolean), func(c *Contest) interface{} { return c.IsPrivate }),
+				"isLocked":          contestField(graphql.NewNonNull(graphql.Boolean), func(c *Contest) interface{} { return c.IsLocked }),
+				"isGuaranteed":      contestField(graphql.NewNonNull(graphql.Boolean), func(c *Contest) interface{} { return c.IsGuaranteed }),
+				"startDate":         contestField(graphql.NewNonNull(graphql.DateTime), func(c *Contest) interface{} { return c.StartDate }),
+				"endDate":           contestField(graphql.NewNonNull(graphql.DateTime), func(c *Contest) interface{} { return c.EndDate }),
+				"locksAt":           contestField(graphql.NewNonNull(graphql.DateTime), func(c *Contest) interface{} { return c.StartDate }),
+				"secondsToLock":     contestDerived(graphql.NewNonNull(graphql.Int), func(res ContestResponse) interface{} { return int(res.SecondsToLock) }),
+				"createdAt":         contestField(graphql.NewNonNull(graphql.DateTime), func(c *Contest) interface{} { return c.CreatedAt }),
+				"entrantCount": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Number of distinct users entered in the contest",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						thunk := loadersFrom(p.Context).entrantCounts.load(p.Source.(*Contest).ID)
+						return func() (interface{}, error) {
+							count, err := thunk()
+							if err != nil {
+								return nil, resolveError(p.Context, err, "Failed to count entrants")
+							}
+							return count, nil
+						}, nil
+					},
+				},
+				"myEntries": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(entryType))),
+					Description: "The current user's entries in the contest; empty for anonymous requests",
+					Args:        graphql.FieldConfigArgument{"first": firstArgument},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						userID := graphRequestFrom(p.Context).userID
+						if userID == 0 {
+							return []*Entry{}, nil
+						}
+						contestID := p.Source.(*Contest).ID
+						return loadUserEntriesField(p, userID, func(entry *Entry) bool { return entry.ContestID == contestID })
+					},
+				},
+				"leaderboard": leaderboardField(graphql.FieldConfigArgument{}, func(p graphql.ResolveParams) (int, error) {
+					return p.Source.(*Contest).ID, nil
+				}),
+			}
+		}),
+	})
+
+	entryType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Entry",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			// lineup hides the players of other users' entries until their contest starts
+			lineup := func(p graphql.ResolveParams, get func(*entryLineup) interface{}) (interface{}, error) {
+				entry := p.Source.(*Entry)
+				if entry.UserID != graphRequestFrom(p.Context).userID && entry.Status == entryStatusUpcoming {
+					return nil, nil
+				}
+
+				thunk := loadersFrom(p.Context).lineups.load(entry.ID)
+				return func() (interface{}, error) {
+					lineup, err := thunk()
+					if err != nil {
+						return nil, resolveError(p.Context, err, "Failed to fetch lineup")
+					}
+					if lineup == nil {
+						lineup = &entryLineup{Players: []int{}, Roles: LineupRoles{}}
+					}
+					return get(lineup), nil
+				}, nil
+			}
+
+			return graphql.Fields{
+				"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*Entry).ID, nil }},
+				"status":    &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*Entry).Status, nil }},
+				"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*Entry).CreatedAt, nil }},
+				"contest": &graphql.Field{
+					Type: contestType,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return loadContest(p, p.Source.(*Entry).ContestID)
+					},
+				},
+				"user": &graphql.Field{
+					Type: graphql.NewNonNull(userType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return &graphUser{ID: p.Source.(*Entry).UserID}, nil
+					},
+				},
+				"lineup": &graphql.Field{
+					Type:        graphql.NewList(graphql.NewNonNull(graphql.Int)),
+					Description: "Player IDs of the lineup; null for other users' entries until the contest starts",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return lineup(p, func(l *entryLineup) interface{} { return l.Players })
+					},
+				},
+				"roles": &graphql.Field{
+					Type: graphql.NewList(graphql.NewNonNull(lineupRoleType)),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return lineup(p, func(l *entryLineup) interface{} {
+							roles := []map[string]interface{}{}
+							for role, playerID := range l.Roles {
+								roles = append(roles, map[string]interface{}{"role": role, "playerId": playerID})
+							}
+							return roles
+						})
+					},
+				},
+				"points": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.Float),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						thunk := loadersFrom(p.Context).points.load(p.Source.(*Entry).ID)
+						return func() (interface{}, error) {
+							points, err := thunk()
+							if err != nil {
+								return nil, resolveError(p.Context, err, "Failed to compute points")
+							}
+							return points, nil
+						}, nil
+					},
+				},
+			}
+		}),
+	})
+
+	userType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*graphUser).ID, nil }},
+				"entries": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(entryType))),
+					Description: "The user's entries, newest first; only readable by the user",
+					Args:        graphql.FieldConfigArgument{"first": firstArgument},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						userID := p.Source.(*graphUser).ID
+						if userID != graphRequestFrom(p.Context).userID {
+							return nil, resolveError(p.Context, forbiddenError("forbidden", "Only the user can list their entries"), "")
+						}
+						return loadUserEntriesField(p, userID, nil)
+					},
+				},
+			}
+		}),
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"contests": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(contestType))),
+				Description: "The lobby: the public contests open for entries, one per series",
+				Args:        graphql.FieldConfigArgument{"first": firstArgument},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					rows, err := getLobbyContests(graphRequestFrom(p.Context).db)
+					if err != nil {
+						return nil, resolveError(p.Context, err, "Failed to fetch contests")
+					}
+					if n := listLimit(p); n < len(rows) {
+						rows = rows[:n]
+					}
+
+					contests := make([]*Contest, len(rows))
+					for i, row := range rows {
+						contests[i] = row.Contest
+					}
+					return contests, nil
+				},
+			},
+			"contest": &graphql.Field{
+				Type: contestType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					return loadContest(p, id)
+				},
+			},
+			"leaderboard": leaderboardField(graphql.FieldConfigArgument{
+				"contestId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+			}, func(p graphql.ResolveParams) (int, error) {
+				return idArg(p, "contestId")
+			}),
+			"teams": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(teamType))),
+				Description: "The teams that are not part of a league",
+				Args:        graphql.FieldConfigArgument{"first": firstArgument},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					teams, err := getTeams(graphRequestFrom(p.Context).db)
+					if err != nil {
+						return nil, resolveError(p.Context, err, "Failed to fetch teams")
+					}
+					if n := listLimit(p); n < len(teams) {
+						teams = teams[:n]
+					}
+
+					res := make([]*Team, len(teams))
+					for i := range teams {
+						res[i] = &teams[i]
+					}
+					return res, nil
+				},
+			},
+			"team": &graphql.Field{
+				Type: teamType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+
+					team, err := getTeam(graphRequestFrom(p.Context).db, id)
+					if errors.Is(err, errTeamNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, resolveError(p.Context, err, "Failed to fetch team")
+					}
+					return team, nil
+				},
+			},
+			"me": &graphql.Field{
+				Type:        userType,
+				Description: "The current user; null for anonymous requests",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					userID := graphRequestFrom(p.Context).userID
+					if userID == 0 {
+						return nil, nil
+					}
+					return &graphUser{ID: userID}, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
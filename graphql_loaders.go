@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// batchLoader collects the keys requested while a GraphQL query resolves one level and
+// fetches them with a single query when the first value is needed. Values are cached for
+// the rest of the request, so an object referenced twice is only read once.
+type batchLoader[V any] struct {
+	fetch func(keys []int) (map[int]V, error)
+
+	mu      sync.Mutex
+	pending []int
+	results map[int]*loaderResult[V]
+}
+
+type loaderResult[V any] struct {
+	value V
+	err   error
+	done  bool
+}
+
+func newBatchLoader[V any](fetch func(keys []int) (map[int]V, error)) *batchLoader[V] {
+	return &batchLoader[V]{fetch: fetch, results: make(map[int]*loaderResult[V])}
+}
+
+// load queues key and returns a thunk that yields its value. graphql-go resolves the
+// thunks of a level only after every field of that level was visited, so all keys of
+// the level end up in one batch. A key the batch did not return yields the zero value.
+func (l *batchLoader[V]) load(key int) func() (V, error) {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok {
+		l.results[key] = &loaderResult[V]{}
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		res := l.results[key]
+		if !res.done {
+			l.dispatch()
+		}
+		return res.value, res.err
+	}
+}
+
+// dispatch fetches every pending key; l.mu must be held
+func (l *batchLoader[V]) dispatch() {
+	keys := l.pending
+	l.pending = nil
+
+	values, err := l.fetch(keys)
+	for _, key := range keys {
+		res := l.results[key]
+		res.done = true
+		if err != nil {
+			res.err = err
+			continue
+		}
+		res.value = values[key]
+	}
+}
+
+// entryLineup is the players and roles of one entry
+type entryLineup struct {
+	Players []int
+	Roles   LineupRoles
+}
+
+// graphLoaders holds the batch loaders of one GraphQL request
+type graphLoaders struct {
+	contests      *batchLoader[*Contest]
+	entrantCounts *batchLoader[int]
+	entries       *batchLoader[*Entry]
+	userEntries   *batchLoader[[]*Entry]
+	lineups       *batchLoader[*entryLineup]
+	points        *batchLoader[float64]
+}
+
+type graphLoadersKey struct{}
+
+func newGraphLoaders(db *sql.DB) *graphLoaders {
+	return &graphLoaders{
+		contests:      newBatchLoader(func(ids []int) (map[int]*Contest, error) { return loadContests(db, ids) }),
+		entrantCounts: newBatchLoader(func(ids []int) (map[int]int, error) { return loadEntrantCounts(db, ids) }),
+		entries:       newBatchLoader(func(ids []int) (map[int]*Entry, error) { return loadEntries(db, ids) }),
+		userEntries:   newBatchLoader(func(ids []int) (map[int][]*Entry, error) { return loadUserEntries(db, ids) }),
+		lineups:       newBatchLoader(func(ids []int) (map[int]*entryLineup, error) { return loadLineups(db, ids) }),
+		points:        newBatchLoader(func(ids []int) (map[int]float64, error) { return loadEntryPoints(db, ids) }),
+	}
+}
+
+// loadersFrom returns the loaders of the request ctx belongs to
+func loadersFrom(ctx context.Context) *graphLoaders {
+	return ctx.Value(graphLoadersKey{}).(*graphLoaders)
+}
+
+// intArgs converts IDs into query arguments for an IN clause
+func intArgs(ids []int) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+func loadContests(db *sql.DB, ids []int) (map[int]*Contest, error) {
+	rows, err := db.Query("SELECT "+contestColumns+" FROM contest WHERE id IN ("+placeholders(len(ids))+")", intArgs(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contests := make(map[int]*Contest, len(ids))
+	for rows.Next() {
+		contest, err := scanContest(rows)
+		if err != nil {
+			return nil, err
+		}
+		contests[contest.ID] = contest
+	}
+
+	return contests, rows.Err()
+}
+
+// loadEntrantCounts returns the number of distinct users entered in each contest
+func loadEntrantCounts(db *sql.DB, ids []int) (map[int]int, error) {
+	rows, err := db.Query("SELECT contest_id, COUNT(DISTINCT user_id) FROM user_contest WHERE contest_id IN ("+placeholders(len(ids))+") GROUP BY contest_id", intArgs(ids)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int, len(ids))
+	for rows.Next() {
+		var contestID, count int
+		if err := rows.Scan(&contestID, &count); err != nil {
+			return nil, err
+		}
+		counts[contestID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func loadEntries(db *sql.DB, ids []int) (map[int]*Entry, error) {
+	rows, err := db.Query(
+		"SELECT "+entryColumns+" FROM user_contest uc JOIN contest c ON c.id = uc.contest_id WHERE uc.id IN ("+placeholders(len(ids))+")",
+		intArgs(ids)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make(map[int]*Entry, len(ids))
+	now := time.Now()
+	for rows.Next() {
+		entry, err := scanEntry(rows, now)
+		if err != nil {
+			return nil, err
+		}
+		entries[entry.ID] = entry
+	}
+
+	return entries, rows.Err()
+}
+
+// loadUserEntries returns the entries of each user, newest first
+func loadUserEntries(db *sql.DB, userIDs []int) (map[int][]*Entry, error) {
+	rows, err := db.Query(
+		"SELECT "+entryColumns+" FROM user_contest uc JOIN contest c ON c.id = uc.contest_id WHERE uc.user_id IN ("+placeholders(len(userIDs))+") ORDER BY uc.created_at DESC, uc.id DESC",
+		intArgs(userIDs)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make(map[int][]*Entry, len(userIDs))
+	now := time.Now()
+	for rows.Next() {
+		entry, err := scanEntry(rows, now)
+		if err != nil {
+			return nil, err
+		}
+		entries[entry.UserID] = append(entries[entry.UserID], entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func loadLineups(db *sql.DB, entryIDs []int) (map[int]*entryLineup, error) {
+	rows, err := db.Query(
+		"SELECT entry_id, player_id, IFNULL(role, '') FROM entry_lineup WHERE entry_id IN ("+placeholders(len(entryIDs))+") ORDER BY entry_id, player_id",
+		intArgs(entryIDs)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lineups := make(map[int]*entryLineup, len(entryIDs))
+	for _, id := range entryIDs {
+		lineups[id] = &entryLineup{Players: []int{}, Roles: LineupRoles{}}
+	}
+	for rows.Next() {
+		var entryID, playerID int
+		var role string
+		if err := rows.Scan(&entryID, &playerID, &role); err != nil {
+			return nil, err
+		}
+		lineup := lineups[entryID]
+		lineup.Players = append(lineup.Players, playerID)
+		if role != "" {
+			lineup.Roles[role] = playerID
+		}
+	}
+
+	return lineups, rows.Err()
+}
+
+// loadEntryPoints returns the fantasy points of each entry with role multipliers applied,
+// like computeEntryPoints
+func loadEntryPoints(db *sql.DB, entryIDs []int) (map[int]float64, error) {
+	rows, err := db.Query(
+		"SELECT el.entry_id, IFNULL(el.role, ''), IFNULL(ps.points, 0) FROM entry_lineup el JOIN player p ON p.id = el.player_id LEFT JOIN player_score ps ON ps.player_id = el.player_id AND ps.game_id = p.game_id WHERE el.entry_id IN ("+placeholders(len(entryIDs))+")",
+		intArgs(entryIDs)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := make(map[int]float64, len(entryIDs))
+	for rows.Next() {
+		var entryID int
+		var role string
+		var score float64
+		if err := rows.Scan(&entryID, &role, &score); err != nil {
+			return nil, err
+		}
+		points[entryID] += score * roleMultiplier(role)
+	}
+
+	return points, rows.Err()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+func TestQueryCostCountsListsAtTheirLimit(t *testing.T) {
+	schema, err := newGraphQLSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query      string
+		variables  map[string]interface{}
+		complexity int
+	}{
+		// A list without first can return maxListSize items
+		{`{ contests { id name } }`, nil, maxListSize*2 + 1},
+		{`{ contests(first: 5) { id name } }`, nil, 5*2 + 1},
+		{`query ($n: Int) { contests(first: $n) { id } }`, map[string]interface{}{"n": float64(3)}, 3*1 + 1},
+		// first is capped like the resolvers cap it
+		{`{ contests(first: 100000) { id } }`, nil, maxListSize*1 + 1},
+		{`{ me { entries { id } } }`, nil, maxListSize*1 + 1 + 1},
+		{`{ contests(first: 2) { myEntries(first: 3) { id } leaderboard(first: 4) { rank } } }`, nil, 2*((3*1+1)+(4*1+1)) + 1},
+	}
+
+	for _, tt := range tests {
+		doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if _, complexity := queryCost(schema, doc, "", tt.variables); complexity != tt.complexity {
+			t.Errorf("%s: complexity %d, want %d", tt.query, complexity, tt.complexity)
+		}
+	}
+}
+
+func TestUnboundedNestedListsAreTooComplex(t *testing.T) {
+	schema, err := newGraphQLSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: `{ contests { leaderboard { rank } } }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, complexity := queryCost(schema, doc, "", nil); complexity <= maxQueryComplexity {
+		t.Errorf("complexity %d is within the limit of %d", complexity, maxQueryComplexity)
+	}
+}
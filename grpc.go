@@ -0,0 +1,543 @@
+package main
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative fantasy.proto
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// leaderboardWatchInterval is how often a watched leaderboard is recomputed; an update
+// is only sent when it changed
+const leaderboardWatchInterval = 5 * time.Second
+
+// errorDomain is the domain of the google.rpc.ErrorInfo attached to failed calls
+const errorDomain = "fantasy"
+
+// grpcIdempotencyKey is the metadata key carrying the Idempotency-Key of a call
+var grpcIdempotencyKey = strings.ToLower(idempotencyHeader)
+
+// grpcIdempotentMethods are the calls that honor an idempotency key, with a constructor
+// of their response to decode replays into
+var grpcIdempotentMethods = map[string]func() proto.Message{
+	Fantasy_CreateContest_FullMethodName: func() proto.Message { return &ContestInfo{} },
+	Fantasy_CreateTeam_FullMethodName:    func() proto.Message { return &TeamInfo{} },
+	Fantasy_EnterContest_FullMethodName:  func() proto.Message { return &EnterContestResponse{} },
+	Fantasy_UpdateLineup_FullMethodName:  func() proto.Message { return &UpdateLineupResponse{} },
+	Fantasy_LeaveContest_FullMethodName:  func() proto.Message { return &LeaveContestResponse{} },
+}
+
+// fantasyServer implements the Fantasy gRPC service on top of the same domain functions
+// as the HTTP handlers
+type fantasyServer struct {
+	UnimplementedFantasyServer
+	db *sql.DB
+}
+
+// newGRPCServer returns a gRPC server with the Fantasy service registered
+func newGRPCServer(db *sql.DB) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcUnaryErrorInterceptor, grpcIdempotencyInterceptor(db)),
+		grpc.ChainStreamInterceptor(grpcStreamErrorInterceptor),
+	)
+	RegisterFantasyServer(s, &fantasyServer{db: db})
+	return s
+}
+
+func (s *fantasyServer) ListContests(ctx context.Context, req *ListContestsRequest) (*ListContestsResponse, error) {
+	contests, err := getLobbyContests(s.db)
+	if err != nil {
+		return nil, apiError(err, "Failed to fetch contests")
+	}
+
+	now := time.Now()
+	res := &ListContestsResponse{Contests: make([]*ContestInfo, len(contests))}
+	for i, row := range contests {
+		info := newContestInfo(row.Contest, now)
+		info.SeriesContests = int32(row.SeriesContests)
+		info.SeriesEntries = int32(row.SeriesEntries)
+		res.Contests[i] = info
+	}
+
+	return res, nil
+}
+
+func (s *fantasyServer) GetContest(ctx context.Context, req *GetContestRequest) (*ContestInfo, error) {
+	contest, err := getContest(s.db, int(req.ContestId))
+	if err != nil {
+		return nil, apiError(err, "Failed to fetch contest")
+	}
+
+	return newContestInfo(contest, time.Now()), nil
+}
+
+func (s *fantasyServer) CreateContest(ctx context.Context, req *NewContestRequest) (*ContestInfo, error) {
+	create := CreateContestRequest{
+		Name:              req.Name,
+		Prize:             req.Prize,
+		EntryFee:          req.EntryFee,
+		TotalSlots:        int(req.TotalSlots),
+		MaxEntriesPerUser: int(req.MaxEntriesPerUser),
+		RosterSize:        int(req.RosterSize),
+		IsGuaranteed:      req.IsGuaranteed,
+		MinFillPercent:    int(req.MinFillPercent),
+		AutoClone:         req.AutoClone,
+		MaxClones:         int(req.MaxClones),
+		StartDate:         protoTime(req.StartDate),
+		EndDate:           protoTime(req.EndDate),
+	}
+	if err := validateRequest(create); err != nil {
+		return nil, err
+	}
+
+	contestID, err := createContest(s.db, create.toContest())
+	if err != nil {
+		return nil, apiError(err, "Failed to create contest")
+	}
+
+	contest, err := getContest(s.db, contestID)
+	if err != nil {
+		return nil, apiError(err, "Failed to fetch contest")
+	}
+
+	return newContestInfo(contest, time.Now()), nil
+}
+
+func (s *fantasyServer) GetLeaderboard(ctx context.Context, req *GetLeaderboardRequest) (*Leaderboard, error) {
+	leaderboard, err := getContestLeaderboard(s.db, int(req.ContestId))
+	if err != nil {
+		return nil, apiError(err, "Failed to fetch the leaderboard")
+	}
+
+	return newLeaderboard(int(req.ContestId), leaderboard), nil
+}
+
+// WatchLeaderboard streams the leaderboard until the client goes away, sending it again
+// whenever the points change
+func (s *fantasyServer) WatchLeaderboard(req *WatchLeaderboardRequest, stream Fantasy_WatchLeaderboardServer) error {
+	ticker := time.NewTicker(leaderboardWatchInterval)
+	defer ticker.Stop()
+
+	var last []LeaderboardEntry
+	sent := false
+	for {
+		leaderboard, err := getContestLeaderboard(s.db, int(req.ContestId))
+		if err != nil {
+			return apiError(err, "Failed to fetch the leaderboard")
+		}
+
+		if !sent || !reflect.DeepEqual(leaderboard, last) {
+			if err := stream.Send(newLeaderboard(int(req.ContestId), leaderboard)); err != nil {
+				return err
+			}
+			last = leaderboard
+			sent = true
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *fantasyServer) CreateTeam(ctx context.Context, req *NewTeamRequest) (*TeamInfo, error) {
+	create := CreateTeamRequest{Name: req.Name, DisplayName: req.DisplayName}
+	if err := validateRequest(create); err != nil {
+		return nil, err
+	}
+
+	teamID, err := createTeam(s.db, create.Name, create.DisplayName)
+	if err != nil {
+		return nil, apiError(err, "Failed to create team")
+	}
+
+	team, err := getTeam(s.db, teamID)
+	if err != nil {
+		return nil, apiError(err, "Failed to fetch team")
+	}
+
+	return newTeamInfo(team), nil
+}
+
+func (s *fantasyServer) ListTeams(ctx context.Context, req *ListTeamsRequest) (*ListTeamsResponse, error) {
+	teams, err := getTeams(s.db)
+	if err != nil {
+		return nil, apiError(err, "Failed to fetch teams")
+	}
+
+	res := &ListTeamsResponse{Teams: make([]*TeamInfo, len(teams))}
+	for i := range teams {
+		res.Teams[i] = newTeamInfo(&teams[i])
+	}
+
+	return res, nil
+}
+
+func (s *fantasyServer) GetTeam(ctx context.Context, req *GetTeamRequest) (*TeamInfo, error) {
+	team, err := getTeam(s.db, int(req.TeamId))
+	if err != nil {
+		return nil, apiError(err, "Failed to fetch team")
+	}
+
+	return newTeamInfo(team), nil
+}
+
+func (s *fantasyServer) EnterContest(ctx context.Context, req *EnterContestRequest) (*EnterContestResponse, error) {
+	userID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entryID, err := enterContest(s.db, ContestEntry{
+		ContestID:  int(req.ContestId),
+		UserID:     userID,
+		Lineup:     fromProtoIDs(req.Lineup),
+		Roles:      fromProtoRoles(req.Roles),
+		InviteCode: req.InviteCode,
+		Password:   req.Password,
+	})
+	if err != nil {
+		return nil, apiError(err, "Failed to enter contest")
+	}
+
+	return &EnterContestResponse{EntryId: int64(entryID)}, nil
+}
+
+func (s *fantasyServer) ListEntries(ctx context.Context, req *ListEntriesRequest) (*ListEntriesResponse, error) {
+	userID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := getUserEntries(s.db, userID)
+	if err != nil {
+		return nil, apiError(err, "Failed to fetch entries")
+	}
+
+	res := &ListEntriesResponse{Entries: make([]*EntryInfo, len(entries))}
+	for i, entry := range entries {
+		res.Entries[i] = &EntryInfo{
+			Id:          int64(entry.ID),
+			ContestId:   int64(entry.ContestID),
+			ContestName: entry.ContestName,
+			UserId:      int64(entry.UserID),
+			Status:      entry.Status,
+			Lineup:      toProtoIDs(entry.Lineup),
+			Roles:       toProtoRoles(entry.Roles),
+			CreatedAt:   timestamppb.New(entry.CreatedAt),
+		}
+	}
+
+	return res, nil
+}
+
+func (s *fantasyServer) UpdateLineup(ctx context.Context, req *UpdateLineupRequest) (*UpdateLineupResponse, error) {
+	userID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateEntryLineup(s.db, userID, int(req.EntryId), fromProtoIDs(req.Lineup), fromProtoRoles(req.Roles)); err != nil {
+		return nil, apiError(err, "Failed to update the lineup")
+	}
+
+	return &UpdateLineupResponse{}, nil
+}
+
+func (s *fantasyServer) LeaveContest(ctx context.Context, req *LeaveContestRequest) (*LeaveContestResponse, error) {
+	userID, err := grpcUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := leaveContest(s.db, userID, int(req.ContestId), int(req.EntryId)); err != nil {
+		return nil, apiError(err, "Failed to leave the contest")
+	}
+
+	return &LeaveContestResponse{}, nil
+}
+
+// grpcUserID returns the ID of the user making the call, sent in the same key as the
+// HTTP header
+func grpcUserID(ctx context.Context) (int, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(strings.ToLower(userIDHeader))
+	if len(values) == 0 {
+		return 0, unauthorizedError("unauthenticated", "Missing or invalid user ID")
+	}
+
+	userID, err := strconv.Atoi(values[0])
+	if err != nil || userID <= 0 {
+		return 0, unauthorizedError("unauthenticated", "Missing or invalid user ID")
+	}
+
+	return userID, nil
+}
+
+// grpcRequestID returns the request ID sent by the client, or a new one
+func grpcRequestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(strings.ToLower(requestIDHeader))
+	if len(values) == 0 || values[0] == "" || len(values[0]) > maxRequestIDLength {
+		return newRequestID()
+	}
+	return values[0]
+}
+
+// grpcUnaryErrorInterceptor converts the errors returned by the service into gRPC statuses
+func grpcUnaryErrorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	res, err := handler(ctx, req)
+	if err != nil {
+		return nil, grpcStatusError(info.FullMethod, grpcRequestID(ctx), err)
+	}
+	return res, nil
+}
+
+// grpcIdempotencyInterceptor gives the calls in grpcIdempotentMethods the semantics of
+// the Idempotency-Key header, with the key sent in the idempotency-key metadata. Keys
+// share the HTTP API's store and per-user scope. Only successful responses are stored;
+// a failed call releases its key so a retry runs it again.
+func grpcIdempotencyInterceptor(db *sql.DB) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		newResponse, ok := grpcIdempotentMethods[info.FullMethod]
+		md, _ := metadata.FromIncomingContext(ctx)
+		keys := md.Get(grpcIdempotencyKey)
+		if !ok || len(keys) == 0 || keys[0] == "" {
+			return handler(ctx, req)
+		}
+		key := keys[0]
+
+		userID, err := grpcUserID(ctx)
+		if err != nil {
+			return nil, errIdempotencyKeyAnonymous
+		}
+		scope := strconv.Itoa(userID)
+
+		if len(key) > maxIdempotencyKeyLength {
+			return nil, errIdempotencyKeyTooLong
+		}
+
+		body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req.(proto.Message))
+		if err != nil {
+			return nil, apiError(err, "Failed to check the idempotency key")
+		}
+		hash := idempotencyRequestHash("GRPC", info.FullMethod, body)
+
+		stored, err := claimIdempotencyKey(db, scope, key, hash)
+		if err != nil {
+			return nil, apiError(err, "Failed to check the idempotency key")
+		}
+
+		if stored != nil {
+			switch {
+			case stored.RequestHash != hash:
+				return nil, errIdempotencyKeyReused
+			case stored.Status == idempotencyPending:
+				return nil, errIdempotencyKeyInUse
+			}
+
+			res := newResponse()
+			if err := proto.Unmarshal(stored.ResponseBody, res); err != nil {
+				return nil, apiError(err, "Failed to replay the response")
+			}
+			grpc.SetHeader(ctx, metadata.Pairs("idempotent-replayed", "true"))
+			return res, nil
+		}
+
+		res, err := handler(ctx, req)
+		if err != nil {
+			if err := releaseIdempotencyKey(db, scope, key); err != nil {
+				log.Printf("idempotency: failed to release key %q: %v", key, err)
+			}
+			return nil, err
+		}
+
+		body, err = proto.Marshal(res.(proto.Message))
+		if err == nil {
+			err = completeIdempotencyKey(db, scope, key, http.StatusOK, "application/protobuf", body)
+		}
+		if err != nil {
+			log.Printf("idempotency: failed to store the response for key %q: %v", key, err)
+		}
+
+		return res, nil
+	}
+}
+
+// grpcStreamErrorInterceptor converts the errors returned by streaming calls into gRPC statuses
+func grpcStreamErrorInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := handler(srv, ss); err != nil {
+		return grpcStatusError(info.FullMethod, grpcRequestID(ss.Context()), err)
+	}
+	return nil
+}
+
+// grpcStatusError renders err as a gRPC status. Like abortWithError, internal and
+// unavailable errors are logged with their cause and clients only see the message. The
+// error code and invalid fields travel as ErrorInfo and BadRequest details.
+func grpcStatusError(method, requestID string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	apiErr := apiError(err, "Internal server error")
+	if apiErr.Kind == kindInternal || apiErr.Kind == kindUnavailable {
+		log.Printf("%s [%s]: %s: %v", method, requestID, apiErr.Message, apiErr.Err)
+	}
+
+	st := status.New(grpcCode(apiErr.Kind), apiErr.Message)
+	info := &errdetails.ErrorInfo{
+		Reason:   apiErr.Code,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"request_id": requestID},
+	}
+
+	var withDetails *status.Status
+	if len(apiErr.Fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(apiErr.Fields))
+		for i, field := range apiErr.Fields {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message}
+		}
+		withDetails, err = st.WithDetails(info, &errdetails.BadRequest{FieldViolations: violations})
+	} else {
+		withDetails, err = st.WithDetails(info)
+	}
+	if err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// grpcCode returns the gRPC code of an error kind
+func grpcCode(kind errorKind) codes.Code {
+	switch kind {
+	case kindValidation:
+		return codes.InvalidArgument
+	case kindUnauthorized:
+		return codes.Unauthenticated
+	case kindForbidden:
+		return codes.PermissionDenied
+	case kindNotFound:
+		return codes.NotFound
+	case kindConflict:
+		return codes.FailedPrecondition
+	case kindUnprocessable:
+		return codes.InvalidArgument
+	case kindUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// newContestInfo maps a contest onto its gRPC shape as seen at now
+func newContestInfo(contest *Contest, now time.Time) *ContestInfo {
+	res := newContestResponse(contest, now)
+	return &ContestInfo{
+		Id:                int64(contest.ID),
+		Name:              contest.Name,
+		Prize:             contest.Prize,
+		PrizeStructure:    contest.PrizeStructure,
+		EntryFee:          contest.EntryFee,
+		TotalSlots:        int32(contest.TotalSlots),
+		RemainingSlots:    int32(contest.RemainingSlots),
+		FilledSlots:       int32(res.FilledSlots),
+		FillPercent:       res.FillPercent,
+		MaxEntriesPerUser: int32(contest.MaxEntriesPerUser),
+		RosterSize:        int32(contest.RosterSize),
+		Status:            contest.Status,
+		IsPrivate:         contest.IsPrivate,
+		IsLocked:          contest.IsLocked,
+		IsGuaranteed:      contest.IsGuaranteed,
+		MinFillPercent:    int32(contest.MinFillPercent),
+		SlateId:           int64(contest.SlateID),
+		SeriesId:          int64(contest.SeriesID),
+		CloneNumber:       int32(contest.CloneNumber),
+		StartDate:         timestamppb.New(contest.StartDate),
+		EndDate:           timestamppb.New(contest.EndDate),
+		LocksAt:           timestamppb.New(contest.StartDate),
+		SecondsToLock:     res.SecondsToLock,
+		CreatedAt:         timestamppb.New(contest.CreatedAt),
+	}
+}
+
+// newTeamInfo maps a team onto its gRPC shape
+func newTeamInfo(team *Team) *TeamInfo {
+	return &TeamInfo{
+		Id:          int64(team.ID),
+		Name:        team.Name,
+		DisplayName: team.DisplayName,
+		CreatedAt:   timestamppb.New(team.CreatedAt),
+	}
+}
+
+// newLeaderboard maps a contest's leaderboard onto its gRPC shape
+func newLeaderboard(contestID int, leaderboard []LeaderboardEntry) *Leaderboard {
+	rows := make([]*LeaderboardRow, len(leaderboard))
+	for i, entry := range leaderboard {
+		rows[i] = &LeaderboardRow{
+			Rank:    int32(entry.Rank),
+			EntryId: int64(entry.EntryID),
+			UserId:  int64(entry.UserID),
+			Points:  entry.Points,
+		}
+	}
+
+	return &Leaderboard{ContestId: int64(contestID), Rows: rows, UpdatedAt: timestamppb.Now()}
+}
+
+// protoTime converts an optional timestamp; a missing one is the zero time, which the
+// request validation rejects where a time is required
+func protoTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func fromProtoIDs(ids []int64) []int {
+	res := make([]int, len(ids))
+	for i, id := range ids {
+		res[i] = int(id)
+	}
+	return res
+}
+
+func toProtoIDs(ids []int) []int64 {
+	res := make([]int64, len(ids))
+	for i, id := range ids {
+		res[i] = int64(id)
+	}
+	return res
+}
+
+func fromProtoRoles(roles map[string]int64) LineupRoles {
+	res := make(LineupRoles, len(roles))
+	for role, playerID := range roles {
+		res[role] = int(playerID)
+	}
+	return res
+}
+
+func toProtoRoles(roles LineupRoles) map[string]int64 {
+	res := make(map[string]int64, len(roles))
+	for role, playerID := range roles {
+		res[role] = int64(playerID)
+	}
+	return res
+}
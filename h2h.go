@@ -0,0 +1,608 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Head-to-head challenge statuses
+const (
+	challengeOpen      = "open"
+	challengeMatched   = "matched"
+	challengeCancelled = "cancelled"
+)
+
+const (
+	// Share of the collected entry fees kept by the house in a head-to-head contest
+	h2hRake = 0.10
+
+	// Largest skill rating gap allowed between two matched opponents
+	h2hSkillBand = 200
+)
+
+// Challenge is a user's request for a head-to-head opponent on a slate at a given entry fee.
+// A challenge with InvitedUserID set can only be accepted by that user.
+type Challenge struct {
+	ID            int     `json:"id"`
+	UserID        int     `json:"user_id"`
+	SlateID       int     `json:"slate_id"`
+	EntryFee      float64 `json:"entry_fee"`
+	InvitedUserID *int    `json:"invited_user_id,omitempty"`
+	Status        string  `json:"status"`
+	MatchedUserID *int    `json:"matched_user_id,omitempty"`
+	ContestID     *int    `json:"contest_id,omitempty"`
+}
+
+// ChallengeRequest is the request body used to post a head-to-head challenge
+type ChallengeRequest struct {
+	SlateID    int     `json:"slate_id"`
+	EntryFee   float64 `json:"entry_fee"`
+	Lineup     []int   `json:"lineup"`
+	OpponentID int     `json:"opponent_id,omitempty"`
+}
+
+// ChallengeAccept is the request body used by an invited friend to accept a challenge
+type ChallengeAccept struct {
+	Lineup []int `json:"lineup"`
+}
+
+func setupH2HRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to post a challenge; it is matched right away when a suitable opponent is waiting
+	r.POST("/h2h/challenges", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		var req ChallengeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		challenge, err := postChallenge(db, userID, req)
+		if err != nil {
+			c.Error(apiError(err, "Failed to post the challenge"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, challenge)
+	})
+
+	// Route for an invited friend to accept a challenge
+	r.POST("/h2h/challenges/:id/accept", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		challengeID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid challenge ID"))
+			return
+		}
+
+		var req ChallengeAccept
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		challenge, err := acceptChallenge(db, userID, challengeID, req.Lineup)
+		if err != nil {
+			c.Error(apiError(err, "Failed to accept the challenge"))
+			return
+		}
+
+		c.JSON(http.StatusOK, challenge)
+	})
+
+	// Route to withdraw an open challenge
+	r.DELETE("/h2h/challenges/:id", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		challengeID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid challenge ID"))
+			return
+		}
+
+		if err := cancelChallenge(db, userID, challengeID); err != nil {
+			c.Error(apiError(err, "Failed to cancel the challenge"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Challenge cancelled successfully"})
+	})
+}
+
+// openChallenge is a stored challenge waiting for an opponent
+type openChallenge struct {
+	ID       int
+	UserID   int
+	EntryFee float64
+	FeeHeld  bool
+	Lineup   []int
+}
+
+// challengerError wraps why the user who posted a challenge could not be entered when
+// it was matched, so the challenge can be dropped instead of blocking later matches
+type challengerError struct {
+	err error
+}
+
+func (e *challengerError) Error() string {
+	return e.err.Error()
+}
+
+func (e *challengerError) Unwrap() error {
+	return e.err
+}
+
+// postChallenge either matches the user against a waiting challenge of similar skill,
+// or stores a new open challenge and holds its entry fee. Challenges aimed at a friend
+// are never auto-matched. The lineup, the slate and the user's eligibility are checked
+// up front so a stored challenge can be matched later.
+func postChallenge(db *sql.DB, userID int, req ChallengeRequest) (*Challenge, error) {
+	if req.EntryFee < 0 {
+		return nil, invalidField("invalid_entry_fee", "entry_fee", "Entry fee cannot be negative")
+	}
+	if req.OpponentID == userID {
+		return nil, invalidField("self_challenge", "opponent_id", "You cannot challenge yourself")
+	}
+
+	// The contest is only created on a match; eligibility does not depend on it
+	if !isUserEligible(db, userID, 0) {
+		return nil, errNotEligible
+	}
+
+	lineup, err := json.Marshal(req.Lineup)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	slate, err := getSlate(tx, req.SlateID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if !time.Now().Before(slate.StartTime) {
+		tx.Rollback()
+		return nil, conflictError("slate_started", "The slate has already started")
+	}
+
+	if err := validateSlateLineup(tx, slate, req.Lineup); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var skillRating int
+	err = tx.QueryRow("SELECT skill_rating FROM users WHERE id = ?", userID).Scan(&skillRating)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Match the longest-waiting open challenge that fits. A challenger who can no longer
+	// be entered has their challenge dropped and the next one is tried.
+	if req.OpponentID == 0 {
+		for {
+			open, err := nextOpenChallenge(tx, userID, req.SlateID, req.EntryFee, skillRating)
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			if open == nil {
+				break
+			}
+
+			contestID, err := matchChallenge(db, tx, slate, open, userID, req.Lineup)
+			var challengerErr *challengerError
+			if errors.As(err, &challengerErr) {
+				if err := dropChallenge(tx, slate, open, challengerErr.err); err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+				continue
+			}
+			if err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+
+			if err := tx.Commit(); err != nil {
+				return nil, err
+			}
+
+			return &Challenge{ID: open.ID, UserID: open.UserID, SlateID: req.SlateID, EntryFee: req.EntryFee, Status: challengeMatched, MatchedUserID: &userID, ContestID: &contestID}, nil
+		}
+	}
+
+	// No opponent yet: park the challenge until someone matches or the friend accepts
+	var invitedUserID sql.NullInt64
+	if req.OpponentID != 0 {
+		invitedUserID = sql.NullInt64{Int64: int64(req.OpponentID), Valid: true}
+	}
+
+	res, err := tx.Exec(
+		"INSERT INTO h2h_challenge (user_id, slate_id, entry_fee, skill_rating, invited_user_id, lineup, status, fee_held, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, TRUE, NOW())",
+		userID, req.SlateID, req.EntryFee, skillRating, invitedUserID, string(lineup), challengeOpen,
+	)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	challengeID, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Hold the entry fee so the challenge can always be entered once it is matched
+	if err := debitWallet(tx, userID, req.EntryFee, fmt.Sprintf("Entry fee held for head-to-head challenge %d", challengeID)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	challenge := &Challenge{ID: int(challengeID), UserID: userID, SlateID: req.SlateID, EntryFee: req.EntryFee, Status: challengeOpen}
+	if req.OpponentID != 0 {
+		challenge.InvitedUserID = &req.OpponentID
+
+		message := fmt.Sprintf("You have been challenged to a head-to-head contest on %s. Accept challenge %d to play.", slate.Name, challengeID)
+		if err := notifyUser(tx, req.OpponentID, notificationChallengeReceived, message); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// nextOpenChallenge locks the longest-waiting open challenge the user can be matched
+// against, or returns nil when there is none. Rows another matcher already holds are
+// skipped so two users cannot claim the same opponent. The fee is bound as a decimal
+// string so it compares exactly against the DECIMAL column instead of as a float.
+func nextOpenChallenge(tx *sql.Tx, userID int, slateID int, entryFee float64, skillRating int) (*openChallenge, error) {
+	var open openChallenge
+	var lineup string
+	err := tx.QueryRow(
+		"SELECT id, user_id, entry_fee, fee_held, lineup FROM h2h_challenge WHERE status = ? AND slate_id = ? AND entry_fee = ? AND invited_user_id IS NULL AND user_id <> ? AND ABS(skill_rating - ?) <= ? ORDER BY created_at, id LIMIT 1 FOR UPDATE SKIP LOCKED",
+		challengeOpen, slateID, strconv.FormatFloat(entryFee, 'f', 2, 64), userID, skillRating, h2hSkillBand,
+	).Scan(&open.ID, &open.UserID, &open.EntryFee, &open.FeeHeld, &lineup)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(lineup), &open.Lineup); err != nil {
+		return nil, err
+	}
+
+	return &open, nil
+}
+
+// acceptChallenge lets the invited friend take up a challenge aimed at them. A challenge
+// whose poster can no longer be entered is cancelled and refunded instead.
+func acceptChallenge(db *sql.DB, userID int, challengeID int, lineup []int) (*Challenge, error) {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var challenge Challenge
+	var invitedUserID sql.NullInt64
+	var challengerLineup string
+	var feeHeld bool
+	err = tx.QueryRow(
+		"SELECT id, user_id, slate_id, entry_fee, invited_user_id, lineup, status, fee_held FROM h2h_challenge WHERE id = ? FOR UPDATE",
+		challengeID,
+	).Scan(&challenge.ID, &challenge.UserID, &challenge.SlateID, &challenge.EntryFee, &invitedUserID, &challengerLineup, &challenge.Status, &feeHeld)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("challenge_not_found", "Challenge not found")
+		}
+		return nil, err
+	}
+
+	if challenge.Status != challengeOpen {
+		tx.Rollback()
+		return nil, conflictError("challenge_closed", "The challenge is no longer open")
+	}
+
+	if !invitedUserID.Valid || int(invitedUserID.Int64) != userID {
+		tx.Rollback()
+		return nil, forbiddenError("challenge_not_for_you", "This challenge was not sent to you")
+	}
+
+	open := &openChallenge{ID: challenge.ID, UserID: challenge.UserID, EntryFee: challenge.EntryFee, FeeHeld: feeHeld}
+	if err := json.Unmarshal([]byte(challengerLineup), &open.Lineup); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	slate, err := getSlate(tx, challenge.SlateID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	contestID, err := matchChallenge(db, tx, slate, open, userID, lineup)
+	var challengerErr *challengerError
+	if errors.As(err, &challengerErr) {
+		if err := dropChallenge(tx, slate, open, challengerErr.err); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+		return nil, conflictError("challenge_closed", "The challenger can no longer play, so the challenge was cancelled")
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	challenge.Status = challengeMatched
+	challenge.MatchedUserID = &userID
+	challenge.ContestID = &contestID
+	challenge.InvitedUserID = &userID
+
+	return &challenge, nil
+}
+
+// matchChallenge creates the two-player contest for a matched challenge and enters
+// both users, all inside tx, so either both are entered and charged or neither is.
+// When the challenger cannot be entered, everything the match did is rolled back to a
+// savepoint and a *challengerError is returned, leaving tx usable.
+func matchChallenge(db *sql.DB, tx *sql.Tx, slate *Slate, open *openChallenge, userID int, lineup []int) (int, error) {
+	if _, err := tx.Exec("SAVEPOINT h2h_match"); err != nil {
+		return 0, err
+	}
+
+	contestID, err := insertContest(tx, &Contest{
+		Name:              fmt.Sprintf("Head-to-Head: %s", slate.Name),
+		Prize:             2 * open.EntryFee * (1 - h2hRake),
+		EntryFee:          open.EntryFee,
+		TotalSlots:        2,
+		MaxEntriesPerUser: 1,
+		RosterSize:        slate.RosterSize,
+		SlateID:           slate.ID,
+		StartDate:         slate.StartTime,
+		EndDate:           slate.EndTime,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// The challenger's fee was held when the challenge was posted; release it so entering
+	// charges it as for any other contest
+	if open.FeeHeld {
+		if err := creditWallet(tx, open.UserID, open.EntryFee, fmt.Sprintf("Release of the fee held for head-to-head challenge %d", open.ID)); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := addEntry(db, tx, ContestEntry{ContestID: contestID, UserID: open.UserID, Lineup: open.Lineup}, true); err != nil {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			return 0, err
+		}
+		if _, err := tx.Exec("ROLLBACK TO SAVEPOINT h2h_match"); err != nil {
+			return 0, err
+		}
+		return 0, &challengerError{err: err}
+	}
+
+	if _, err := addEntry(db, tx, ContestEntry{ContestID: contestID, UserID: userID, Lineup: lineup}, true); err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec("UPDATE h2h_challenge SET status = ?, contest_id = ?, matched_user_id = ? WHERE id = ?", challengeMatched, contestID, userID, open.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range []int{open.UserID, userID} {
+		message := fmt.Sprintf("Your head-to-head challenge on %s has been matched. Good luck!", slate.Name)
+		if err := notifyUser(tx, id, notificationChallengeMatched, message); err != nil {
+			return 0, err
+		}
+	}
+
+	return contestID, nil
+}
+
+// dropChallenge cancels a challenge whose poster could not be entered when it was
+// matched, refunds the held fee and tells them why
+func dropChallenge(tx *sql.Tx, slate *Slate, open *openChallenge, reason error) error {
+	message := fmt.Sprintf("Your head-to-head challenge on %s was cancelled because your entry could not be made: %s.", slate.Name, reason.Error())
+	return closeChallenge(tx, open.ID, open.UserID, open.EntryFee, open.FeeHeld, message)
+}
+
+// closeChallenge cancels an open challenge inside tx, refunds its held fee and notifies
+// the poster with message unless it is empty
+func closeChallenge(tx *sql.Tx, challengeID int, userID int, entryFee float64, feeHeld bool, message string) error {
+	_, err := tx.Exec("UPDATE h2h_challenge SET status = ? WHERE id = ?", challengeCancelled, challengeID)
+	if err != nil {
+		return err
+	}
+
+	if feeHeld {
+		if err := creditWallet(tx, userID, entryFee, fmt.Sprintf("Refund for head-to-head challenge %d", challengeID)); err != nil {
+			return err
+		}
+	}
+
+	if message == "" {
+		return nil
+	}
+	return notifyUser(tx, userID, notificationChallengeCancelled, message)
+}
+
+// cancelChallenge withdraws one of the user's open challenges and refunds its held fee
+func cancelChallenge(db *sql.DB, userID int, challengeID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var entryFee float64
+	var feeHeld bool
+	err = tx.QueryRow(
+		"SELECT entry_fee, fee_held FROM h2h_challenge WHERE id = ? AND user_id = ? AND status = ? FOR UPDATE",
+		challengeID, userID, challengeOpen,
+	).Scan(&entryFee, &feeHeld)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return notFoundError("challenge_not_found", "Open challenge not found")
+		}
+		return err
+	}
+
+	if err := closeChallenge(tx, challengeID, userID, entryFee, feeHeld, ""); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// expireStartedChallenges cancels the open challenges whose slate has started without
+// an opponent and refunds their held fees. A challenge that fails is logged and left
+// for the next run so it does not hold up the others.
+func expireStartedChallenges(db *sql.DB) error {
+	rows, err := db.Query("SELECT ch.id FROM h2h_challenge ch JOIN slate s ON s.id = ch.slate_id WHERE ch.status = ? AND s.start_time <= NOW()", challengeOpen)
+	if err != nil {
+		return err
+	}
+
+	var challengeIDs []int
+	for rows.Next() {
+		var challengeID int
+		if err := rows.Scan(&challengeID); err != nil {
+			rows.Close()
+			return err
+		}
+		challengeIDs = append(challengeIDs, challengeID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, challengeID := range challengeIDs {
+		if err := expireChallenge(db, challengeID); err != nil {
+			log.Printf("h2h: failed to expire challenge %d: %v", challengeID, err)
+		}
+	}
+
+	return nil
+}
+
+// expireChallenge cancels one open challenge whose slate has started
+func expireChallenge(db *sql.DB, challengeID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Re-check under lock in case the challenge was matched or cancelled meanwhile
+	var userID int
+	var entryFee float64
+	var feeHeld bool
+	var slateName string
+	err = tx.QueryRow(
+		"SELECT ch.user_id, ch.entry_fee, ch.fee_held, s.name FROM h2h_challenge ch JOIN slate s ON s.id = ch.slate_id WHERE ch.id = ? AND ch.status = ? FOR UPDATE",
+		challengeID, challengeOpen,
+	).Scan(&userID, &entryFee, &feeHeld, &slateName)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	message := fmt.Sprintf("Nobody took up your head-to-head challenge on %s before it started. Your entry fee has been refunded.", slateName)
+	if err := closeChallenge(tx, challengeID, userID, entryFee, feeHeld, message); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyHeader carries the client-chosen key that makes a mutating request safe to retry
+const idempotencyHeader = "Idempotency-Key"
+
+// Idempotency key statuses
+const (
+	idempotencyPending   = "pending"
+	idempotencyCompleted = "completed"
+)
+
+const (
+	// idempotencyTTL is how long a stored response is replayed for its key
+	idempotencyTTL = 24 * time.Hour
+	// idempotencyPendingTimeout is how long a key can stay pending before another
+	// request may take it over, e.g. after the instance handling it crashed
+	idempotencyPendingTimeout = time.Minute
+	// maxIdempotencyKeyLength matches the width of the idempotency_key column
+	maxIdempotencyKeyLength = 255
+	idempotencyJobInterval  = time.Hour
+)
+
+var (
+	errIdempotencyKeyAnonymous = unauthorizedError("unauthenticated", "Idempotency-Key requires an authenticated user")
+	errIdempotencyKeyTooLong   = invalidField("invalid_idempotency_key", idempotencyHeader, "Idempotency-Key is too long")
+	errIdempotencyKeyReused    = unprocessableError("idempotency_key_reused", "Idempotency-Key was already used with a different request")
+	errIdempotencyKeyInUse     = conflictError("idempotency_key_in_use", "A request with this Idempotency-Key is still being processed")
+)
+
+// idempotentMethods are the HTTP methods the Idempotency-Key header is honored on
+var idempotentMethods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// storedResponse is the idempotency_key row of a key seen before
+type storedResponse struct {
+	RequestHash  string
+	Status       string
+	ResponseCode int
+	ContentType  string
+	ResponseBody []byte
+}
+
+// idempotentWriter keeps a copy of the response body so it can be stored for replays
+type idempotentWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotentWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotentWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMiddleware honors the Idempotency-Key header on mutating requests. The first
+// request with a key runs normally and its response is stored in the database; a retry
+// with the same key and body within idempotencyTTL gets the stored response replayed
+// without running the handler again. Keys are scoped to the authenticated user, so the
+// same key from two users never collides; a key sent without a user is rejected rather
+// than ignored, since the request would not be safe to retry. Server errors are not
+// stored so they can be retried.
+func idempotencyMiddleware(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyHeader)
+		if key == "" || !idempotentMethods[c.Request.Method] {
+			c.Next()
+			return
+		}
+
+		// Keys are only honored for an authenticated user; anonymous callers would all
+		// share one scope and could be replayed each other's responses
+		userID, err := currentUserID(c)
+		if err != nil {
+			abortWithError(c, errIdempotencyKeyAnonymous)
+			return
+		}
+		scope := strconv.Itoa(userID)
+
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, errIdempotencyKeyTooLong)
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithError(c, invalidBody(err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := idempotencyRequestHash(c.Request.Method, c.Request.URL.Path, body)
+
+		stored, err := claimIdempotencyKey(db, scope, key, hash)
+		if err != nil {
+			abortWithError(c, apiError(err, "Failed to check the idempotency key"))
+			return
+		}
+
+		if stored != nil {
+			switch {
+			case stored.RequestHash != hash:
+				abortWithError(c, errIdempotencyKeyReused)
+			case stored.Status == idempotencyPending:
+				abortWithError(c, errIdempotencyKeyInUse)
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(stored.ResponseCode, stored.ContentType, stored.ResponseBody)
+				c.Abort()
+			}
+			return
+		}
+
+		// A panicking handler releases the key so the client can retry
+		defer func() {
+			if p := recover(); p != nil {
+				if err := releaseIdempotencyKey(db, scope, key); err != nil {
+					log.Printf("idempotency: failed to release key %q: %v", key, err)
+				}
+				panic(p)
+			}
+		}()
+
+		w := &idempotentWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if w.Status() >= http.StatusInternalServerError {
+			err = releaseIdempotencyKey(db, scope, key)
+		} else {
+			err = completeIdempotencyKey(db, scope, key, w.Status(), w.Header().Get("Content-Type"), w.body.Bytes())
+		}
+		if err != nil {
+			log.Printf("idempotency: failed to store the response for key %q: %v", key, err)
+		}
+	}
+}
+
+// idempotencyRequestHash fingerprints a request so a key reused for a different request
+// can be told apart from a retry
+func idempotencyRequestHash(method, path string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, method+" "+path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// claimIdempotencyKey marks the key as pending for this request. It returns nil when the
+// request should run, or the stored row when the key is already taken. An expired key, or
+// one left pending for longer than idempotencyPendingTimeout, is taken over.
+func claimIdempotencyKey(db *sql.DB, scope, key, hash string) (*storedResponse, error) {
+	_, err := db.Exec(
+		"INSERT INTO idempotency_key (scope, idempotency_key, request_hash, status, created_at, updated_at, expires_at) VALUES (?, ?, ?, ?, NOW(), NOW(), ?)",
+		scope, key, hash, idempotencyPending, time.Now().Add(idempotencyTTL),
+	)
+	if err == nil {
+		return nil, nil
+	}
+	if !isDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	res, err := db.Exec(
+		"UPDATE idempotency_key SET request_hash = ?, status = ?, response_code = NULL, content_type = NULL, response_body = NULL, created_at = NOW(), updated_at = NOW(), expires_at = ? "+
+			"WHERE scope = ? AND idempotency_key = ? AND (expires_at <= NOW() OR (status = ? AND updated_at <= ?))",
+		hash, idempotencyPending, time.Now().Add(idempotencyTTL), scope, key, idempotencyPending, time.Now().Add(-idempotencyPendingTimeout),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, nil
+	}
+
+	var stored storedResponse
+	var responseCode sql.NullInt64
+	var contentType sql.NullString
+	err = db.QueryRow(
+		"SELECT request_hash, status, response_code, content_type, response_body FROM idempotency_key WHERE scope = ? AND idempotency_key = ?",
+		scope, key,
+	).Scan(&stored.RequestHash, &stored.Status, &responseCode, &contentType, &stored.ResponseBody)
+	if err != nil {
+		return nil, err
+	}
+	stored.ResponseCode = int(responseCode.Int64)
+	stored.ContentType = contentType.String
+
+	return &stored, nil
+}
+
+// completeIdempotencyKey stores the response of the request that claimed the key
+func completeIdempotencyKey(db *sql.DB, scope, key string, code int, contentType string, body []byte) error {
+	_, err := db.Exec(
+		"UPDATE idempotency_key SET status = ?, response_code = ?, content_type = ?, response_body = ?, updated_at = NOW() WHERE scope = ? AND idempotency_key = ?",
+		idempotencyCompleted, code, contentType, body, scope, key,
+	)
+	return err
+}
+
+// releaseIdempotencyKey forgets a key whose request failed so a retry runs it again
+func releaseIdempotencyKey(db *sql.DB, scope, key string) error {
+	_, err := db.Exec("DELETE FROM idempotency_key WHERE scope = ? AND idempotency_key = ? AND status = ?", scope, key, idempotencyPending)
+	return err
+}
+
+// runIdempotencyJob periodically deletes expired idempotency keys
+func runIdempotencyJob(ctx context.Context, db *sql.DB) {
+	ticker := time.NewTicker(idempotencyJobInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := db.Exec("DELETE FROM idempotency_key WHERE expires_at <= NOW()"); err != nil {
+			log.Printf("idempotency: failed to delete expired keys: %v", err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// newIdempotencyTestRouter serves POST /things behind the idempotency middleware and
+// counts how many times the handler actually runs
+func newIdempotencyTestRouter(db *sql.DB, calls *int) *gin.Engine {
+	r := gin.New()
+	r.Use(requestIDMiddleware(), idempotencyMiddleware(db), errorMiddleware())
+	r.POST("/things", func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusCreated, gin.H{"id": *calls})
+	})
+
+	return r
+}
+
+func doIdempotent(r http.Handler, userID int, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(idempotencyHeader, key)
+	if userID != 0 {
+		req.Header.Set(userIDHeader, strconv.Itoa(userID))
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysCompletedRequest(t *testing.T) {
+	var calls int
+	r := newIdempotencyTestRouter(newTestDB(t), &calls)
+
+	first := doIdempotent(r, 7, "key-1", `{"a":1}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first request: got %d, want %d", first.Code, http.StatusCreated)
+	}
+
+	replay := doIdempotent(r, 7, "key-1", `{"a":1}`)
+	if replay.Code != http.StatusCreated {
+		t.Fatalf("replay: got %d, want %d", replay.Code, http.StatusCreated)
+	}
+	if replay.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replay is missing the Idempotent-Replayed header")
+	}
+	if replay.Body.String() != first.Body.String() {
+		t.Errorf("replay body = %s, want %s", replay.Body, first.Body)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestIdempotencyScopesKeysByUser(t *testing.T) {
+	var calls int
+	r := newIdempotencyTestRouter(newTestDB(t), &calls)
+
+	doIdempotent(r, 7, "key-1", `{"a":1}`)
+	other := doIdempotent(r, 8, "key-1", `{"a":1}`)
+	if other.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("another user's request was replayed")
+	}
+
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestIdempotencyRejectsAnonymousKey(t *testing.T) {
+	var calls int
+	// The key is rejected before the store is used, so no database is needed
+	r := newIdempotencyTestRouter(nil, &calls)
+
+	w := doIdempotent(r, 0, "key-1", `{"a":1}`)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("got %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if !strings.Contains(w.Body.String(), "unauthenticated") {
+		t.Errorf("body %s does not name unauthenticated", w.Body)
+	}
+	if calls != 0 {
+		t.Errorf("handler ran %d times, want 0", calls)
+	}
+
+	// Without a key anonymous requests still run
+	req := httptest.NewRequest(http.MethodPost, "/things", strings.NewReader(`{"a":1}`))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated || calls != 1 {
+		t.Errorf("got %d with %d calls, want %d with 1", w.Code, calls, http.StatusCreated)
+	}
+}
+
+func TestIdempotencyRejectsKeyReusedForAnotherRequest(t *testing.T) {
+	var calls int
+	r := newIdempotencyTestRouter(newTestDB(t), &calls)
+
+	doIdempotent(r, 7, "key-1", `{"a":1}`)
+	w := doIdempotent(r, 7, "key-1", `{"a":2}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("got %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+	if !strings.Contains(w.Body.String(), "idempotency_key_reused") {
+		t.Errorf("body %s does not name idempotency_key_reused", w.Body)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestIdempotencyRejectsKeyStillInProgress(t *testing.T) {
+	var calls int
+	db := newTestDB(t)
+	r := newIdempotencyTestRouter(db, &calls)
+
+	// Another instance has claimed the key and not finished yet
+	hash := idempotencyRequestHash(http.MethodPost, "/things", []byte(`{"a":1}`))
+	if _, err := claimIdempotencyKey(db, "7", "key-1", hash); err != nil {
+		t.Fatal(err)
+	}
+
+	w := doIdempotent(r, 7, "key-1", `{"a":1}`)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("got %d, want %d", w.Code, http.StatusConflict)
+	}
+	if !strings.Contains(w.Body.String(), "idempotency_key_in_use") {
+		t.Errorf("body %s does not name idempotency_key_in_use", w.Body)
+	}
+	if calls != 0 {
+		t.Errorf("handler ran %d times, want 0", calls)
+	}
+}
+
+func TestGRPCIdempotencyRejectsAnonymousKey(t *testing.T) {
+	var calls int
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return &EnterContestResponse{EntryId: 1}, nil
+	}
+	interceptor := grpcIdempotencyInterceptor(nil)
+	info := &grpc.UnaryServerInfo{FullMethod: Fantasy_EnterContest_FullMethodName}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcIdempotencyKey, "key-1"))
+	if _, err := interceptor(ctx, &EnterContestRequest{ContestId: 1}, info, handler); !errors.Is(err, errIdempotencyKeyAnonymous) {
+		t.Fatalf("got %v, want %v", err, errIdempotencyKeyAnonymous)
+	}
+	if calls != 0 {
+		t.Errorf("handler ran %d times, want 0", calls)
+	}
+
+	// Without a key the call runs as usual
+	if _, err := interceptor(context.Background(), &EnterContestRequest{ContestId: 1}, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errLeagueNotFound = notFoundError("league_not_found", "League not found")
+	errTeamNotFound   = notFoundError("team_not_found", "Team not found")
+)
+
+// League is a season-long league whose teams are each owned by one user. The regular
+// season runs RegularSeasonWeeks weeks from SeasonStart, then the top PlayoffTeams
+// teams play a knockout bracket.
+type League struct {
+	ID                 int            `json:"id"`
+	Name               string         `json:"name"`
+	CommissionerID     int            `json:"commissioner_id"`
+	SeasonStart        *time.Time     `json:"season_start,omitempty"`
+	RegularSeasonWeeks int            `json:"regular_season_weeks"`
+	PlayoffTeams       int            `json:"playoff_teams"`
+	Teams              []TeamResponse `json:"teams"`
+	CreatedAt          time.Time      `json:"created_at"`
+}
+
+// LeagueRequest is the request body used to create a league
+type LeagueRequest struct {
+	Name string `json:"name" binding:"required,min=2,max=50,name_charset"`
+}
+
+// LeagueTeamRequest is the request body used to join a league with a new team
+type LeagueTeamRequest struct {
+	Name        string `json:"name" binding:"required,min=2,max=50,name_charset"`
+	DisplayName string `json:"display_name" binding:"omitempty,max=100"`
+}
+
+func setupLeagueRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to create a league commissioned by the current user
+	r.POST("/leagues", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		var req LeagueRequest
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+
+		res, err := db.Exec("INSERT INTO league (name, commissioner_id, created_at) VALUES (?, ?, NOW())", req.Name, userID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to create the league"))
+			return
+		}
+
+		leagueID, _ := res.LastInsertId()
+		c.JSON(http.StatusCreated, gin.H{"message": "League created successfully", "league_id": leagueID})
+	})
+
+	// Route to fetch a league and its teams
+	r.GET("/leagues/:id", func(c *gin.Context) {
+		leagueID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid league ID"))
+			return
+		}
+
+		league, err := getLeague(db, leagueID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch the league"))
+			return
+		}
+
+		c.JSON(http.StatusOK, league)
+	})
+
+	// Route for the current user to join a league with a new team
+	r.POST("/leagues/:id/teams", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		leagueID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid league ID"))
+			return
+		}
+
+		var req LeagueTeamRequest
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+
+		teamID, err := joinLeague(db, leagueID, userID, req)
+		if err != nil {
+			c.Error(apiError(err, "Failed to join the league"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Team created successfully", "team_id": teamID})
+	})
+}
+
+// getLeague returns a league with its teams in the order they joined
+func getLeague(db *sql.DB, leagueID int) (*League, error) {
+	var league League
+	var seasonStart sql.NullTime
+	err := db.QueryRow("SELECT id, name, commissioner_id, season_start, regular_season_weeks, playoff_teams, created_at FROM league WHERE id = ?", leagueID).
+		Scan(&league.ID, &league.Name, &league.CommissionerID, &seasonStart, &league.RegularSeasonWeeks, &league.PlayoffTeams, &league.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errLeagueNotFound
+		}
+		return nil, err
+	}
+	if seasonStart.Valid {
+		league.SeasonStart = &seasonStart.Time
+	}
+
+	rows, err := db.Query("SELECT id, name, displayname, created_at FROM team WHERE league_id = ? ORDER BY id", leagueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	league.Teams = []TeamResponse{}
+	for rows.Next() {
+		var team Team
+		if err := rows.Scan(&team.ID, &team.Name, &team.DisplayName, &team.CreatedAt); err != nil {
+			return nil, err
+		}
+		league.Teams = append(league.Teams, newTeamResponse(&team))
+	}
+
+	return &league, rows.Err()
+}
+
+// joinLeague creates a team owned by userID in the league. A user owns at most one
+// team per league, and nobody can join once the league has started drafting.
+func joinLeague(db *sql.DB, leagueID int, userID int, req LeagueTeamRequest) (int, error) {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the league so two joins and a draft start cannot interleave
+	var id int
+	err = tx.QueryRow("SELECT id FROM league WHERE id = ? FOR UPDATE", leagueID).Scan(&id)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return 0, errLeagueNotFound
+		}
+		return 0, err
+	}
+
+	var drafts int
+	err = tx.QueryRow("SELECT COUNT(*) FROM draft WHERE league_id = ? AND status <> ?", leagueID, draftScheduled).Scan(&drafts)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if drafts > 0 {
+		tx.Rollback()
+		return 0, conflictError("league_drafted", "The league has already drafted")
+	}
+
+	var owned int
+	err = tx.QueryRow("SELECT COUNT(*) FROM team WHERE league_id = ? AND owner_id = ?", leagueID, userID).Scan(&owned)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if owned > 0 {
+		tx.Rollback()
+		return 0, conflictError("team_exists", "You already have a team in this league")
+	}
+
+	res, err := tx.Exec(
+		"INSERT INTO team (name, displayname, league_id, owner_id, created_at) VALUES (?, ?, ?, ?, NOW())",
+		req.Name, req.DisplayName, leagueID, userID,
+	)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	teamID, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(teamID), nil
+}
+
+// teamOwner returns the user who owns a league team
+func teamOwner(q queryer, teamID int) (int, error) {
+	var ownerID sql.NullInt64
+	err := q.QueryRow("SELECT owner_id FROM team WHERE id = ?", teamID).Scan(&ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errTeamNotFound
+		}
+		return 0, err
+	}
+
+	return int(ownerID.Int64), nil
+}
+
+// leagueCommissioner returns the commissioner of a league
+func leagueCommissioner(q queryer, leagueID int) (int, error) {
+	var commissionerID int
+	err := q.QueryRow("SELECT commissioner_id FROM league WHERE id = ?", leagueID).Scan(&commissionerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, errLeagueNotFound
+		}
+		return 0, err
+	}
+
+	return commissionerID, nil
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// How often contests that reached their start time are locked, and those that reached
+// their end time settled
+const contestLockInterval = time.Minute
+
+// ContestOverlay records how much the operator added to a guaranteed contest's
+// prize because the collected entry fees did not cover it
+type ContestOverlay struct {
+	ContestID     int       `json:"contest_id"`
+	ContestName   string    `json:"contest_name"`
+	Prize         float64   `json:"prize"`
+	Entries       int       `json:"entries"`
+	CollectedFees float64   `json:"collected_fees"`
+	Overlay       float64   `json:"overlay"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func setupFinanceRoutes(r *gin.Engine, db *sql.DB) {
+	// Route for operators to report overlays recorded between two dates (RFC 3339),
+	// defaulting to the last 30 days
+	r.GET("/finance/overlays", func(c *gin.Context) {
+		if _, ok := operatorRequest(c, db); !ok {
+			return
+		}
+
+		to := time.Now()
+		from := to.AddDate(0, 0, -30)
+
+		if v := c.Query("from"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.Error(invalidParam("from", "Invalid from date"))
+				return
+			}
+			from = t
+		}
+		if v := c.Query("to"); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.Error(invalidParam("to", "Invalid to date"))
+				return
+			}
+			to = t
+		}
+
+		overlays, err := getContestOverlays(db, from, to)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch overlays"))
+			return
+		}
+
+		var total float64
+		for _, o := range overlays {
+			total += o.Overlay
+		}
+
+		c.JSON(http.StatusOK, gin.H{"overlays": overlays, "total_overlay": total})
+	})
+}
+
+// lockStartedContests locks every active contest whose start time has passed. A contest
+// that fails is logged and retried on the next run so it does not hold up the others.
+func lockStartedContests(db *sql.DB) error {
+	rows, err := db.Query("SELECT id FROM contest WHERE status = ? AND start_date <= NOW()", contestStatusActive)
+	if err != nil {
+		return err
+	}
+
+	var contestIDs []int
+	for rows.Next() {
+		var contestID int
+		if err := rows.Scan(&contestID); err != nil {
+			rows.Close()
+			return err
+		}
+		contestIDs = append(contestIDs, contestID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, contestID := range contestIDs {
+		if err := lockContest(db, contestID); err != nil {
+			log.Printf("lock: failed to lock contest %d: %v", contestID, err)
+		}
+	}
+
+	return nil
+}
+
+// lockContest closes a contest at its start time. A non-guaranteed contest below its
+// minimum fill is cancelled and every entry refunded; a guaranteed contest records the
+// overlay between its prize and the fees it collected.
+func lockContest(db *sql.DB, contestID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Re-check under lock in case another run got here first
+	var status, name string
+	var totalSlots, minFillPercent int
+	var prize, entryFee float64
+	var isGuaranteed bool
+	err = tx.QueryRow(
+		"SELECT status, name, total_slots, min_fill_percent, prize, entry_fee, is_guaranteed FROM contest WHERE id = ? FOR UPDATE",
+		contestID,
+	).Scan(&status, &name, &totalSlots, &minFillPercent, &prize, &entryFee, &isGuaranteed)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if status != contestStatusActive {
+		tx.Rollback()
+		return nil
+	}
+
+	var entries int
+	err = tx.QueryRow("SELECT COUNT(*) FROM user_contest WHERE contest_id = ?", contestID).Scan(&entries)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Nobody else gets in once the contest starts
+	_, err = tx.Exec("UPDATE contest_waitlist SET status = ? WHERE contest_id = ? AND status IN (?, ?)", waitlistCancelled, contestID, waitlistWaiting, waitlistPromoted)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	underfilled := entries*100 < minFillPercent*totalSlots
+	if underfilled && !isGuaranteed {
+		err = cancelContest(tx, contestID, name, entryFee)
+		if err == nil {
+			err = recordContestEvent(tx, webhookContestCancelled, contestID, nil)
+		}
+	} else {
+		_, err = tx.Exec("UPDATE contest SET status = ? WHERE id = ?", contestStatusLocked, contestID)
+		if err == nil && isGuaranteed {
+			collected := float64(entries) * entryFee
+			overlay := prize - collected
+			if overlay < 0 {
+				overlay = 0
+			}
+			_, err = tx.Exec(
+				"INSERT INTO contest_overlay (contest_id, prize, entries, collected_fees, overlay, created_at) VALUES (?, ?, ?, ?, ?, NOW())",
+				contestID, prize, entries, collected, overlay,
+			)
+		}
+		if err == nil {
+			err = recordContestEvent(tx, webhookContestLocked, contestID, nil)
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// cancelContest marks the contest cancelled and refunds the entry fee of every entry
+func cancelContest(tx *sql.Tx, contestID int, name string, entryFee float64) error {
+	_, err := tx.Exec("UPDATE contest SET status = ? WHERE id = ?", contestStatusCancelled, contestID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.Query("SELECT user_id FROM user_contest WHERE contest_id = ?", contestID)
+	if err != nil {
+		return err
+	}
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// One refund per entry, so multi-entry users get every fee back
+	for _, userID := range userIDs {
+		if err := creditWallet(tx, userID, entryFee, fmt.Sprintf("Refund for cancelled contest %d", contestID)); err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("%s did not fill and was cancelled. Your entry fee has been refunded.", name)
+		if err := notifyUser(tx, userID, notificationContestCancelled, message); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settleEndedContests settles every locked contest whose end time has passed, logging
+// and skipping any contest that fails
+func settleEndedContests(db *sql.DB) error {
+	rows, err := db.Query("SELECT id FROM contest WHERE status = ? AND end_date <= NOW()", contestStatusLocked)
+	if err != nil {
+		return err
+	}
+
+	var contestIDs []int
+	for rows.Next() {
+		var contestID int
+		if err := rows.Scan(&contestID); err != nil {
+			rows.Close()
+			return err
+		}
+		contestIDs = append(contestIDs, contestID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, contestID := range contestIDs {
+		if err := settleContest(db, contestID); err != nil {
+			log.Printf("lock: failed to settle contest %d: %v", contestID, err)
+		}
+	}
+
+	return nil
+}
+
+// settleContest makes a locked contest's results final once it has ended and announces
+// the final leaderboard to webhook subscribers
+func settleContest(db *sql.DB, contestID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Re-check under lock in case another run got here first
+	var status string
+	err = tx.QueryRow("SELECT status FROM contest WHERE id = ? FOR UPDATE", contestID).Scan(&status)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if status != contestStatusLocked {
+		tx.Rollback()
+		return nil
+	}
+
+	leaderboard, err := getContestLeaderboard(tx, contestID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE contest SET status = ? WHERE id = ?", contestStatusSettled, contestID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := recordContestEvent(tx, webhookContestSettled, contestID, leaderboard); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// runContestLockJob periodically locks contests that have started, settles those
+// that have ended and expires head-to-head challenges nobody took up
+func runContestLockJob(ctx context.Context, db *sql.DB) {
+	ticker := time.NewTicker(contestLockInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := lockStartedContests(db); err != nil {
+			log.Printf("lock: failed to lock started contests: %v", err)
+		}
+		if err := settleEndedContests(db); err != nil {
+			log.Printf("lock: failed to settle ended contests: %v", err)
+		}
+		if err := expireStartedChallenges(db); err != nil {
+			log.Printf("lock: failed to expire head-to-head challenges: %v", err)
+		}
+	}
+}
+
+// getContestOverlays returns the overlays recorded in [from, to), newest first
+func getContestOverlays(db *sql.DB, from, to time.Time) ([]ContestOverlay, error) {
+	rows, err := db.Query(
+		"SELECT o.contest_id, c.name, o.prize, o.entries, o.collected_fees, o.overlay, o.created_at FROM contest_overlay o JOIN contest c ON c.id = o.contest_id WHERE o.created_at >= ? AND o.created_at < ? ORDER BY o.created_at DESC",
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overlays := []ContestOverlay{}
+	for rows.Next() {
+		var o ContestOverlay
+		if err := rows.Scan(&o.ContestID, &o.ContestName, &o.Prize, &o.Entries, &o.CollectedFees, &o.Overlay, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		overlays = append(overlays, o)
+	}
+
+	return overlays, rows.Err()
+}
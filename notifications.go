@@ -0,0 +1,80 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Notification kinds
+const (
+	notificationWaitlistPromoted   = "waitlist_promoted"
+	notificationWaitlistExpired    = "waitlist_expired"
+	notificationEntryRemoved       = "entry_removed"
+	notificationChallengeReceived  = "challenge_received"
+	notificationChallengeMatched   = "challenge_matched"
+	notificationChallengeCancelled = "challenge_cancelled"
+	notificationContestCancelled   = "contest_cancelled"
+	notificationTradeProposed      = "trade_proposed"
+	notificationTradeAccepted      = "trade_accepted"
+	notificationTradeExecuted      = "trade_executed"
+	notificationTradeClosed        = "trade_closed"
+)
+
+// Notification is a message shown to a user in the app
+type Notification struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Kind      string    `json:"kind"`
+	Message   string    `json:"message"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func setupNotificationRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to list the current user's notifications
+	r.GET("/me/notifications", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		notifications, err := getUserNotifications(db, userID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch notifications"))
+			return
+		}
+
+		c.JSON(http.StatusOK, notifications)
+	})
+}
+
+// notifyUser queues a notification for the user as part of the caller's transaction,
+// so it is only delivered if the change it describes is committed
+func notifyUser(tx *sql.Tx, userID int, kind, message string) error {
+	_, err := tx.Exec("INSERT INTO notification (user_id, kind, message, is_read, created_at) VALUES (?, ?, ?, FALSE, NOW())", userID, kind, message)
+	return err
+}
+
+// getUserNotifications returns the user's most recent notifications
+func getUserNotifications(db *sql.DB, userID int) ([]Notification, error) {
+	rows, err := db.Query("SELECT id, user_id, kind, message, is_read, created_at FROM notification WHERE user_id = ? ORDER BY id DESC LIMIT 100", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Message, &n.Read, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
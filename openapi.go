@@ -0,0 +1,436 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIPage is the Swagger UI page served at /docs; it renders /openapi.json
+//
+//go:embed swagger-ui.html
+var swaggerUIPage []byte
+
+// apiObject describes a free-form JSON object by its property names and OpenAPI types.
+// It documents the gin.H bodies handlers build inline.
+type apiObject map[string]string
+
+// apiParam is a query parameter of an operation
+type apiParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// apiOperation documents one route. Request and Response hold a value of the body type,
+// or nil when the operation has no body.
+type apiOperation struct {
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Query    []apiParam
+	Request  interface{}
+	Status   int
+	Response interface{}
+}
+
+// messageBody is the body of operations that only confirm they succeeded
+var messageBody = apiObject{"message": "string"}
+
+// apiOperations is the API contract. Every route registered on the engine must be listed
+// here and vice versa; checkRouteDrift enforces it at startup.
+var apiOperations = []apiOperation{
+	{http.MethodGet, "/openapi.json", "docs", "OpenAPI document of this API", nil, nil, http.StatusOK, apiObject{"openapi": "string", "info": "object", "paths": "object", "components": "object"}},
+	{http.MethodGet, "/docs", "docs", "Swagger UI for the OpenAPI document", nil, nil, http.StatusOK, nil},
+
+	{http.MethodPost, "/teams", "teams", "Create a team", nil, CreateTeamRequest{}, http.StatusCreated, TeamResponse{}},
+	{http.MethodGet, "/teams", "teams", "List the teams that are not part of a league", nil, nil, http.StatusOK, []TeamResponse{}},
+	{http.MethodGet, "/teams/:id", "teams", "Fetch a team", nil, nil, http.StatusOK, TeamResponse{}},
+
+	{http.MethodGet, "/contests", "contests", "List the lobby, one row per contest series", nil, nil, http.StatusOK, []LobbyContestResponse{}},
+	{http.MethodPost, "/contests", "contests", "Create a contest", nil, CreateContestRequest{}, http.StatusCreated, ContestResponse{}},
+	{http.MethodGet, "/contests/:id", "contests", "Fetch a contest", nil, nil, http.StatusOK, ContestResponse{}},
+	{http.MethodPut, "/contests/:id/slots", "contests", "Change the total number of slots of a contest", nil, ContestSlotsChange{}, http.StatusOK, messageBody},
+	{http.MethodPost, "/contests/enter", "contests", "Enter a contest with a lineup, paying its entry fee", nil, ContestEntry{}, http.StatusCreated, apiObject{"message": "string", "entry_id": "integer"}},
+	{http.MethodPut, "/contests/change", "contests", "Move one of the user's entries to another contest", nil, ContestChange{}, http.StatusOK, messageBody},
+	{http.MethodDelete, "/contests/leave/:contestID/:entryID", "contests", "Leave a contest with one entry and get the entry fee back", nil, nil, http.StatusOK, messageBody},
+	{http.MethodGet, "/contests/:id/leaderboard", "contests", "Rank a contest's entries by fantasy points", nil, nil, http.StatusOK, []LeaderboardEntry{}},
+
+	{http.MethodGet, "/me/entries", "entries", "List the current user's entries", nil, nil, http.StatusOK, []Entry{}},
+	{http.MethodGet, "/me/entries/:id/lineup", "entries", "Show an entry's lineup with lock state, roles and points", nil, nil, http.StatusOK, apiObject{"entry_id": "integer", "server_time": "string", "players": "array", "roles": "object", "points": "number"}},
+	{http.MethodPut, "/contests/lineup", "entries", "Edit the lineup of an entry", nil, LineupChange{}, http.StatusOK, messageBody},
+
+	{http.MethodPost, "/contests/:id/waitlist", "waitlist", "Join the waitlist of a full contest", nil, WaitlistJoin{}, http.StatusCreated, apiObject{"message": "string", "waitlist_id": "integer"}},
+	{http.MethodGet, "/me/waitlist", "waitlist", "List the current user's waitlist spots", nil, nil, http.StatusOK, []WaitlistSpot{}},
+	{http.MethodPost, "/waitlist/:id/confirm", "waitlist", "Confirm a held slot and enter the contest", nil, nil, http.StatusCreated, apiObject{"message": "string", "entry_id": "integer"}},
+	{http.MethodDelete, "/waitlist/:id", "waitlist", "Leave a waitlist", nil, nil, http.StatusOK, messageBody},
+
+	{http.MethodPost, "/contests/private", "private contests", "Create a private contest and its invite", nil, PrivateContestRequest{}, http.StatusCreated, apiObject{"message": "string", "contest_id": "integer", "invite_code": "string", "invite_link": "string"}},
+	{http.MethodGet, "/contests/invite/:code", "private contests", "Preview a private contest from its invite code", nil, nil, http.StatusOK, ContestInvite{}},
+	{http.MethodPost, "/contests/invite/:code/enter", "private contests", "Enter a private contest through its invite", nil, InviteEntry{}, http.StatusCreated, apiObject{"message": "string", "entry_id": "integer"}},
+	{http.MethodDelete, "/contests/:id/entries/:entryID", "private contests", "Remove an entry from a private contest", nil, nil, http.StatusOK, messageBody},
+	{http.MethodPost, "/contests/:id/lock", "private contests", "Lock a private contest to new entries", nil, nil, http.StatusOK, messageBody},
+	{http.MethodPost, "/contests/:id/unlock", "private contests", "Unlock a private contest", nil, nil, http.StatusOK, messageBody},
+	{http.MethodPut, "/contests/:id/rules", "private contests", "Change the rules of a private contest before it starts", nil, ContestRulesChange{}, http.StatusOK, messageBody},
+
+	{http.MethodPost, "/h2h/challenges", "h2h", "Post a head-to-head challenge or match an open one", nil, ChallengeRequest{}, http.StatusCreated, Challenge{}},
+	{http.MethodPost, "/h2h/challenges/:id/accept", "h2h", "Accept a head-to-head challenge", nil, ChallengeAccept{}, http.StatusOK, Challenge{}},
+	{http.MethodDelete, "/h2h/challenges/:id", "h2h", "Cancel an open challenge", nil, nil, http.StatusOK, messageBody},
+
+	{http.MethodPost, "/contest-templates", "templates", "Create a contest template", nil, ContestTemplate{}, http.StatusCreated, apiObject{"message": "string", "template_id": "integer"}},
+	{http.MethodGet, "/contest-templates", "templates", "List contest templates", nil, nil, http.StatusOK, []ContestTemplate{}},
+	{http.MethodGet, "/contest-templates/:id/preview", "templates", "Preview the contests a template will create", []apiParam{{"days", "integer", "Number of days to preview"}}, nil, http.StatusOK, []ContestOccurrence{}},
+	{http.MethodPost, "/contest-templates/generate", "templates", "Create the contests due from every active template", nil, nil, http.StatusOK, apiObject{"message": "string", "created": "integer"}},
+
+	{http.MethodGet, "/finance/overlays", "finance", "List prize overlays paid on guaranteed contests", []apiParam{{"from", "string", "Start of the period, RFC 3339"}, {"to", "string", "End of the period, RFC 3339"}}, nil, http.StatusOK, apiObject{"overlays": "array", "total_overlay": "number"}},
+	{http.MethodGet, "/me/notifications", "notifications", "List the current user's notifications", nil, nil, http.StatusOK, []Notification{}},
+	{http.MethodPost, "/graphql", "graphql", "Run a GraphQL query over contests, teams, users, entries and leaderboards", nil, GraphQLRequest{}, http.StatusOK, apiObject{"data": "object", "errors": "array"}},
+	{http.MethodGet, "/graphql", "graphql", "Run a GraphQL query sent in the URL, e.g. a persisted query", []apiParam{{"query", "string", "The query"}, {"operationName", "string", "The operation to run"}, {"variables", "string", "The variables, as JSON"}, {"extensions", "string", "The extensions, as JSON, e.g. the persisted query hash"}}, nil, http.StatusOK, apiObject{"data": "object", "errors": "array"}},
+	{http.MethodPut, "/games/:id/scores", "scoring", "Record the fantasy points players scored in a game", nil, []PlayerScore{}, http.StatusOK, messageBody},
+
+	{http.MethodPost, "/webhooks", "webhooks", "Subscribe a public URL to contest and entry events, for partner accounts; the response holds the signing secret", nil, WebhookRequest{}, http.StatusCreated, WebhookSubscription{}},
+	{http.MethodGet, "/webhooks", "webhooks", "List the current user's webhook subscriptions", nil, nil, http.StatusOK, []WebhookSubscription{}},
+	{http.MethodDelete, "/webhooks/:id", "webhooks", "Delete a webhook subscription", nil, nil, http.StatusOK, messageBody},
+	{http.MethodGet, "/webhooks/:id/deliveries", "webhooks", "List a subscription's deliveries, newest first", []apiParam{{"status", "string", "Only deliveries with this status: pending, delivered or dead"}}, nil, http.StatusOK, []WebhookDelivery{}},
+	{http.MethodGet, "/webhooks/:id/deliveries/:deliveryID/attempts", "webhooks", "List the attempts made at sending a delivery", nil, nil, http.StatusOK, []WebhookAttempt{}},
+	{http.MethodPost, "/webhooks/:id/deliveries/:deliveryID/replay", "webhooks", "Send a delivered or dead delivery again", nil, nil, http.StatusAccepted, messageBody},
+
+	{http.MethodPost, "/leagues", "leagues", "Create a league", nil, LeagueRequest{}, http.StatusCreated, apiObject{"message": "string", "league_id": "integer"}},
+	{http.MethodGet, "/leagues/:id", "leagues", "Fetch a league and its teams", nil, nil, http.StatusOK, League{}},
+	{http.MethodPost, "/leagues/:id/teams", "leagues", "Join a league with a new team", nil, LeagueTeamRequest{}, http.StatusCreated, apiObject{"message": "string", "team_id": "integer"}},
+
+	{http.MethodPost, "/leagues/:id/drafts", "drafts", "Schedule a league draft", nil, DraftRequest{}, http.StatusCreated, apiObject{"message": "string", "draft_id": "integer"}},
+	{http.MethodGet, "/drafts/:id", "drafts", "Fetch a draft with its order and picks", nil, nil, http.StatusOK, Draft{}},
+	{http.MethodPost, "/drafts/:id/start", "drafts", "Start a draft", nil, nil, http.StatusOK, messageBody},
+	{http.MethodPost, "/drafts/:id/picks", "drafts", "Make a pick", nil, DraftPickRequest{}, http.StatusCreated, DraftPick{}},
+	{http.MethodPut, "/drafts/:id/queue", "drafts", "Replace the team's draft queue", nil, DraftQueue{}, http.StatusOK, messageBody},
+	{http.MethodGet, "/drafts/:id/room", "drafts", "Join the live draft room over a WebSocket", nil, nil, http.StatusSwitchingProtocols, nil},
+	{http.MethodPost, "/drafts/:id/nominations", "auctions", "Nominate a player in an auction draft", nil, NominationRequest{}, http.StatusCreated, AuctionLot{}},
+	{http.MethodPost, "/drafts/:id/bids", "auctions", "Bid on the player up for auction", nil, BidRequest{}, http.StatusOK, AuctionLot{}},
+	{http.MethodGet, "/drafts/:id/bids", "auctions", "List the auction bid log", nil, nil, http.StatusOK, []DraftBid{}},
+
+	{http.MethodPut, "/leagues/:id/season", "seasons", "Set up the league season", nil, SeasonSettings{}, http.StatusOK, messageBody},
+	{http.MethodPost, "/leagues/:id/schedule", "seasons", "Generate the round-robin schedule", nil, nil, http.StatusCreated, messageBody},
+	{http.MethodGet, "/leagues/:id/matchups", "seasons", "List matchups", []apiParam{{"week", "integer", "Only return this week's matchups"}}, nil, http.StatusOK, []Matchup{}},
+	{http.MethodPut, "/leagues/:id/matchups/:matchupID", "seasons", "Score a matchup", nil, MatchupScore{}, http.StatusOK, messageBody},
+	{http.MethodGet, "/leagues/:id/standings", "seasons", "Fetch the standings", nil, nil, http.StatusOK, []Standing{}},
+	{http.MethodPost, "/leagues/:id/playoffs", "seasons", "Start the playoffs", nil, nil, http.StatusCreated, messageBody},
+
+	{http.MethodPut, "/leagues/:id/trade-settings", "trades", "Change the league's trade review and veto settings", nil, TradeSettings{}, http.StatusOK, messageBody},
+	{http.MethodPost, "/leagues/:id/trades", "trades", "Propose a trade", nil, TradeProposal{}, http.StatusCreated, apiObject{"message": "string", "trade_id": "integer"}},
+	{http.MethodGet, "/leagues/:id/trades", "trades", "List the league's trades", nil, nil, http.StatusOK, []Trade{}},
+	{http.MethodPost, "/leagues/:id/trades/:tradeID/accept", "trades", "Accept a trade", nil, nil, http.StatusOK, messageBody},
+	{http.MethodPost, "/leagues/:id/trades/:tradeID/reject", "trades", "Reject a trade", nil, nil, http.StatusOK, messageBody},
+	{http.MethodDelete, "/leagues/:id/trades/:tradeID", "trades", "Cancel a proposed trade", nil, nil, http.StatusOK, messageBody},
+	{http.MethodPost, "/leagues/:id/trades/:tradeID/veto", "trades", "Veto a trade under review", nil, nil, http.StatusOK, messageBody},
+	{http.MethodPost, "/leagues/:id/trades/:tradeID/counter", "trades", "Counter a trade with a new proposal", nil, TradeProposal{}, http.StatusCreated, apiObject{"message": "string", "trade_id": "integer"}},
+
+	{http.MethodPut, "/leagues/:id/waivers", "waivers", "Change the league's waiver settings", nil, WaiverSettings{}, http.StatusOK, messageBody},
+	{http.MethodPost, "/leagues/:id/drops", "waivers", "Drop a player from the user's team", nil, RosterMove{}, http.StatusOK, messageBody},
+	{http.MethodPost, "/leagues/:id/adds", "waivers", "Add a free agent to the user's team", nil, RosterMove{}, http.StatusOK, messageBody},
+	{http.MethodPost, "/leagues/:id/claims", "waivers", "Claim a player on waivers", nil, WaiverClaimRequest{}, http.StatusCreated, apiObject{"message": "string", "claim_id": "integer"}},
+	{http.MethodGet, "/leagues/:id/claims", "waivers", "List the team's waiver claims", nil, nil, http.StatusOK, []WaiverClaim{}},
+	{http.MethodDelete, "/leagues/:id/claims/:claimID", "waivers", "Cancel a pending waiver claim", nil, nil, http.StatusOK, messageBody},
+}
+
+func setupOpenAPIRoutes(r *gin.Engine) {
+	spec, err := buildOpenAPISpec()
+	if err != nil {
+		panic(err)
+	}
+
+	// Route to fetch the OpenAPI document
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", spec)
+	})
+
+	// Route to browse the API with Swagger UI
+	r.GET("/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerUIPage)
+	})
+}
+
+// checkRouteDrift compares the routes registered on the engine with apiOperations and
+// reports any route missing from either side
+func checkRouteDrift(routes gin.RoutesInfo) error {
+	documented := make(map[string]bool, len(apiOperations))
+	for _, op := range apiOperations {
+		documented[op.Method+" "+op.Path] = true
+	}
+
+	registered := make(map[string]bool, len(routes))
+	var undocumented []string
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if !documented[key] {
+			undocumented = append(undocumented, key)
+		}
+	}
+
+	var unserved []string
+	for key := range documented {
+		if !registered[key] {
+			unserved = append(unserved, key)
+		}
+	}
+
+	if len(undocumented) == 0 && len(unserved) == 0 {
+		return nil
+	}
+
+	sort.Strings(undocumented)
+	sort.Strings(unserved)
+	return fmt.Errorf("the OpenAPI operations and the routes have drifted: undocumented routes %v, documented routes not served %v", undocumented, unserved)
+}
+
+// buildOpenAPISpec renders apiOperations as an OpenAPI 3 document. Body schemas are
+// generated from the Go types: JSON tags name the properties and binding tags add the
+// required fields and bounds.
+func buildOpenAPISpec() ([]byte, error) {
+	schemas := openAPISchemas{}
+	paths := map[string]map[string]interface{}{}
+
+	for _, op := range apiOperations {
+		path, params := openAPIPath(op.Path)
+		for _, q := range op.Query {
+			params = append(params, map[string]interface{}{
+				"name":        q.Name,
+				"in":          "query",
+				"description": q.Description,
+				"schema":      map[string]interface{}{"type": q.Type},
+			})
+		}
+		if op.Method != http.MethodGet {
+			params = append(params, map[string]interface{}{"$ref": "#/components/parameters/IdempotencyKey"})
+		}
+
+		success := map[string]interface{}{"description": http.StatusText(op.Status)}
+		if op.Response != nil {
+			success["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schemas.of(op.Response)},
+			}
+		}
+
+		operation := map[string]interface{}{
+			"tags":        []string{op.Tag},
+			"summary":     op.Summary,
+			"operationId": operationID(op),
+			"responses": map[string]interface{}{
+				strconv.Itoa(op.Status): success,
+				"default": map[string]interface{}{
+					"description": "Error",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": schemas.of(errorResponse{})},
+					},
+				},
+			},
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+		if op.Request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": schemas.of(op.Request)},
+				},
+			}
+		}
+
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(op.Method)] = operation
+	}
+
+	spec := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Fantasy API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"parameters": map[string]interface{}{
+				"IdempotencyKey": map[string]interface{}{
+					"name":        idempotencyHeader,
+					"in":          "header",
+					"description": "Makes the request safe to retry; a retry with the same key replays the first response. Requires X-User-ID.",
+					"schema":      map[string]interface{}{"type": "string", "maxLength": maxIdempotencyKeyLength},
+				},
+			},
+			"securitySchemes": map[string]interface{}{
+				"userID": map[string]interface{}{"type": "apiKey", "in": "header", "name": userIDHeader},
+			},
+		},
+		"security": []map[string][]string{{"userID": {}}},
+	}
+
+	return json.MarshalIndent(spec, "", "  ")
+}
+
+// openAPIPath converts a gin path such as /leagues/:id into /leagues/{id} and returns
+// its path parameters
+func openAPIPath(ginPath string) (string, []map[string]interface{}) {
+	segments := strings.Split(ginPath, "/")
+	var params []map[string]interface{}
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+
+		name := segment[1:]
+		schema := map[string]interface{}{"type": "integer"}
+		if name == "code" {
+			schema = map[string]interface{}{"type": "string"}
+		}
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   schema,
+		})
+		segments[i] = "{" + name + "}"
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// operationID derives a stable operation ID such as getLeaguesIdStandings from the route
+func operationID(op apiOperation) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, part := range strings.FieldsFunc(op.Path, func(r rune) bool { return r == '/' || r == ':' || r == '-' || r == '.' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// openAPISchemas collects the named schemas referenced from the document
+type openAPISchemas map[string]interface{}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// of returns the schema of v's type, registering named struct types as components
+func (s openAPISchemas) of(v interface{}) interface{} {
+	if obj, ok := v.(apiObject); ok {
+		props := map[string]interface{}{}
+		for name, typ := range obj {
+			props[name] = map[string]interface{}{"type": typ}
+		}
+		return map[string]interface{}{"type": "object", "properties": props}
+	}
+
+	return s.typeSchema(reflect.TypeOf(v))
+}
+
+func (s openAPISchemas) typeSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		schema := s.typeSchema(t.Elem())
+		if _, ok := schema["$ref"]; ok {
+			return map[string]interface{}{"allOf": []interface{}{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": s.typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": s.typeSchema(t.Elem())}
+	case reflect.Struct:
+		name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+		if _, ok := s[name]; !ok {
+			// Register the name first so self-referencing types terminate
+			s[name] = map[string]interface{}{}
+			s[name] = s.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+
+	return map[string]interface{}{}
+}
+
+func (s openAPISchemas) structSchema(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	var required []string
+	s.addFields(t, props, &required)
+
+	schema := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the JSON properties of t's fields, flattening embedded structs the way
+// encoding/json does
+func (s openAPISchemas) addFields(t reflect.Type, props map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.addFields(embedded, props, required)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := jsonFieldName(field)
+		if name == "" {
+			continue
+		}
+
+		schema := s.typeSchema(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+			key, value, _ := strings.Cut(rule, "=")
+			n, err := strconv.ParseFloat(value, 64)
+			switch {
+			case key == "required":
+				*required = append(*required, name)
+			case err != nil:
+			case (key == "min" || key == "gte") && field.Type.Kind() == reflect.String:
+				schema["minLength"] = int(n)
+			case (key == "max" || key == "lte") && field.Type.Kind() == reflect.String:
+				schema["maxLength"] = int(n)
+			case key == "min" || key == "gte":
+				schema["minimum"] = n
+			case key == "max" || key == "lte":
+				schema["maximum"] = n
+			}
+		}
+
+		props[name] = schema
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRoutesMatchOpenAPIOperations(t *testing.T) {
+	// The routes are only registered here, so the database is never dialled
+	db, err := sql.Open("mysql", "test:test@tcp(127.0.0.1:3306)/fantasy_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	r := newRouter(db, newDraftManager(db))
+	if err := checkRouteDrift(r.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenAPISpecRoundTrip(t *testing.T) {
+	raw, err := buildOpenAPISpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var spec struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components map[string]map[string]json.RawMessage `json:"components"`
+	}
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		t.Fatalf("the document is not valid JSON: %v", err)
+	}
+	if spec.OpenAPI != "3.0.3" {
+		t.Errorf("openapi = %q, want 3.0.3", spec.OpenAPI)
+	}
+
+	operationIDs := map[string]string{}
+	for _, op := range apiOperations {
+		path, _ := openAPIPath(op.Path)
+		body, ok := spec.Paths[path][strings.ToLower(op.Method)]
+		if !ok {
+			t.Errorf("%s %s is missing from the document", op.Method, op.Path)
+			continue
+		}
+
+		var operation struct {
+			OperationID string                     `json:"operationId"`
+			Responses   map[string]json.RawMessage `json:"responses"`
+		}
+		if err := json.Unmarshal(body, &operation); err != nil {
+			t.Errorf("%s %s: %v", op.Method, op.Path, err)
+			continue
+		}
+		if prev, ok := operationIDs[operation.OperationID]; ok {
+			t.Errorf("%s %s and %s share the operation ID %q", op.Method, op.Path, prev, operation.OperationID)
+		}
+		operationIDs[operation.OperationID] = op.Method + " " + op.Path
+		if len(operation.Responses) == 0 {
+			t.Errorf("%s %s documents no responses", op.Method, op.Path)
+		}
+	}
+
+	// Every reference must point at a component the document defines
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range openAPIRefs(doc) {
+		parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+		if !strings.HasPrefix(ref, "#/components/") || len(parts) != 2 {
+			t.Errorf("unexpected reference %q", ref)
+			continue
+		}
+		if _, ok := spec.Components[parts[0]][parts[1]]; !ok {
+			t.Errorf("reference %q does not resolve", ref)
+		}
+	}
+}
+
+// openAPIRefs returns every $ref found in a decoded JSON document
+func openAPIRefs(v interface{}) []string {
+	var refs []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				refs = append(refs, ref)
+				continue
+			}
+			refs = append(refs, openAPIRefs(child)...)
+		}
+	case []interface{}:
+		for _, child := range v {
+			refs = append(refs, openAPIRefs(child)...)
+		}
+	}
+	return refs
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+var errPlayerNotFound = notFoundError("player_not_found", "Player not found")
+
+// Player is a real-world athlete that can be picked in lineups
+type Player struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Position string `json:"position"`
+	GameID   int    `json:"game_id"`
+}
+
+// LineupPlayer is a player in an entry's lineup together with its lock state. A player
+// is locked from the kickoff of their real-world game onwards.
+type LineupPlayer struct {
+	Player
+	Kickoff *time.Time `json:"kickoff,omitempty"`
+	Locked  bool       `json:"locked"`
+}
+
+// placeholders returns "?, ?, ..." with n placeholders for an IN clause
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// getLineupPlayers returns the given players with their game kickoff and whether they
+// are locked at now. Players without a scheduled game are never locked.
+func getLineupPlayers(q queryer, playerIDs []int, now time.Time) ([]LineupPlayer, error) {
+	players := []LineupPlayer{}
+	if len(playerIDs) == 0 {
+		return players, nil
+	}
+
+	args := make([]interface{}, len(playerIDs))
+	for i, id := range playerIDs {
+		args[i] = id
+	}
+
+	rows, err := q.Query(
+		"SELECT p.id, p.name, p.position, IFNULL(p.game_id, 0), g.kickoff FROM player p LEFT JOIN game g ON g.id = p.game_id WHERE p.id IN ("+placeholders(len(playerIDs))+") ORDER BY p.id",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lp LineupPlayer
+		var kickoff sql.NullTime
+		if err := rows.Scan(&lp.ID, &lp.Name, &lp.Position, &lp.GameID, &kickoff); err != nil {
+			return nil, err
+		}
+		if kickoff.Valid {
+			lp.Kickoff = &kickoff.Time
+			lp.Locked = !now.Before(kickoff.Time)
+		}
+		players = append(players, lp)
+	}
+
+	return players, rows.Err()
+}
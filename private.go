@@ -0,0 +1,527 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// Number of random bytes behind an invite code; 5 bytes encode to 8 base32 characters
+	inviteCodeBytes = 5
+
+	// Base of the shareable link handed out for private contests
+	inviteLinkBaseURL = "https://fantasy.example.com/join/"
+)
+
+// PrivateContestRequest is the request body used by a commissioner to create a private contest
+type PrivateContestRequest struct {
+	Name              string    `json:"name" binding:"required,min=3,max=100,name_charset"`
+	Prize             float64   `json:"prize" binding:"gte=0"`
+	EntryFee          float64   `json:"entry_fee" binding:"gte=0"`
+	TotalSlots        int       `json:"total_slots" binding:"required,gte=2,lte=100000"`
+	MaxEntriesPerUser int       `json:"max_entries_per_user" binding:"omitempty,gte=1,lte=150"`
+	RosterSize        int       `json:"roster_size" binding:"gte=0,lte=50"`
+	StartDate         time.Time `json:"start_date" binding:"required,future"`
+	EndDate           time.Time `json:"end_date" binding:"required"`
+	Password          string    `json:"password,omitempty" binding:"omitempty,max=72"`
+}
+
+// ContestRulesChange is the request body used by a commissioner to edit a private
+// contest before it starts. Fields left out are not changed.
+type ContestRulesChange struct {
+	Name              *string    `json:"name" binding:"omitempty,min=3,max=100,name_charset"`
+	Prize             *float64   `json:"prize" binding:"omitempty,gte=0"`
+	MaxEntriesPerUser *int       `json:"max_entries_per_user" binding:"omitempty,gte=1,lte=150"`
+	RosterSize        *int       `json:"roster_size" binding:"omitempty,gte=0,lte=50"`
+	StartDate         *time.Time `json:"start_date" binding:"omitempty,future"`
+	EndDate           *time.Time `json:"end_date"`
+	Password          *string    `json:"password" binding:"omitempty,max=72"`
+}
+
+// InviteEntry is the request body used to enter a private contest through its invite
+type InviteEntry struct {
+	Lineup   []int       `json:"lineup"`
+	Roles    LineupRoles `json:"roles,omitempty"`
+	Password string      `json:"password,omitempty"`
+}
+
+// ContestInvite is what a user sees when opening an invite link
+type ContestInvite struct {
+	ContestID        int       `json:"contest_id"`
+	Name             string    `json:"name"`
+	CommissionerID   int       `json:"commissioner_id"`
+	EntryFee         float64   `json:"entry_fee"`
+	RemainingSlots   int       `json:"remaining_slots"`
+	StartDate        time.Time `json:"start_date"`
+	RequiresPassword bool      `json:"requires_password"`
+}
+
+func setupPrivateContestRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to create a private contest owned by the current user
+	r.POST("/contests/private", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		var req PrivateContestRequest
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+
+		contestID, inviteCode, err := createPrivateContest(db, userID, req)
+		if err != nil {
+			c.Error(apiError(err, "Failed to create contest"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"message":     "Contest created successfully",
+			"contest_id":  contestID,
+			"invite_code": inviteCode,
+			"invite_link": inviteLinkBaseURL + inviteCode,
+		})
+	})
+
+	// Route to preview a private contest from its invite code
+	r.GET("/contests/invite/:code", func(c *gin.Context) {
+		invite, err := getContestInvite(db, c.Param("code"))
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch the invite"))
+			return
+		}
+
+		c.JSON(http.StatusOK, invite)
+	})
+
+	// Route to enter a private contest through its invite code
+	r.POST("/contests/invite/:code/enter", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		var req InviteEntry
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		invite, err := getContestInvite(db, c.Param("code"))
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch the invite"))
+			return
+		}
+
+		entryID, err := enterContest(db, ContestEntry{
+			ContestID:  invite.ContestID,
+			UserID:     userID,
+			Lineup:     req.Lineup,
+			Roles:      req.Roles,
+			InviteCode: c.Param("code"),
+			Password:   req.Password,
+		})
+		if err != nil {
+			c.Error(apiError(err, "Failed to enter contest"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Entered contest successfully", "entry_id": entryID})
+	})
+
+	// Route for the commissioner to remove an entry from their contest
+	r.DELETE("/contests/:id/entries/:entryID", func(c *gin.Context) {
+		userID, contestID, ok := commissionerRequest(c)
+		if !ok {
+			return
+		}
+
+		entryID, err := strconv.Atoi(c.Param("entryID"))
+		if err != nil {
+			c.Error(invalidParam("entryID", "Invalid entry ID"))
+			return
+		}
+
+		if err := kickEntry(db, userID, contestID, entryID); err != nil {
+			c.Error(apiError(err, "Failed to remove the entry"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Entry removed successfully"})
+	})
+
+	// Routes for the commissioner to stop and resume new entries
+	r.POST("/contests/:id/lock", func(c *gin.Context) {
+		userID, contestID, ok := commissionerRequest(c)
+		if !ok {
+			return
+		}
+
+		if err := setContestLocked(db, userID, contestID, true); err != nil {
+			c.Error(apiError(err, "Failed to lock the contest"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Contest locked successfully"})
+	})
+
+	r.POST("/contests/:id/unlock", func(c *gin.Context) {
+		userID, contestID, ok := commissionerRequest(c)
+		if !ok {
+			return
+		}
+
+		if err := setContestLocked(db, userID, contestID, false); err != nil {
+			c.Error(apiError(err, "Failed to unlock the contest"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Contest unlocked successfully"})
+	})
+
+	// Route for the commissioner to edit the contest rules before it starts
+	r.PUT("/contests/:id/rules", func(c *gin.Context) {
+		userID, contestID, ok := commissionerRequest(c)
+		if !ok {
+			return
+		}
+
+		var change ContestRulesChange
+		if err := bindJSON(c, &change); err != nil {
+			c.Error(err)
+			return
+		}
+
+		if err := updateContestRules(db, userID, contestID, change); err != nil {
+			c.Error(apiError(err, "Failed to update the contest rules"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Contest rules updated successfully"})
+	})
+}
+
+// commissionerRequest reads the current user and the contest ID of a commissioner
+// route, writing the error response itself when either is missing
+func commissionerRequest(c *gin.Context) (int, int, bool) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return 0, 0, false
+	}
+
+	contestID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(invalidParam("id", "Invalid contest ID"))
+		return 0, 0, false
+	}
+
+	return userID, contestID, true
+}
+
+// generateInviteCode returns a random, URL-safe invite code
+func generateInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf), nil
+}
+
+// createPrivateContest creates a contest that is hidden from the lobby and can only
+// be entered with its invite code, and the password if one is set
+func createPrivateContest(db *sql.DB, commissionerID int, req PrivateContestRequest) (int, string, error) {
+	if req.MaxEntriesPerUser <= 0 {
+		req.MaxEntriesPerUser = defaultMaxEntriesPerUser
+	}
+
+	inviteCode, err := generateInviteCode()
+	if err != nil {
+		return 0, "", err
+	}
+
+	var passwordHash sql.NullString
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return 0, "", err
+		}
+		passwordHash = sql.NullString{String: string(hash), Valid: true}
+	}
+
+	res, err := db.Exec(
+		"INSERT INTO contest (name, prize, entry_fee, total_slots, remaining_slots, max_entries_per_user, roster_size, start_date, end_date, status, active_date, is_private, commissioner_id, invite_code, password_hash, is_locked, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 'active', NOW(), TRUE, ?, ?, ?, FALSE, NOW())",
+		req.Name, req.Prize, req.EntryFee, req.TotalSlots, req.TotalSlots, req.MaxEntriesPerUser, req.RosterSize, req.StartDate, req.EndDate, commissionerID, inviteCode, passwordHash,
+	)
+	if err != nil {
+		return 0, "", err
+	}
+
+	contestID, err := res.LastInsertId()
+	if err != nil {
+		return 0, "", err
+	}
+
+	return int(contestID), inviteCode, nil
+}
+
+// getContestInvite looks up the private contest behind an invite code
+func getContestInvite(db *sql.DB, inviteCode string) (*ContestInvite, error) {
+	var invite ContestInvite
+	err := db.QueryRow(
+		"SELECT id, name, IFNULL(commissioner_id, 0), entry_fee, remaining_slots, start_date, password_hash IS NOT NULL FROM contest WHERE invite_code = ? AND is_private = TRUE",
+		strings.ToUpper(inviteCode),
+	).Scan(&invite.ContestID, &invite.Name, &invite.CommissionerID, &invite.EntryFee, &invite.RemainingSlots, &invite.StartDate, &invite.RequiresPassword)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("invite_not_found", "Invite not found")
+		}
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+// checkPrivateContestAccess verifies the invite code and password given with an entry
+func checkPrivateContestAccess(inviteCode, passwordHash string, entry ContestEntry) error {
+	if inviteCode == "" || !strings.EqualFold(inviteCode, entry.InviteCode) {
+		return forbiddenError("invite_required", "A valid invite code is required to enter this contest")
+	}
+
+	if passwordHash != "" && bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(entry.Password)) != nil {
+		return forbiddenError("incorrect_password", "Incorrect contest password")
+	}
+
+	return nil
+}
+
+// lockCommissionedContest locks the contest row and checks that userID is its
+// commissioner and that the contest has not started yet
+func lockCommissionedContest(tx *sql.Tx, userID int, contestID int) error {
+	var commissionerID int
+	var startDate time.Time
+	err := tx.QueryRow("SELECT IFNULL(commissioner_id, 0), start_date FROM contest WHERE id = ? FOR UPDATE", contestID).Scan(&commissionerID, &startDate)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return errContestNotFound
+		}
+		return err
+	}
+
+	if commissionerID == 0 || commissionerID != userID {
+		return forbiddenError("not_commissioner", "Only the commissioner can manage this contest")
+	}
+
+	if !time.Now().Before(startDate) {
+		return errContestStarted
+	}
+
+	return nil
+}
+
+// kickEntry lets the commissioner remove an entry; the entrant is refunded and notified
+func kickEntry(db *sql.DB, commissionerID int, contestID int, entryID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := lockCommissionedContest(tx, commissionerID, contestID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var entrantID int
+	err = tx.QueryRow("SELECT user_id FROM user_contest WHERE id = ? AND contest_id = ?", entryID, contestID).Scan(&entrantID)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return errEntryNotFound
+		}
+		return err
+	}
+
+	if err := removeEntry(tx, entrantID, contestID, entryID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	message := fmt.Sprintf("Your entry in contest %d was removed by the commissioner and your entry fee was refunded.", contestID)
+	if err := notifyUser(tx, entrantID, notificationEntryRemoved, message); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// setContestLocked lets the commissioner stop or resume new entries
+func setContestLocked(db *sql.DB, commissionerID int, contestID int, locked bool) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := lockCommissionedContest(tx, commissionerID, contestID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE contest SET is_locked = ? WHERE id = ?", locked, contestID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// updateContestRules applies the commissioner's rule changes to a contest that has not started
+func updateContestRules(db *sql.DB, commissionerID int, contestID int, change ContestRulesChange) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := lockCommissionedContest(tx, commissionerID, contestID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Entries were made under the current roster size and entry limit, so those are
+	// fixed once the first one is in
+	if change.RosterSize != nil || change.MaxEntriesPerUser != nil {
+		var entries int
+		err = tx.QueryRow("SELECT COUNT(*) FROM user_contest WHERE contest_id = ?", contestID).Scan(&entries)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if entries > 0 {
+			tx.Rollback()
+			return conflictError("contest_has_entries", "The roster size and entries per user cannot change once the contest has entries")
+		}
+	}
+
+	// A date left out keeps its stored value, so check the end against the start the
+	// contest will actually have
+	if change.StartDate != nil || change.EndDate != nil {
+		var startDate, endDate time.Time
+		err = tx.QueryRow("SELECT start_date, end_date FROM contest WHERE id = ?", contestID).Scan(&startDate, &endDate)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if change.StartDate != nil {
+			startDate = *change.StartDate
+		}
+		if change.EndDate != nil {
+			endDate = *change.EndDate
+		}
+		if !endDate.After(startDate) {
+			tx.Rollback()
+			return invalidField("invalid_end_date", "end_date", "The end date must be after the start date")
+		}
+	}
+
+	var sets []string
+	var args []interface{}
+	if change.Name != nil {
+		sets = append(sets, "name = ?")
+		args = append(args, *change.Name)
+	}
+	if change.Prize != nil {
+		sets = append(sets, "prize = ?")
+		args = append(args, *change.Prize)
+	}
+	if change.MaxEntriesPerUser != nil {
+		sets = append(sets, "max_entries_per_user = ?")
+		args = append(args, *change.MaxEntriesPerUser)
+	}
+	if change.RosterSize != nil {
+		sets = append(sets, "roster_size = ?")
+		args = append(args, *change.RosterSize)
+	}
+	if change.StartDate != nil {
+		sets = append(sets, "start_date = ?", "active_date = ?")
+		args = append(args, *change.StartDate, *change.StartDate)
+	}
+	if change.EndDate != nil {
+		sets = append(sets, "end_date = ?")
+		args = append(args, *change.EndDate)
+	}
+	if change.Password != nil {
+		// An empty password removes the password requirement
+		var passwordHash sql.NullString
+		if *change.Password != "" {
+			hash, err := bcrypt.GenerateFromPassword([]byte(*change.Password), bcrypt.DefaultCost)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			passwordHash = sql.NullString{String: string(hash), Valid: true}
+		}
+		sets = append(sets, "password_hash = ?")
+		args = append(args, passwordHash)
+	}
+
+	if len(sets) > 0 {
+		args = append(args, contestID)
+		_, err = tx.Exec("UPDATE contest SET "+strings.Join(sets, ", ")+" WHERE id = ?", args...)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestAPI serves the full router over a fresh test database, the way the client's
+// TestServer is meant to be used
+func newTestAPI(t *testing.T) *httptest.Server {
+	db := newTestDB(t)
+	ts := httptest.NewServer(newRouter(db, newDraftManager(db)))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestCreateContestThroughTheRouter(t *testing.T) {
+	ts := newTestAPI(t)
+
+	start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	body, _ := json.Marshal(gin.H{
+		"name":        "Sunday Main",
+		"prize":       1000,
+		"total_slots": 10,
+		"auto_clone":  true,
+		"max_clones":  3,
+		"start_date":  start,
+		"end_date":    start.Add(3 * time.Hour),
+	})
+
+	post := func() *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/contests", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(userIDHeader, "1")
+		req.Header.Set(idempotencyHeader, "create-sunday-main")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := post()
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("got %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	var created ContestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != contestStatusActive || created.PrizeStructure != defaultPrizeStructure || created.RemainingSlots != 10 {
+		t.Errorf("created %+v, want an active %s contest with 10 open slots", created, defaultPrizeStructure)
+	}
+
+	// Retrying with the same key replays the contest instead of creating a second one
+	replay := post()
+	defer replay.Body.Close()
+	var replayed ContestResponse
+	if err := json.NewDecoder(replay.Body).Decode(&replayed); err != nil {
+		t.Fatal(err)
+	}
+	if replay.Header.Get("Idempotent-Replayed") != "true" || replayed.ID != created.ID {
+		t.Errorf("retry returned contest %d, want the replay of %d", replayed.ID, created.ID)
+	}
+
+	got, err := http.Get(fmt.Sprintf("%s/contests/%d", ts.URL, created.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Body.Close()
+	if got.StatusCode != http.StatusOK {
+		t.Errorf("fetching the contest: got %d, want %d", got.StatusCode, http.StatusOK)
+	}
+}
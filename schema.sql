@@ -0,0 +1,529 @@
+-- Schema changes applied on top of the existing contest, team, users and
+-- user_contest tables. Run in order.
+
+-- Multi-entry contests: every user_contest row is now an entry with its own ID
+-- and lineup, and each contest caps how many entries one user may hold.
+ALTER TABLE contest
+    ADD COLUMN max_entries_per_user INT NOT NULL DEFAULT 1;
+
+ALTER TABLE user_contest
+    DROP PRIMARY KEY,
+    ADD COLUMN id INT NOT NULL AUTO_INCREMENT PRIMARY KEY FIRST,
+    ADD COLUMN created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD INDEX idx_user_contest_user_contest (user_id, contest_id);
+
+CREATE TABLE entry_lineup (
+    entry_id  INT NOT NULL,
+    player_id INT NOT NULL,
+    PRIMARY KEY (entry_id, player_id),
+    FOREIGN KEY (entry_id) REFERENCES user_contest (id) ON DELETE CASCADE
+);
+
+-- Multi-contest participation: membership lives only in user_contest, so the
+-- single selected contest on the user record goes away.
+ALTER TABLE users
+    DROP COLUMN selected_contest_id;
+
+-- Entry fees, lineup rules and the user wallet. Entering charges the fee,
+-- leaving refunds it and switching settles the difference.
+ALTER TABLE contest
+    ADD COLUMN entry_fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN roster_size INT NOT NULL DEFAULT 0;
+
+ALTER TABLE users
+    ADD COLUMN balance DECIMAL(12, 2) NOT NULL DEFAULT 0;
+
+CREATE TABLE wallet_transaction (
+    id         INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id    INT NOT NULL,
+    amount     DECIMAL(12, 2) NOT NULL,
+    reason     VARCHAR(255) NOT NULL,
+    created_at DATETIME NOT NULL,
+    INDEX idx_wallet_transaction_user (user_id, created_at)
+);
+
+-- Contest waitlist: FIFO queue per contest. A promoted spot holds a slot until
+-- hold_expires_at, then either becomes an entry or is released.
+CREATE TABLE contest_waitlist (
+    id              INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    contest_id      INT NOT NULL,
+    user_id         INT NOT NULL,
+    status          VARCHAR(16) NOT NULL,
+    lineup          TEXT NOT NULL,
+    entry_id        INT NULL,
+    promoted_at     DATETIME NULL,
+    hold_expires_at DATETIME NULL,
+    created_at      DATETIME NOT NULL,
+    INDEX idx_contest_waitlist_queue (contest_id, status, created_at),
+    INDEX idx_contest_waitlist_hold (status, hold_expires_at),
+    INDEX idx_contest_waitlist_user (user_id)
+);
+
+CREATE TABLE notification (
+    id         INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id    INT NOT NULL,
+    kind       VARCHAR(32) NOT NULL,
+    message    VARCHAR(512) NOT NULL,
+    is_read    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME NOT NULL,
+    INDEX idx_notification_user (user_id, id)
+);
+
+-- Private contests: created by a commissioner, hidden from the lobby and joined
+-- through an invite code and optional password.
+ALTER TABLE contest
+    ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN commissioner_id INT NULL,
+    ADD COLUMN invite_code VARCHAR(16) NULL,
+    ADD COLUMN password_hash VARCHAR(255) NULL,
+    ADD COLUMN is_locked BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD UNIQUE INDEX idx_contest_invite_code (invite_code),
+    ADD INDEX idx_contest_lobby (is_private, status, start_date);
+
+-- Head-to-head matchmaking: slates group the real-world games contests are
+-- played on, and open challenges wait for an opponent of similar skill.
+CREATE TABLE slate (
+    id          INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    sport       VARCHAR(32) NOT NULL,
+    name        VARCHAR(255) NOT NULL,
+    roster_size INT NOT NULL DEFAULT 0,
+    start_time  DATETIME NOT NULL,
+    end_time    DATETIME NOT NULL,
+    INDEX idx_slate_start (start_time)
+);
+
+ALTER TABLE contest
+    ADD COLUMN slate_id INT NULL,
+    ADD INDEX idx_contest_slate (slate_id);
+
+ALTER TABLE users
+    ADD COLUMN skill_rating INT NOT NULL DEFAULT 1000;
+
+CREATE TABLE h2h_challenge (
+    id              INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id         INT NOT NULL,
+    slate_id        INT NOT NULL,
+    entry_fee       DECIMAL(10, 2) NOT NULL,
+    skill_rating    INT NOT NULL,
+    invited_user_id INT NULL,
+    lineup          TEXT NOT NULL,
+    status          VARCHAR(16) NOT NULL,
+    contest_id      INT NULL,
+    matched_user_id INT NULL,
+    created_at      DATETIME NOT NULL,
+    INDEX idx_h2h_challenge_open (status, slate_id, entry_fee, created_at)
+);
+
+-- Contest templates and recurring generation. Generated contests remember their
+-- template and occurrence so the generator never creates the same one twice.
+CREATE TABLE contest_template (
+    id                   INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name_pattern         VARCHAR(255) NOT NULL,
+    prize                DECIMAL(12, 2) NOT NULL,
+    prize_structure      VARCHAR(32) NOT NULL,
+    entry_fee            DECIMAL(10, 2) NOT NULL,
+    total_slots          INT NOT NULL,
+    max_entries_per_user INT NOT NULL,
+    roster_size          INT NOT NULL,
+    recurrence           VARCHAR(16) NOT NULL,
+    sport                VARCHAR(32) NOT NULL DEFAULT '',
+    start_time           VARCHAR(5) NOT NULL DEFAULT '',
+    cron_expr            VARCHAR(64) NOT NULL DEFAULT '',
+    duration_minutes     INT NOT NULL DEFAULT 0,
+    lead_days            INT NOT NULL DEFAULT 1,
+    active               BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at           DATETIME NOT NULL
+);
+
+ALTER TABLE contest
+    ADD COLUMN prize_structure VARCHAR(32) NOT NULL DEFAULT 'winner_takes_all',
+    ADD COLUMN template_id INT NULL,
+    ADD COLUMN occurrence_key VARCHAR(64) NULL,
+    ADD UNIQUE INDEX idx_contest_template_occurrence (template_id, occurrence_key);
+
+-- Guaranteed contests and minimum fill. At lock time underfilled non-guaranteed
+-- contests are cancelled and refunded; guaranteed ones record their overlay.
+ALTER TABLE contest
+    ADD COLUMN is_guaranteed BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN min_fill_percent INT NOT NULL DEFAULT 0,
+    ADD INDEX idx_contest_status_start (status, start_date);
+
+ALTER TABLE contest_template
+    ADD COLUMN is_guaranteed BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN min_fill_percent INT NOT NULL DEFAULT 0;
+
+CREATE TABLE contest_overlay (
+    contest_id     INT NOT NULL PRIMARY KEY,
+    prize          DECIMAL(12, 2) NOT NULL,
+    entries        INT NOT NULL,
+    collected_fees DECIMAL(12, 2) NOT NULL,
+    overlay        DECIMAL(12, 2) NOT NULL,
+    created_at     DATETIME NOT NULL,
+    INDEX idx_contest_overlay_created (created_at)
+);
+
+-- Auto-clone on fill. Clones point at the original contest through series_id,
+-- which the lobby uses to show a series as one row.
+ALTER TABLE contest
+    ADD COLUMN auto_clone BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN max_clones INT NOT NULL DEFAULT 0,
+    ADD COLUMN series_id INT NULL,
+    ADD COLUMN clone_number INT NOT NULL DEFAULT 0,
+    ADD INDEX idx_contest_series (series_id, clone_number);
+
+ALTER TABLE contest_template
+    ADD COLUMN auto_clone BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN max_clones INT NOT NULL DEFAULT 0;
+
+-- Real-world games and players. A player locks at their game's kickoff; until
+-- then they can still be swapped in or out of a lineup (late swap).
+CREATE TABLE game (
+    id        INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    slate_id  INT NULL,
+    home_team VARCHAR(64) NOT NULL,
+    away_team VARCHAR(64) NOT NULL,
+    kickoff   DATETIME NOT NULL,
+    INDEX idx_game_slate (slate_id),
+    INDEX idx_game_kickoff (kickoff)
+);
+
+CREATE TABLE player (
+    id       INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name     VARCHAR(255) NOT NULL,
+    position VARCHAR(16) NOT NULL,
+    game_id  INT NULL,
+    INDEX idx_player_game (game_id)
+);
+
+-- Season-long leagues and drafts. A league team is owned by one user; drafted
+-- players land on the team's roster. Drafts persist the pick on the clock and
+-- its deadline so the pick timers can resume after a restart.
+CREATE TABLE league (
+    id              INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name            VARCHAR(255) NOT NULL,
+    commissioner_id INT NOT NULL,
+    created_at      DATETIME NOT NULL
+);
+
+ALTER TABLE team
+    ADD COLUMN league_id INT NULL,
+    ADD COLUMN owner_id INT NULL,
+    ADD UNIQUE INDEX idx_team_league_owner (league_id, owner_id);
+
+ALTER TABLE player
+    ADD COLUMN adp_rank INT NULL,
+    ADD INDEX idx_player_adp_rank (adp_rank);
+
+CREATE TABLE team_player (
+    team_id      INT NOT NULL,
+    player_id    INT NOT NULL,
+    acquired_via VARCHAR(16) NOT NULL,
+    acquired_at  DATETIME NOT NULL,
+    PRIMARY KEY (team_id, player_id),
+    INDEX idx_team_player_player (player_id)
+);
+
+CREATE TABLE draft (
+    id            INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    league_id     INT NOT NULL,
+    type          VARCHAR(16) NOT NULL,
+    status        VARCHAR(16) NOT NULL,
+    rounds        INT NOT NULL,
+    pick_seconds  INT NOT NULL,
+    current_pick  INT NOT NULL DEFAULT 0,
+    pick_deadline DATETIME NULL,
+    started_at    DATETIME NULL,
+    completed_at  DATETIME NULL,
+    created_at    DATETIME NOT NULL,
+    UNIQUE INDEX idx_draft_league (league_id),
+    INDEX idx_draft_status (status)
+);
+
+CREATE TABLE draft_order (
+    draft_id INT NOT NULL,
+    slot     INT NOT NULL,
+    team_id  INT NOT NULL,
+    PRIMARY KEY (draft_id, slot),
+    UNIQUE INDEX idx_draft_order_team (draft_id, team_id)
+);
+
+CREATE TABLE draft_pick (
+    draft_id    INT NOT NULL,
+    pick_number INT NOT NULL,
+    round       INT NOT NULL,
+    team_id     INT NOT NULL,
+    player_id   INT NOT NULL,
+    auto        BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  DATETIME NOT NULL,
+    PRIMARY KEY (draft_id, pick_number),
+    UNIQUE INDEX idx_draft_pick_player (draft_id, player_id)
+);
+
+CREATE TABLE draft_queue (
+    draft_id  INT NOT NULL,
+    team_id   INT NOT NULL,
+    player_id INT NOT NULL,
+    position  INT NOT NULL,
+    PRIMARY KEY (draft_id, team_id, player_id),
+    INDEX idx_draft_queue_position (draft_id, team_id, position)
+);
+
+-- Auction drafts. The draft row holds the player up for bidding and the clock;
+-- draft_order holds each team's remaining budget and draft_bid logs every bid,
+-- including rejected ones, for dispute resolution.
+ALTER TABLE draft
+    ADD COLUMN budget INT NOT NULL DEFAULT 0,
+    ADD COLUMN min_bid INT NOT NULL DEFAULT 0,
+    ADD COLUMN bid_seconds INT NOT NULL DEFAULT 0,
+    ADD COLUMN anti_snipe_seconds INT NOT NULL DEFAULT 0,
+    ADD COLUMN lot_player_id INT NULL,
+    ADD COLUMN lot_bid INT NOT NULL DEFAULT 0,
+    ADD COLUMN lot_team_id INT NULL;
+
+ALTER TABLE draft_order
+    ADD COLUMN budget INT NOT NULL DEFAULT 0;
+
+ALTER TABLE draft_pick
+    ADD COLUMN price INT NOT NULL DEFAULT 0;
+
+CREATE TABLE draft_bid (
+    id         INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    draft_id   INT NOT NULL,
+    nomination INT NOT NULL,
+    team_id    INT NOT NULL,
+    player_id  INT NULL,
+    amount     INT NOT NULL,
+    kind       VARCHAR(16) NOT NULL,
+    accepted   BOOLEAN NOT NULL,
+    reason     VARCHAR(255) NULL,
+    created_at DATETIME(3) NOT NULL,
+    INDEX idx_draft_bid_draft (draft_id, nomination)
+);
+
+-- League seasons: a round-robin regular season of weekly head-to-head matchups
+-- followed by a seeded playoff bracket. Playoff matchups carry their round,
+-- bracket slot and seeds; a bye has no away team.
+ALTER TABLE league
+    ADD COLUMN season_start DATE NULL,
+    ADD COLUMN regular_season_weeks INT NOT NULL DEFAULT 14,
+    ADD COLUMN playoff_teams INT NOT NULL DEFAULT 4;
+
+CREATE TABLE matchup (
+    id            INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    league_id     INT NOT NULL,
+    week          INT NOT NULL,
+    home_team_id  INT NOT NULL,
+    away_team_id  INT NULL,
+    home_points   DECIMAL(8, 2) NULL,
+    away_points   DECIMAL(8, 2) NULL,
+    status        VARCHAR(16) NOT NULL,
+    playoff_round INT NOT NULL DEFAULT 0,
+    bracket_slot  INT NOT NULL DEFAULT 0,
+    home_seed     INT NOT NULL DEFAULT 0,
+    away_seed     INT NOT NULL DEFAULT 0,
+    INDEX idx_matchup_league_week (league_id, week),
+    INDEX idx_matchup_league_round (league_id, playoff_round, bracket_slot)
+);
+
+-- Waivers and free agents. A dropped player sits on waivers until the nightly
+-- run after clears_at; claims are resolved by rolling priority, reverse
+-- standings or FAAB blind bids. Unclaimed players become free agents.
+ALTER TABLE league
+    ADD COLUMN waiver_type VARCHAR(32) NOT NULL DEFAULT 'rolling',
+    ADD COLUMN waiver_hours INT NOT NULL DEFAULT 48,
+    ADD COLUMN faab_budget INT NOT NULL DEFAULT 100,
+    ADD COLUMN roster_limit INT NOT NULL DEFAULT 16;
+
+ALTER TABLE team
+    ADD COLUMN waiver_priority INT NOT NULL DEFAULT 0,
+    ADD COLUMN faab_spent INT NOT NULL DEFAULT 0;
+
+CREATE TABLE player_waiver (
+    league_id INT NOT NULL,
+    player_id INT NOT NULL,
+    clears_at DATETIME NOT NULL,
+    PRIMARY KEY (league_id, player_id),
+    INDEX idx_player_waiver_clears (clears_at)
+);
+
+CREATE TABLE waiver_claim (
+    id             INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    league_id      INT NOT NULL,
+    team_id        INT NOT NULL,
+    player_id      INT NOT NULL,
+    drop_player_id INT NULL,
+    bid            INT NOT NULL DEFAULT 0,
+    status         VARCHAR(16) NOT NULL,
+    reason         VARCHAR(255) NULL,
+    created_at     DATETIME NOT NULL,
+    processed_at   DATETIME NULL,
+    INDEX idx_waiver_claim_league_status (league_id, status),
+    INDEX idx_waiver_claim_team (team_id)
+);
+
+-- Trades between league teams. An accepted trade waits out the league's review
+-- period, during which the commissioner or a vote of the other teams can veto
+-- it; rosters and FAAB are checked again when it executes.
+ALTER TABLE league
+    ADD COLUMN trade_review_hours INT NOT NULL DEFAULT 24,
+    ADD COLUMN trade_veto VARCHAR(16) NOT NULL DEFAULT 'commissioner';
+
+CREATE TABLE trade (
+    id               INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    league_id        INT NOT NULL,
+    proposer_team_id INT NOT NULL,
+    receiver_team_id INT NOT NULL,
+    offer_faab       INT NOT NULL DEFAULT 0,
+    request_faab     INT NOT NULL DEFAULT 0,
+    status           VARCHAR(16) NOT NULL,
+    counter_of       INT NULL,
+    review_ends_at   DATETIME NULL,
+    reason           VARCHAR(255) NULL,
+    created_at       DATETIME NOT NULL,
+    INDEX idx_trade_league (league_id, created_at),
+    INDEX idx_trade_review (status, review_ends_at)
+);
+
+CREATE TABLE trade_player (
+    trade_id     INT NOT NULL,
+    player_id    INT NOT NULL,
+    from_team_id INT NOT NULL,
+    PRIMARY KEY (trade_id, player_id)
+);
+
+CREATE TABLE trade_vote (
+    trade_id   INT NOT NULL,
+    team_id    INT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (trade_id, team_id)
+);
+
+-- Captain and vice-captain lineups and player scoring. A lineup player may hold
+-- one role, whose multiplier is applied to the points they score in their game.
+ALTER TABLE entry_lineup
+    ADD COLUMN role VARCHAR(16) NULL;
+
+CREATE TABLE player_score (
+    player_id  INT NOT NULL,
+    game_id    INT NOT NULL,
+    points     DECIMAL(8, 2) NOT NULL,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (player_id, game_id),
+    INDEX idx_player_score_game (game_id)
+);
+
+-- Idempotency keys. The first request with a key stores its response here and
+-- retries with the same key and body replay it until the key expires; a key is
+-- scoped to the user sending it.
+CREATE TABLE idempotency_key (
+    scope           VARCHAR(64) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash    CHAR(64) NOT NULL,
+    status          VARCHAR(16) NOT NULL,
+    response_code   INT NULL,
+    content_type    VARCHAR(255) NULL,
+    response_body   MEDIUMBLOB NULL,
+    created_at      DATETIME NOT NULL,
+    updated_at      DATETIME NOT NULL,
+    expires_at      DATETIME NOT NULL,
+    PRIMARY KEY (scope, idempotency_key),
+    INDEX idx_idempotency_key_expires (expires_at)
+);
+
+-- Persisted GraphQL queries. Clients send the SHA-256 hash of a query instead of
+-- its text once the query is stored here.
+CREATE TABLE persisted_query (
+    hash         CHAR(64) NOT NULL PRIMARY KEY,
+    query        TEXT NOT NULL,
+    created_at   DATETIME NOT NULL,
+    last_used_at DATETIME NOT NULL
+);
+
+-- Outbound webhooks. Contest and entry changes record an event in the same
+-- transaction and queue one delivery per active subscription to its type; a job
+-- sends them signed with the subscription's secret and retries failures with
+-- exponential backoff. Deliveries that run out of attempts stay here as dead
+-- letters until they are replayed. Every attempt is logged.
+CREATE TABLE webhook_subscription (
+    id          INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id     INT NOT NULL,
+    url         VARCHAR(2048) NOT NULL,
+    secret      VARCHAR(128) NOT NULL,
+    event_types VARCHAR(255) NOT NULL,
+    is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  DATETIME NOT NULL,
+    INDEX idx_webhook_subscription_user (user_id)
+);
+
+CREATE TABLE webhook_event (
+    id         INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    event_type VARCHAR(32) NOT NULL,
+    payload    JSON NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE webhook_delivery (
+    id               INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    subscription_id  INT NOT NULL,
+    event_id         INT NOT NULL,
+    status           VARCHAR(16) NOT NULL,
+    attempts         INT NOT NULL DEFAULT 0,
+    next_attempt_at  DATETIME NOT NULL,
+    last_status_code INT NULL,
+    last_error       VARCHAR(512) NULL,
+    delivered_at     DATETIME NULL,
+    created_at       DATETIME NOT NULL,
+    updated_at       DATETIME NOT NULL,
+    INDEX idx_webhook_delivery_due (status, next_attempt_at),
+    INDEX idx_webhook_delivery_subscription (subscription_id, status),
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscription (id),
+    FOREIGN KEY (event_id) REFERENCES webhook_event (id)
+);
+
+CREATE TABLE webhook_delivery_attempt (
+    id          INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    delivery_id INT NOT NULL,
+    status_code INT NULL,
+    error       VARCHAR(512) NULL,
+    duration_ms INT NOT NULL,
+    created_at  DATETIME NOT NULL,
+    INDEX idx_webhook_delivery_attempt_delivery (delivery_id),
+    FOREIGN KEY (delivery_id) REFERENCES webhook_delivery (id)
+);
+
+-- Waitlist spots keep the captain and vice-captain chosen with the queued lineup,
+-- so the entry made when the spot is confirmed has them too.
+ALTER TABLE contest_waitlist
+    ADD COLUMN roles TEXT NULL;
+
+-- Open head-to-head challenges hold their entry fee until they are matched, cancelled
+-- or expire. Challenges posted before the hold existed have fee_held = FALSE and are
+-- never refunded.
+ALTER TABLE h2h_challenge
+    ADD COLUMN fee_held BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Contests created through POST /contests keep their name unique among the contests
+-- open for entries. open_name is only set while such a contest is active, so the
+-- unique index ignores settled contests and those created by clones, templates and
+-- head-to-head matches.
+ALTER TABLE contest
+    ADD COLUMN name_unique BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN open_name VARCHAR(255) AS (IF(name_unique AND status = 'active', name, NULL)) STORED,
+    ADD UNIQUE INDEX idx_contest_open_name (open_name);
+
+-- Webhooks are only offered to partner accounts. Events for subscriptions of accounts
+-- that stop being partners are no longer queued.
+ALTER TABLE users
+    ADD COLUMN is_partner BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Operators run the site: contest templates, score corrections, contest slots and the
+-- finance reports are limited to them.
+ALTER TABLE users
+    ADD COLUMN is_operator BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Team names are unique among the teams outside of leagues. open_name is only set for
+-- those teams, so league teams may share names. Duplicate names created before the
+-- index existed must be renamed before it is added.
+ALTER TABLE team
+    ADD COLUMN open_name VARCHAR(50) AS (IF(league_id IS NULL, name, NULL)) STORED,
+    ADD UNIQUE INDEX idx_team_open_name (open_name);
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lineup roles. A player holding a role scores their fantasy points times the role's
+// multiplier; every other player in the lineup scores them once.
+const (
+	roleCaptain     = "captain"
+	roleViceCaptain = "vice_captain"
+)
+
+// lineupRoleMultipliers holds the multiplier of every role a lineup can designate
+var lineupRoleMultipliers = map[string]float64{
+	roleCaptain:     2,
+	roleViceCaptain: 1.5,
+}
+
+var errNotScorer = forbiddenError("not_operator", "Only operators and partners can record scores")
+
+// LineupRoles maps a role such as "captain" to the player ID holding it
+type LineupRoles map[string]int
+
+// PlayerScore is the fantasy points a player scored in a game
+type PlayerScore struct {
+	PlayerID int     `json:"player_id"`
+	Points   float64 `json:"points"`
+}
+
+// LeaderboardEntry is one entry's standing in a contest
+type LeaderboardEntry struct {
+	Rank    int     `json:"rank"`
+	EntryID int     `json:"entry_id"`
+	UserID  int     `json:"user_id"`
+	Points  float64 `json:"points"`
+}
+
+func setupScoringRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to record the fantasy points players scored in a game
+	r.PUT("/games/:id/scores", func(c *gin.Context) {
+		if !scoreFeedRequest(c, db) {
+			return
+		}
+
+		gameID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid game ID"))
+			return
+		}
+
+		var scores []PlayerScore
+		if err := c.ShouldBindJSON(&scores); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		if err := savePlayerScores(db, gameID, scores); err != nil {
+			c.Error(apiError(err, "Failed to save the scores"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Scores saved successfully"})
+	})
+
+	// Route to rank a contest's entries by their fantasy points
+	r.GET("/contests/:id/leaderboard", func(c *gin.Context) {
+		contestID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid contest ID"))
+			return
+		}
+
+		leaderboard, err := getContestLeaderboard(db, contestID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch the leaderboard"))
+			return
+		}
+
+		c.JSON(http.StatusOK, leaderboard)
+	})
+}
+
+// scoreFeedRequest checks the caller may record scores: an operator correcting them or
+// a partner feeding them in. It writes the error response itself when they may not.
+func scoreFeedRequest(c *gin.Context, db *sql.DB) bool {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return false
+	}
+
+	var allowed bool
+	err = db.QueryRow("SELECT is_operator OR is_partner FROM users WHERE id = ?", userID).Scan(&allowed)
+	if err != nil && err != sql.ErrNoRows {
+		c.Error(apiError(err, "Failed to check the account"))
+		return false
+	}
+	if !allowed {
+		c.Error(errNotScorer)
+		return false
+	}
+
+	return true
+}
+
+// validateLineupRoles checks that every role is known and held by a distinct player
+// from the lineup
+func validateLineupRoles(lineup []int, roles LineupRoles) error {
+	inLineup := make(map[int]bool, len(lineup))
+	for _, playerID := range lineup {
+		inLineup[playerID] = true
+	}
+
+	holders := make(map[int]string, len(roles))
+	for role, playerID := range roles {
+		if _, ok := lineupRoleMultipliers[role]; !ok {
+			return invalidField("unknown_role", "roles", fmt.Sprintf("Unknown lineup role %q", role))
+		}
+		if !inLineup[playerID] {
+			return invalidField("role_not_in_lineup", "roles", fmt.Sprintf("The %s must be a player in the lineup", role))
+		}
+		if other, ok := holders[playerID]; ok {
+			return invalidField("duplicate_role_player", "roles", fmt.Sprintf("Player %d cannot be both %s and %s", playerID, other, role))
+		}
+		holders[playerID] = role
+	}
+
+	return nil
+}
+
+// roleMultiplier returns the points multiplier of a role; players without one score 1x
+func roleMultiplier(role string) float64 {
+	if m, ok := lineupRoleMultipliers[role]; ok {
+		return m
+	}
+	return 1
+}
+
+// changedRoleHolders returns the players who gain or lose a role between current and next
+func changedRoleHolders(current, next LineupRoles) []int {
+	seen := make(map[int]bool)
+	var changed []int
+	add := func(playerID int) {
+		if playerID != 0 && !seen[playerID] {
+			seen[playerID] = true
+			changed = append(changed, playerID)
+		}
+	}
+
+	for role, playerID := range current {
+		if next[role] != playerID {
+			add(playerID)
+			add(next[role])
+		}
+	}
+	for role, playerID := range next {
+		if _, ok := current[role]; !ok {
+			add(playerID)
+		}
+	}
+
+	sort.Ints(changed)
+	return changed
+}
+
+// getEntryRoles returns the roles designated in an entry's lineup
+func getEntryRoles(q queryer, entryID int) (LineupRoles, error) {
+	rows, err := q.Query("SELECT role, player_id FROM entry_lineup WHERE entry_id = ? AND role IS NOT NULL", entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := LineupRoles{}
+	for rows.Next() {
+		var role string
+		var playerID int
+		if err := rows.Scan(&role, &playerID); err != nil {
+			return nil, err
+		}
+		roles[role] = playerID
+	}
+
+	return roles, rows.Err()
+}
+
+// savePlayerScores records the points of players in a game, replacing earlier values
+// so stat corrections can be posted again
+func savePlayerScores(db *sql.DB, gameID int, scores []PlayerScore) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, score := range scores {
+		var playerGame sql.NullInt64
+		err := tx.QueryRow("SELECT game_id FROM player WHERE id = ?", score.PlayerID).Scan(&playerGame)
+		if err != nil {
+			tx.Rollback()
+			if err == sql.ErrNoRows {
+				return notFoundError("player_not_found", fmt.Sprintf("Player %d not found", score.PlayerID))
+			}
+			return err
+		}
+		if int(playerGame.Int64) != gameID {
+			tx.Rollback()
+			return invalidField("player_not_in_game", "player_id", fmt.Sprintf("Player %d is not playing in game %d", score.PlayerID, gameID))
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO player_score (player_id, game_id, points, updated_at) VALUES (?, ?, ?, NOW()) ON DUPLICATE KEY UPDATE points = VALUES(points), updated_at = NOW()",
+			score.PlayerID, gameID, score.Points,
+		)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// computeEntryPoints returns the fantasy points of an entry with its role multipliers applied
+func computeEntryPoints(q queryer, entryID int) (float64, error) {
+	rows, err := q.Query(
+		"SELECT IFNULL(el.role, ''), IFNULL(ps.points, 0) FROM entry_lineup el JOIN player p ON p.id = el.player_id LEFT JOIN player_score ps ON ps.player_id = el.player_id AND ps.game_id = p.game_id WHERE el.entry_id = ?",
+		entryID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var total float64
+	for rows.Next() {
+		var role string
+		var points float64
+		if err := rows.Scan(&role, &points); err != nil {
+			return 0, err
+		}
+		total += points * roleMultiplier(role)
+	}
+
+	return total, rows.Err()
+}
+
+// getContestLeaderboard returns a contest's entries ordered by points, highest first.
+// Entries on equal points share a rank.
+func getContestLeaderboard(q queryer, contestID int) ([]LeaderboardEntry, error) {
+	var exists bool
+	err := q.QueryRow("SELECT EXISTS (SELECT 1 FROM contest WHERE id = ?)", contestID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errContestNotFound
+	}
+
+	rows, err := q.Query(
+		"SELECT uc.id, uc.user_id, IFNULL(el.role, ''), IFNULL(ps.points, 0) FROM user_contest uc LEFT JOIN entry_lineup el ON el.entry_id = uc.id LEFT JOIN player p ON p.id = el.player_id LEFT JOIN player_score ps ON ps.player_id = el.player_id AND ps.game_id = p.game_id WHERE uc.contest_id = ?",
+		contestID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byEntry := make(map[int]*LeaderboardEntry)
+	for rows.Next() {
+		var entryID, userID int
+		var role string
+		var points float64
+		if err := rows.Scan(&entryID, &userID, &role, &points); err != nil {
+			return nil, err
+		}
+		entry, ok := byEntry[entryID]
+		if !ok {
+			entry = &LeaderboardEntry{EntryID: entryID, UserID: userID}
+			byEntry[entryID] = entry
+		}
+		entry.Points += points * roleMultiplier(role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	leaderboard := make([]LeaderboardEntry, 0, len(byEntry))
+	for _, entry := range byEntry {
+		leaderboard = append(leaderboard, *entry)
+	}
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].Points != leaderboard[j].Points {
+			return leaderboard[i].Points > leaderboard[j].Points
+		}
+		return leaderboard[i].EntryID < leaderboard[j].EntryID
+	})
+
+	for i := range leaderboard {
+		if i > 0 && leaderboard[i].Points == leaderboard[i-1].Points {
+			leaderboard[i].Rank = leaderboard[i-1].Rank
+		} else {
+			leaderboard[i].Rank = i + 1
+		}
+	}
+
+	return leaderboard, nil
+}
@@ -0,0 +1,808 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Matchup statuses
+const (
+	matchupScheduled = "scheduled"
+	matchupFinal     = "final"
+)
+
+const (
+	// Regular season length when the commissioner does not choose one
+	defaultRegularSeasonWeeks = 14
+
+	// Number of teams that make the playoffs when the commissioner does not choose one
+	defaultPlayoffTeams = 4
+)
+
+var errScheduleGenerated = conflictError("schedule_generated", "The schedule has already been generated")
+
+// SeasonSettings is the request body used by a commissioner to set up the season
+type SeasonSettings struct {
+	SeasonStart        time.Time `json:"season_start"`
+	RegularSeasonWeeks int       `json:"regular_season_weeks"`
+	PlayoffTeams       int       `json:"playoff_teams"`
+}
+
+// Matchup is a weekly head-to-head game between two league teams. A playoff bye is a
+// matchup without an away team. Seeds are only set in the playoffs.
+type Matchup struct {
+	ID           int        `json:"id"`
+	LeagueID     int        `json:"league_id"`
+	Week         int        `json:"week"`
+	WeekStart    *time.Time `json:"week_start,omitempty"`
+	HomeTeamID   int        `json:"home_team_id"`
+	AwayTeamID   int        `json:"away_team_id,omitempty"`
+	HomePoints   *float64   `json:"home_points,omitempty"`
+	AwayPoints   *float64   `json:"away_points,omitempty"`
+	Status       string     `json:"status"`
+	PlayoffRound int        `json:"playoff_round,omitempty"`
+	BracketSlot  int        `json:"bracket_slot,omitempty"`
+	HomeSeed     int        `json:"home_seed,omitempty"`
+	AwaySeed     int        `json:"away_seed,omitempty"`
+}
+
+// MatchupScore is the request body used to record the final score of a matchup
+type MatchupScore struct {
+	HomePoints float64 `json:"home_points"`
+	AwayPoints float64 `json:"away_points"`
+}
+
+// Standing is one team's regular-season record
+type Standing struct {
+	Rank          int     `json:"rank"`
+	TeamID        int     `json:"team_id"`
+	TeamName      string  `json:"team_name"`
+	Wins          int     `json:"wins"`
+	Losses        int     `json:"losses"`
+	Ties          int     `json:"ties"`
+	PointsFor     float64 `json:"points_for"`
+	PointsAgainst float64 `json:"points_against"`
+}
+
+func setupSeasonRoutes(r *gin.Engine, db *sql.DB) {
+	// Route for the commissioner to set the season start, length and playoff size
+	r.PUT("/leagues/:id/season", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		var req SeasonSettings
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		if err := updateSeasonSettings(db, userID, leagueID, req); err != nil {
+			c.Error(apiError(err, "Failed to update the season"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Season updated successfully"})
+	})
+
+	// Route for the commissioner to generate the regular-season schedule
+	r.POST("/leagues/:id/schedule", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		if err := generateSchedule(db, userID, leagueID); err != nil {
+			c.Error(apiError(err, "Failed to generate the schedule"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Schedule generated successfully"})
+	})
+
+	// Route to list a league's matchups, optionally for a single week
+	r.GET("/leagues/:id/matchups", func(c *gin.Context) {
+		leagueID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid league ID"))
+			return
+		}
+
+		week := 0
+		if v := c.Query("week"); v != "" {
+			week, err = strconv.Atoi(v)
+			if err != nil || week <= 0 {
+				c.Error(invalidParam("week", "Invalid week"))
+				return
+			}
+		}
+
+		matchups, err := getMatchups(db, leagueID, week)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch matchups"))
+			return
+		}
+
+		c.JSON(http.StatusOK, matchups)
+	})
+
+	// Route for the commissioner to record a matchup's final score
+	r.PUT("/leagues/:id/matchups/:matchupID", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		matchupID, err := strconv.Atoi(c.Param("matchupID"))
+		if err != nil {
+			c.Error(invalidParam("matchupID", "Invalid matchup ID"))
+			return
+		}
+
+		var req MatchupScore
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		if err := scoreMatchup(db, userID, leagueID, matchupID, req); err != nil {
+			c.Error(apiError(err, "Failed to score the matchup"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Matchup scored successfully"})
+	})
+
+	// Route to fetch the regular-season standings
+	r.GET("/leagues/:id/standings", func(c *gin.Context) {
+		leagueID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid league ID"))
+			return
+		}
+
+		standings, err := getStandings(db, leagueID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch standings"))
+			return
+		}
+
+		c.JSON(http.StatusOK, standings)
+	})
+
+	// Route for the commissioner to seed the playoff bracket once the regular season is over
+	r.POST("/leagues/:id/playoffs", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		if err := startPlayoffs(db, userID, leagueID); err != nil {
+			c.Error(apiError(err, "Failed to start the playoffs"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Playoffs started successfully"})
+	})
+}
+
+// leagueRequest reads the current user and the league ID, writing the error response
+// itself when either is missing
+func leagueRequest(c *gin.Context) (int, int, bool) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return 0, 0, false
+	}
+
+	leagueID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(invalidParam("id", "Invalid league ID"))
+		return 0, 0, false
+	}
+
+	return userID, leagueID, true
+}
+
+// updateSeasonSettings changes the season setup, which is only allowed before the
+// schedule has been generated
+func updateSeasonSettings(db *sql.DB, userID int, leagueID int, req SeasonSettings) error {
+	if req.SeasonStart.IsZero() {
+		return invalidField("invalid_season_start", "season_start", "season_start is required")
+	}
+	if req.RegularSeasonWeeks <= 0 {
+		req.RegularSeasonWeeks = defaultRegularSeasonWeeks
+	}
+	if req.PlayoffTeams <= 0 {
+		req.PlayoffTeams = defaultPlayoffTeams
+	}
+	if req.PlayoffTeams < 2 {
+		return invalidField("invalid_playoff_teams", "playoff_teams", "At least two teams must make the playoffs")
+	}
+
+	commissionerID, err := leagueCommissioner(db, leagueID)
+	if err != nil {
+		return err
+	}
+	if commissionerID != userID {
+		return forbiddenError("not_commissioner", "Only the commissioner can change the season")
+	}
+
+	var matchups int
+	err = db.QueryRow("SELECT COUNT(*) FROM matchup WHERE league_id = ?", leagueID).Scan(&matchups)
+	if err != nil {
+		return err
+	}
+	if matchups > 0 {
+		return errScheduleGenerated
+	}
+
+	_, err = db.Exec(
+		"UPDATE league SET season_start = ?, regular_season_weeks = ?, playoff_teams = ? WHERE id = ?",
+		req.SeasonStart, req.RegularSeasonWeeks, req.PlayoffTeams, leagueID,
+	)
+	return err
+}
+
+// roundRobin pairs the teams for each week using the circle method: the first team
+// stays put while the others rotate one place a week, so every team meets every other
+// team once before any pairing repeats. With an odd number of teams one team has a bye
+// each week. Home games are shared out so no team has more than one more than another.
+func roundRobin(teamIDs []int, weeks int) [][][2]int {
+	ids := append([]int(nil), teamIDs...)
+	if len(ids)%2 == 1 {
+		// The bye stays put, so every team rotates through it
+		ids = append([]int{0}, ids...)
+	}
+	n := len(ids)
+
+	schedule := make([][][2]int, weeks)
+	for w := 0; w < weeks; w++ {
+		round := w % (n - 1)
+
+		arranged := make([]int, n)
+		arranged[0] = ids[0]
+		for i := 1; i < n; i++ {
+			arranged[i] = ids[1+(i-1+round)%(n-1)]
+		}
+
+		for i := 0; i < n/2; i++ {
+			// The fixed team alternates week by week; the other pairings alternate down
+			// the table, which the rotation turns into an even split for every team
+			home, away := arranged[i], arranged[n-1-i]
+			if (i == 0 && w%2 == 1) || i%2 == 1 {
+				home, away = away, home
+			}
+			if home == 0 || away == 0 {
+				continue
+			}
+			schedule[w] = append(schedule[w], [2]int{home, away})
+		}
+	}
+
+	return schedule
+}
+
+// generateSchedule creates every regular-season matchup for the league
+func generateSchedule(db *sql.DB, userID int, leagueID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var commissionerID, weeks int
+	var seasonStart sql.NullTime
+	err = tx.QueryRow("SELECT commissioner_id, regular_season_weeks, season_start FROM league WHERE id = ? FOR UPDATE", leagueID).
+		Scan(&commissionerID, &weeks, &seasonStart)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return errLeagueNotFound
+		}
+		return err
+	}
+
+	if commissionerID != userID {
+		tx.Rollback()
+		return forbiddenError("not_commissioner", "Only the commissioner can generate the schedule")
+	}
+
+	if !seasonStart.Valid || weeks <= 0 {
+		tx.Rollback()
+		return conflictError("season_not_set", "Set up the season before generating the schedule")
+	}
+
+	var matchups int
+	err = tx.QueryRow("SELECT COUNT(*) FROM matchup WHERE league_id = ?", leagueID).Scan(&matchups)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if matchups > 0 {
+		tx.Rollback()
+		return errScheduleGenerated
+	}
+
+	rows, err := tx.Query("SELECT id FROM team WHERE league_id = ? ORDER BY id", leagueID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var teamIDs []int
+	for rows.Next() {
+		var teamID int
+		if err := rows.Scan(&teamID); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		teamIDs = append(teamIDs, teamID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if len(teamIDs) < 2 {
+		tx.Rollback()
+		return conflictError("not_enough_teams", "A schedule needs at least two teams")
+	}
+
+	for w, games := range roundRobin(teamIDs, weeks) {
+		for _, game := range games {
+			_, err = tx.Exec(
+				"INSERT INTO matchup (league_id, week, home_team_id, away_team_id, status) VALUES (?, ?, ?, ?, ?)",
+				leagueID, w+1, game[0], game[1], matchupScheduled,
+			)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getMatchups returns a league's matchups by week, or only those of one week when week > 0
+func getMatchups(db *sql.DB, leagueID int, week int) ([]Matchup, error) {
+	var seasonStart sql.NullTime
+	err := db.QueryRow("SELECT season_start FROM league WHERE id = ?", leagueID).Scan(&seasonStart)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errLeagueNotFound
+		}
+		return nil, err
+	}
+
+	query := "SELECT id, league_id, week, home_team_id, IFNULL(away_team_id, 0), home_points, away_points, status, playoff_round, bracket_slot, home_seed, away_seed FROM matchup WHERE league_id = ?"
+	args := []interface{}{leagueID}
+	if week > 0 {
+		query += " AND week = ?"
+		args = append(args, week)
+	}
+	query += " ORDER BY week, bracket_slot, id"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matchups := []Matchup{}
+	for rows.Next() {
+		m, err := scanMatchup(rows)
+		if err != nil {
+			return nil, err
+		}
+		if seasonStart.Valid {
+			start := seasonStart.Time.AddDate(0, 0, 7*(m.Week-1))
+			m.WeekStart = &start
+		}
+		matchups = append(matchups, *m)
+	}
+
+	return matchups, rows.Err()
+}
+
+func scanMatchup(row rowScanner) (*Matchup, error) {
+	var m Matchup
+	var homePoints, awayPoints sql.NullFloat64
+	err := row.Scan(&m.ID, &m.LeagueID, &m.Week, &m.HomeTeamID, &m.AwayTeamID, &homePoints, &awayPoints, &m.Status, &m.PlayoffRound, &m.BracketSlot, &m.HomeSeed, &m.AwaySeed)
+	if err != nil {
+		return nil, err
+	}
+	if homePoints.Valid {
+		m.HomePoints = &homePoints.Float64
+	}
+	if awayPoints.Valid {
+		m.AwayPoints = &awayPoints.Float64
+	}
+
+	return &m, nil
+}
+
+// scoreMatchup records a matchup's final score. Scoring the last game of a playoff
+// round sets up the next round.
+func scoreMatchup(db *sql.DB, userID int, leagueID int, matchupID int, req MatchupScore) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	commissionerID, err := leagueCommissioner(tx, leagueID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if commissionerID != userID {
+		tx.Rollback()
+		return forbiddenError("not_commissioner", "Only the commissioner can score matchups")
+	}
+
+	m, err := scanMatchup(tx.QueryRow(
+		"SELECT id, league_id, week, home_team_id, IFNULL(away_team_id, 0), home_points, away_points, status, playoff_round, bracket_slot, home_seed, away_seed FROM matchup WHERE id = ? AND league_id = ? FOR UPDATE",
+		matchupID, leagueID,
+	))
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return notFoundError("matchup_not_found", "Matchup not found")
+		}
+		return err
+	}
+
+	if m.AwayTeamID == 0 {
+		tx.Rollback()
+		return conflictError("bye_matchup", "A bye cannot be scored")
+	}
+
+	// Once the next playoff round exists its pairings depend on this result
+	if m.PlayoffRound > 0 {
+		var later int
+		err = tx.QueryRow("SELECT COUNT(*) FROM matchup WHERE league_id = ? AND playoff_round > ?", leagueID, m.PlayoffRound).Scan(&later)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if later > 0 {
+			tx.Rollback()
+			return conflictError("playoff_round_set", "The next playoff round has already been set")
+		}
+	}
+
+	_, err = tx.Exec("UPDATE matchup SET home_points = ?, away_points = ?, status = ? WHERE id = ?", req.HomePoints, req.AwayPoints, matchupFinal, matchupID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if m.PlayoffRound > 0 {
+		if err := advancePlayoffs(tx, leagueID, m.PlayoffRound); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getStandings ranks the league's teams on their final regular-season matchups: by win
+// percentage with a tie counting as half a win, then points for, then fewest points against
+func getStandings(q queryer, leagueID int) ([]Standing, error) {
+	rows, err := q.Query("SELECT id, name FROM team WHERE league_id = ? ORDER BY id", leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	byTeam := make(map[int]*Standing)
+	var standings []*Standing
+	for rows.Next() {
+		s := &Standing{}
+		if err := rows.Scan(&s.TeamID, &s.TeamName); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		byTeam[s.TeamID] = s
+		standings = append(standings, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = q.Query(
+		"SELECT home_team_id, away_team_id, home_points, away_points FROM matchup WHERE league_id = ? AND playoff_round = 0 AND status = ?",
+		leagueID, matchupFinal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var homeID, awayID int
+		var homePoints, awayPoints float64
+		if err := rows.Scan(&homeID, &awayID, &homePoints, &awayPoints); err != nil {
+			return nil, err
+		}
+
+		home, away := byTeam[homeID], byTeam[awayID]
+		if home == nil || away == nil {
+			continue
+		}
+
+		home.PointsFor += homePoints
+		home.PointsAgainst += awayPoints
+		away.PointsFor += awayPoints
+		away.PointsAgainst += homePoints
+
+		switch {
+		case homePoints > awayPoints:
+			home.Wins++
+			away.Losses++
+		case awayPoints > homePoints:
+			away.Wins++
+			home.Losses++
+		default:
+			home.Ties++
+			away.Ties++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if pa, pb := winPercentage(a), winPercentage(b); pa != pb {
+			return pa > pb
+		}
+		if a.PointsFor != b.PointsFor {
+			return a.PointsFor > b.PointsFor
+		}
+		return a.PointsAgainst < b.PointsAgainst
+	})
+
+	result := make([]Standing, len(standings))
+	for i, s := range standings {
+		s.Rank = i + 1
+		result[i] = *s
+	}
+
+	return result, nil
+}
+
+func winPercentage(s *Standing) float64 {
+	games := s.Wins + s.Losses + s.Ties
+	if games == 0 {
+		return 0
+	}
+
+	return (float64(s.Wins) + 0.5*float64(s.Ties)) / float64(games)
+}
+
+// bracketSeeds returns the first-round seed order for a bracket of size teams (a power
+// of two), so that 1 plays size, 2 plays size-1 and the top seeds meet as late as possible
+func bracketSeeds(size int) []int {
+	seeds := []int{1}
+	for len(seeds) < size {
+		next := make([]int, 0, 2*len(seeds))
+		for _, s := range seeds {
+			next = append(next, s, 2*len(seeds)+1-s)
+		}
+		seeds = next
+	}
+
+	return seeds
+}
+
+// startPlayoffs seeds the playoff bracket from the final standings. When the number of
+// playoff teams is not a power of two the top seeds get a first-round bye.
+func startPlayoffs(db *sql.DB, userID int, leagueID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var commissionerID, weeks, playoffTeams int
+	err = tx.QueryRow("SELECT commissioner_id, regular_season_weeks, playoff_teams FROM league WHERE id = ? FOR UPDATE", leagueID).
+		Scan(&commissionerID, &weeks, &playoffTeams)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return errLeagueNotFound
+		}
+		return err
+	}
+
+	if commissionerID != userID {
+		tx.Rollback()
+		return forbiddenError("not_commissioner", "Only the commissioner can start the playoffs")
+	}
+
+	var total, unfinished, playoffGames int
+	err = tx.QueryRow(
+		"SELECT COUNT(*), IFNULL(SUM(playoff_round = 0 AND status <> ?), 0), IFNULL(SUM(playoff_round > 0), 0) FROM matchup WHERE league_id = ?",
+		matchupFinal, leagueID,
+	).Scan(&total, &unfinished, &playoffGames)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if total == 0 || unfinished > 0 {
+		tx.Rollback()
+		return conflictError("regular_season_in_progress", "The regular season is not over yet")
+	}
+	if playoffGames > 0 {
+		tx.Rollback()
+		return conflictError("playoffs_started", "The playoffs have already started")
+	}
+
+	standings, err := getStandings(tx, leagueID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if playoffTeams > len(standings) {
+		playoffTeams = len(standings)
+	}
+
+	size := 1
+	for size < playoffTeams {
+		size *= 2
+	}
+
+	seeds := bracketSeeds(size)
+	for slot := 0; slot < size/2; slot++ {
+		homeSeed, awaySeed := seeds[2*slot], seeds[2*slot+1]
+		if homeSeed > awaySeed {
+			homeSeed, awaySeed = awaySeed, homeSeed
+		}
+
+		home := standings[homeSeed-1].TeamID
+		if awaySeed > playoffTeams {
+			err = insertPlayoffMatchup(tx, leagueID, weeks+1, 1, slot+1, home, homeSeed, 0, 0)
+		} else {
+			err = insertPlayoffMatchup(tx, leagueID, weeks+1, 1, slot+1, home, homeSeed, standings[awaySeed-1].TeamID, awaySeed)
+		}
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	// A bracket made only of byes is already decided
+	if err := advancePlayoffs(tx, leagueID, 1); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// insertPlayoffMatchup stores one bracket game; a bye (awayTeamID 0) is final at once
+func insertPlayoffMatchup(tx *sql.Tx, leagueID int, week int, round int, slot int, homeTeamID int, homeSeed int, awayTeamID int, awaySeed int) error {
+	if awayTeamID == 0 {
+		_, err := tx.Exec(
+			"INSERT INTO matchup (league_id, week, home_team_id, away_team_id, status, playoff_round, bracket_slot, home_seed, away_seed) VALUES (?, ?, ?, NULL, ?, ?, ?, ?, 0)",
+			leagueID, week, homeTeamID, matchupFinal, round, slot, homeSeed,
+		)
+		return err
+	}
+
+	_, err := tx.Exec(
+		"INSERT INTO matchup (league_id, week, home_team_id, away_team_id, status, playoff_round, bracket_slot, home_seed, away_seed) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		leagueID, week, homeTeamID, awayTeamID, matchupScheduled, round, slot, homeSeed, awaySeed,
+	)
+	return err
+}
+
+// advancePlayoffs creates the next playoff round once every game of round is final.
+// Winners of neighbouring bracket slots meet; a tied game goes to the better seed.
+func advancePlayoffs(tx *sql.Tx, leagueID int, round int) error {
+	rows, err := tx.Query(
+		"SELECT id, league_id, week, home_team_id, IFNULL(away_team_id, 0), home_points, away_points, status, playoff_round, bracket_slot, home_seed, away_seed FROM matchup WHERE league_id = ? AND playoff_round = ? ORDER BY bracket_slot",
+		leagueID, round,
+	)
+	if err != nil {
+		return err
+	}
+
+	var games []*Matchup
+	for rows.Next() {
+		m, err := scanMatchup(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		games = append(games, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// The final has been played, or the round is still in progress
+	if len(games) < 2 {
+		return nil
+	}
+	for _, m := range games {
+		if m.Status != matchupFinal {
+			return nil
+		}
+	}
+
+	for i := 0; i+1 < len(games); i += 2 {
+		teamA, seedA := playoffWinner(games[i])
+		teamB, seedB := playoffWinner(games[i+1])
+		if seedB < seedA {
+			teamA, seedA, teamB, seedB = teamB, seedB, teamA, seedA
+		}
+
+		err := insertPlayoffMatchup(tx, leagueID, games[i].Week+1, round+1, i/2+1, teamA, seedA, teamB, seedB)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// playoffWinner returns the team and seed that advance from a final playoff matchup
+func playoffWinner(m *Matchup) (int, int) {
+	if m.AwayTeamID == 0 {
+		return m.HomeTeamID, m.HomeSeed
+	}
+
+	home, away := *m.HomePoints, *m.AwayPoints
+	if away > home || (away == home && m.AwaySeed < m.HomeSeed) {
+		return m.AwayTeamID, m.AwaySeed
+	}
+
+	return m.HomeTeamID, m.HomeSeed
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRoundRobin(t *testing.T) {
+	tests := []struct {
+		teams int
+		weeks int
+	}{
+		{2, 1},
+		{4, 3},
+		{5, 5},
+		{8, 7},
+		{9, 9},
+		{10, 9},
+		{11, 11},
+	}
+
+	for _, tt := range tests {
+		teamIDs := make([]int, tt.teams)
+		for i := range teamIDs {
+			teamIDs[i] = 100 + i
+		}
+
+		schedule := roundRobin(teamIDs, tt.weeks)
+		if len(schedule) != tt.weeks {
+			t.Fatalf("%d teams: %d weeks, want %d", tt.teams, len(schedule), tt.weeks)
+		}
+
+		met := make(map[[2]int]int)
+		home := make(map[int]int)
+		for w, week := range schedule {
+			// Every team plays once a week, except the team with a bye when the count is odd
+			if len(week) != tt.teams/2 {
+				t.Errorf("%d teams: week %d has %d matchups, want %d", tt.teams, w+1, len(week), tt.teams/2)
+			}
+			played := make(map[int]bool)
+			for _, m := range week {
+				if played[m[0]] || played[m[1]] {
+					t.Errorf("%d teams: week %d has a team playing twice: %v", tt.teams, w+1, week)
+				}
+				played[m[0]], played[m[1]] = true, true
+
+				pair := m
+				if pair[0] > pair[1] {
+					pair[0], pair[1] = pair[1], pair[0]
+				}
+				met[pair]++
+				home[m[0]]++
+			}
+		}
+
+		// One full cycle pairs every two teams exactly once
+		if want := tt.teams * (tt.teams - 1) / 2; len(met) != want {
+			t.Errorf("%d teams: %d distinct pairings, want %d", tt.teams, len(met), want)
+		}
+		for pair, n := range met {
+			if n != 1 {
+				t.Errorf("%d teams: %v meet %d times, want once", tt.teams, pair, n)
+			}
+		}
+
+		// Home games are shared out within one of each other
+		fewest, most := tt.weeks, 0
+		for _, id := range teamIDs {
+			if home[id] < fewest {
+				fewest = home[id]
+			}
+			if home[id] > most {
+				most = home[id]
+			}
+		}
+		if most-fewest > 1 {
+			t.Errorf("%d teams: home games range from %d to %d", tt.teams, fewest, most)
+		}
+	}
+}
+
+func TestRoundRobinRepeatsTheCycle(t *testing.T) {
+	schedule := roundRobin([]int{1, 2, 3, 4}, 6)
+
+	for w := 0; w < 3; w++ {
+		first := make(map[[2]int]bool)
+		for _, m := range schedule[w] {
+			first[m] = true
+		}
+		for _, m := range schedule[w+3] {
+			if !first[m] && !first[[2]int{m[1], m[0]}] {
+				t.Errorf("week %d pairs %v, which week %d does not", w+4, m, w+1)
+			}
+		}
+	}
+}
+
+func TestBracketSeeds(t *testing.T) {
+	tests := []struct {
+		size  int
+		seeds []int
+	}{
+		{1, []int{1}},
+		{2, []int{1, 2}},
+		{4, []int{1, 4, 2, 3}},
+		{8, []int{1, 8, 4, 5, 2, 7, 3, 6}},
+	}
+
+	for _, tt := range tests {
+		if seeds := bracketSeeds(tt.size); !reflect.DeepEqual(seeds, tt.seeds) {
+			t.Errorf("bracketSeeds(%d) = %v, want %v", tt.size, seeds, tt.seeds)
+		}
+	}
+
+	// First-round opponents always add up to size+1
+	seeds := bracketSeeds(16)
+	for i := 0; i < len(seeds); i += 2 {
+		if seeds[i]+seeds[i+1] != 17 {
+			t.Errorf("seed %d plays seed %d in a bracket of 16", seeds[i], seeds[i+1])
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	httpAddr = ":8080"
+	grpcAddr = ":9090"
+
+	// shutdownTimeout is how long in-flight requests and streams get to finish once the
+	// servers are asked to stop
+	shutdownTimeout = 15 * time.Second
+)
+
+// serve runs the HTTP and gRPC servers and the background jobs until the process
+// receives SIGINT or SIGTERM, or one of the servers fails, then shuts them all down
+// gracefully. Jobs must return once their context is done.
+func serve(handler http.Handler, grpcServer *grpc.Server, jobs ...func(context.Context)) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Jobs also stop when a server fails, so they are cancelled separately from ctx
+	jobCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+
+	var running sync.WaitGroup
+	for _, job := range jobs {
+		running.Add(1)
+		go func(job func(context.Context)) {
+			defer running.Done()
+			job(jobCtx)
+		}(job)
+	}
+
+	lis, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{Addr: httpAddr, Handler: handler}
+	errs := make(chan error, 2)
+
+	go func() {
+		log.Printf("HTTP server listening on %s", httpAddr)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+	}()
+
+	go func() {
+		log.Printf("gRPC server listening on %s", grpcAddr)
+		if err := grpcServer.Serve(lis); err != nil {
+			errs <- err
+		}
+	}()
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		log.Print("shutting down")
+	case serveErr = <-errs:
+		log.Printf("server failed, shutting down: %v", serveErr)
+	}
+
+	stopJobs()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// GracefulStop waits for open streams such as leaderboard watches, so it is bounded
+	// by the same timeout as the HTTP shutdown
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server shutdown: %v", err)
+	}
+
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+
+	// Jobs finish the run they are in, within what is left of the same timeout
+	jobsDone := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(jobsDone)
+	}()
+
+	select {
+	case <-jobsDone:
+	case <-shutdownCtx.Done():
+		log.Print("background jobs did not stop before the shutdown timeout")
+	}
+
+	return serveErr
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Slate is a set of real-world games for one sport that contests are built on
+type Slate struct {
+	ID         int       `json:"id"`
+	Sport      string    `json:"sport"`
+	Name       string    `json:"name"`
+	RosterSize int       `json:"roster_size"`
+	StartTime  time.Time `json:"start_time"`
+	EndTime    time.Time `json:"end_time"`
+}
+
+// getSlate fetches a slate by ID, inside or outside a transaction
+func getSlate(q queryer, slateID int) (*Slate, error) {
+	rows, err := q.Query("SELECT id, sport, name, roster_size, start_time, end_time FROM slate WHERE id = ?", slateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, notFoundError("slate_not_found", "Slate not found")
+	}
+
+	var slate Slate
+	if err := rows.Scan(&slate.ID, &slate.Sport, &slate.Name, &slate.RosterSize, &slate.StartTime, &slate.EndTime); err != nil {
+		return nil, err
+	}
+
+	return &slate, nil
+}
+
+// validateSlateLineup checks a lineup against a slate before any contest exists for it,
+// as validateLineup does for a contest built on the slate
+func validateSlateLineup(q queryer, slate *Slate, lineup []int) error {
+	if slate.RosterSize > 0 && len(lineup) != slate.RosterSize {
+		return invalidField("invalid_lineup_size", "lineup", fmt.Sprintf("Lineup must have exactly %d players", slate.RosterSize))
+	}
+	if len(lineup) == 0 {
+		return nil
+	}
+
+	seen := make(map[int]bool, len(lineup))
+	args := []interface{}{slate.ID}
+	for _, playerID := range lineup {
+		if seen[playerID] {
+			return invalidField("duplicate_player", "lineup", "A player can only appear once in a lineup")
+		}
+		seen[playerID] = true
+		args = append(args, playerID)
+	}
+
+	var onSlate int
+	err := q.QueryRow(
+		"SELECT COUNT(*) FROM player p JOIN game g ON g.id = p.game_id WHERE g.slate_id = ? AND p.id IN ("+placeholders(len(lineup))+")",
+		args...,
+	).Scan(&onSlate)
+	if err != nil {
+		return err
+	}
+
+	if onSlate != len(lineup) {
+		return invalidField("off_slate_player", "lineup", "Lineup contains players who are not playing on this slate")
+	}
+
+	return nil
+}
+
+// getUpcomingSlates returns the sport's slates starting in [from, to), earliest first
+func getUpcomingSlates(db *sql.DB, sport string, from, to time.Time) ([]Slate, error) {
+	rows, err := db.Query("SELECT id, sport, name, roster_size, start_time, end_time FROM slate WHERE sport = ? AND start_time >= ? AND start_time < ? ORDER BY start_time, id", sport, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slates := []Slate{}
+	for rows.Next() {
+		var slate Slate
+		if err := rows.Scan(&slate.ID, &slate.Sport, &slate.Name, &slate.RosterSize, &slate.StartTime, &slate.EndTime); err != nil {
+			return nil, err
+		}
+		slates = append(slates, slate)
+	}
+
+	return slates, rows.Err()
+}
@@ -0,0 +1,593 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+)
+
+// Template recurrence kinds
+const (
+	recurrenceDaily = "daily"
+	recurrenceSlate = "slate"
+	recurrenceCron  = "cron"
+)
+
+const (
+	// How often the generator looks for contests to create
+	contestGeneratorInterval = time.Hour
+
+	// Longest window the preview API will look ahead
+	maxPreviewDays = 31
+
+	// Most occurrences one template produces per call, however often it recurs
+	maxTemplateOccurrences = 1000
+
+	// Furthest ahead a template may create contests; matches the lead_days binding
+	maxTemplateLeadDays = 31
+
+	// Shortest time allowed between two ticks of a cron template
+	minTemplateInterval = time.Hour
+)
+
+// validPrizeStructures lists the prize structures a template may use
+var validPrizeStructures = map[string]bool{
+	prizeWinnerTakesAll: true,
+	prizeFiftyFifty:     true,
+	prizeTopHeavy:       true,
+}
+
+// ContestTemplate describes a contest operators run over and over, and when to run it.
+//
+// NamePattern may contain {date}, {time} and {slate}, filled in per occurrence.
+// Daily templates start at StartTime (HH:MM, UTC) every day; slate templates follow
+// every slate of Sport; cron templates start on each tick of CronExpr. Contests are
+// created LeadDays ahead of their start.
+type ContestTemplate struct {
+	ID                int       `json:"id"`
+	NamePattern       string    `json:"name_pattern" binding:"required,min=3,max=100"`
+	Prize             float64   `json:"prize" binding:"gte=0"`
+	PrizeStructure    string    `json:"prize_structure"`
+	EntryFee          float64   `json:"entry_fee" binding:"gte=0"`
+	TotalSlots        int       `json:"total_slots" binding:"required,gte=2,lte=100000"`
+	MaxEntriesPerUser int       `json:"max_entries_per_user" binding:"omitempty,gte=1,lte=150"`
+	RosterSize        int       `json:"roster_size" binding:"gte=0,lte=50"`
+	IsGuaranteed      bool      `json:"is_guaranteed"`
+	MinFillPercent    int       `json:"min_fill_percent" binding:"gte=0,lte=100"`
+	AutoClone         bool      `json:"auto_clone"`
+	MaxClones         int       `json:"max_clones" binding:"gte=0,lte=1000"`
+	Recurrence        string    `json:"recurrence" binding:"required,oneof=daily slate cron"`
+	Sport             string    `json:"sport,omitempty"`
+	StartTime         string    `json:"start_time,omitempty"`
+	CronExpr          string    `json:"cron_expr,omitempty"`
+	DurationMinutes   int       `json:"duration_minutes" binding:"omitempty,gte=1,lte=10080"`
+	LeadDays          int       `json:"lead_days" binding:"omitempty,gte=1,lte=31"`
+	Active            bool      `json:"active"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// ContestOccurrence is one contest a template produces. Key identifies the occurrence
+// within its template so generation can run any number of times without duplicates.
+type ContestOccurrence struct {
+	TemplateID int       `json:"template_id"`
+	Key        string    `json:"key"`
+	Name       string    `json:"name"`
+	SlateID    int       `json:"slate_id,omitempty"`
+	RosterSize int       `json:"roster_size"`
+	StartDate  time.Time `json:"start_date"`
+	EndDate    time.Time `json:"end_date"`
+	ContestID  *int      `json:"contest_id,omitempty"`
+}
+
+// Templates are run by the site's operators, so every template route requires one
+func setupTemplateRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to create a contest template
+	r.POST("/contest-templates", func(c *gin.Context) {
+		if _, ok := operatorRequest(c, db); !ok {
+			return
+		}
+
+		var tmpl ContestTemplate
+		if err := bindJSON(c, &tmpl); err != nil {
+			c.Error(err)
+			return
+		}
+
+		if err := validateTemplate(&tmpl); err != nil {
+			c.Error(err)
+			return
+		}
+
+		templateID, err := createContestTemplate(db, tmpl)
+		if err != nil {
+			c.Error(apiError(err, "Failed to create contest template"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Contest template created successfully", "template_id": templateID})
+	})
+
+	// Route to list all contest templates
+	r.GET("/contest-templates", func(c *gin.Context) {
+		if _, ok := operatorRequest(c, db); !ok {
+			return
+		}
+
+		templates, err := getContestTemplates(db, false)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch contest templates"))
+			return
+		}
+
+		c.JSON(http.StatusOK, templates)
+	})
+
+	// Route to show which contests a template will create over the next days
+	r.GET("/contest-templates/:id/preview", func(c *gin.Context) {
+		if _, ok := operatorRequest(c, db); !ok {
+			return
+		}
+
+		templateID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid template ID"))
+			return
+		}
+
+		days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+		if err != nil || days <= 0 || days > maxPreviewDays {
+			c.Error(invalidParam("days", fmt.Sprintf("days must be between 1 and %d", maxPreviewDays)))
+			return
+		}
+
+		tmpl, err := getContestTemplate(db, templateID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch the contest template"))
+			return
+		}
+
+		now := time.Now().UTC()
+		occurrences, err := templateOccurrences(db, tmpl, now, now.AddDate(0, 0, days))
+		if err != nil {
+			c.Error(apiError(err, "Failed to preview contest template"))
+			return
+		}
+
+		c.JSON(http.StatusOK, occurrences)
+	})
+
+	// Route to run the generator now instead of waiting for the next scheduled run
+	r.POST("/contest-templates/generate", func(c *gin.Context) {
+		if _, ok := operatorRequest(c, db); !ok {
+			return
+		}
+
+		created, err := generateContests(db, time.Now().UTC())
+		if err != nil {
+			c.Error(apiError(err, "Failed to generate contests"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Contests generated successfully", "created": created})
+	})
+}
+
+// validateTemplate checks what the binding tags cannot, fills in defaults, and checks
+// that the contests the template creates pass the same validation as POST /contests
+func validateTemplate(tmpl *ContestTemplate) error {
+	if tmpl.PrizeStructure == "" {
+		tmpl.PrizeStructure = defaultPrizeStructure
+	}
+	if !validPrizeStructures[tmpl.PrizeStructure] {
+		return invalidField("invalid_template", "prize_structure", fmt.Sprintf("unknown prize_structure %q", tmpl.PrizeStructure))
+	}
+	if tmpl.MaxEntriesPerUser <= 0 {
+		tmpl.MaxEntriesPerUser = defaultMaxEntriesPerUser
+	}
+	if tmpl.LeadDays <= 0 {
+		tmpl.LeadDays = 1
+	}
+
+	switch tmpl.Recurrence {
+	case recurrenceDaily:
+		if _, err := time.Parse("15:04", tmpl.StartTime); err != nil {
+			return invalidField("invalid_template", "start_time", "daily templates need start_time as HH:MM")
+		}
+		if tmpl.DurationMinutes <= 0 {
+			return invalidField("invalid_template", "duration_minutes", "daily templates need a positive duration_minutes")
+		}
+	case recurrenceSlate:
+		if tmpl.Sport == "" {
+			return invalidField("invalid_template", "sport", "slate templates need a sport")
+		}
+	case recurrenceCron:
+		schedule, err := cron.ParseStandard(tmpl.CronExpr)
+		if err != nil {
+			return invalidField("invalid_template", "cron_expr", fmt.Sprintf("invalid cron_expr: %v", err))
+		}
+		// Expressions such as "0 0 30 2 *" parse but never fire
+		tick := schedule.Next(time.Now())
+		if tick.IsZero() {
+			return invalidField("invalid_template", "cron_expr", "cron_expr never fires")
+		}
+		// Sample the upcoming ticks to catch expressions firing more than once an hour
+		for i := 0; i < 100; i++ {
+			next := schedule.Next(tick)
+			if next.IsZero() {
+				break
+			}
+			if next.Sub(tick) < minTemplateInterval {
+				return invalidField("invalid_template", "cron_expr", fmt.Sprintf("cron_expr may fire at most once every %s", minTemplateInterval))
+			}
+			tick = next
+		}
+		if tmpl.DurationMinutes <= 0 {
+			return invalidField("invalid_template", "duration_minutes", "cron templates need a positive duration_minutes")
+		}
+	default:
+		return invalidField("invalid_template", "recurrence", fmt.Sprintf("unknown recurrence %q", tmpl.Recurrence))
+	}
+
+	// Try the contest an occurrence tomorrow would create
+	start := time.Now().UTC().AddDate(0, 0, 1)
+	sample := ContestOccurrence{
+		Name:       templateContestName(tmpl.NamePattern, start, tmpl.Sport),
+		RosterSize: tmpl.RosterSize,
+		StartDate:  start,
+		EndDate:    start.Add(time.Duration(tmpl.DurationMinutes) * time.Minute),
+	}
+	if tmpl.Recurrence == recurrenceSlate {
+		sample.EndDate = start.Add(time.Hour)
+	}
+	return validateRequest(tmpl.contestRequest(sample))
+}
+
+// contestRequest returns the request that creates the contest of one occurrence, so
+// template contests go through the validation of POST /contests
+func (tmpl *ContestTemplate) contestRequest(occ ContestOccurrence) CreateContestRequest {
+	return CreateContestRequest{
+		Name:              occ.Name,
+		Prize:             tmpl.Prize,
+		EntryFee:          tmpl.EntryFee,
+		TotalSlots:        tmpl.TotalSlots,
+		MaxEntriesPerUser: tmpl.MaxEntriesPerUser,
+		RosterSize:        occ.RosterSize,
+		IsGuaranteed:      tmpl.IsGuaranteed,
+		MinFillPercent:    tmpl.MinFillPercent,
+		AutoClone:         tmpl.AutoClone,
+		MaxClones:         tmpl.MaxClones,
+		StartDate:         occ.StartDate,
+		EndDate:           occ.EndDate,
+	}
+}
+
+// createContestTemplate stores a validated template
+func createContestTemplate(db *sql.DB, tmpl ContestTemplate) (int, error) {
+	res, err := db.Exec(
+		"INSERT INTO contest_template (name_pattern, prize, prize_structure, entry_fee, total_slots, max_entries_per_user, roster_size, is_guaranteed, min_fill_percent, auto_clone, max_clones, recurrence, sport, start_time, cron_expr, duration_minutes, lead_days, active, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, TRUE, NOW())",
+		tmpl.NamePattern, tmpl.Prize, tmpl.PrizeStructure, tmpl.EntryFee, tmpl.TotalSlots, tmpl.MaxEntriesPerUser, tmpl.RosterSize, tmpl.IsGuaranteed, tmpl.MinFillPercent, tmpl.AutoClone, tmpl.MaxClones, tmpl.Recurrence, tmpl.Sport, tmpl.StartTime, tmpl.CronExpr, tmpl.DurationMinutes, tmpl.LeadDays,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	templateID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(templateID), nil
+}
+
+// templateColumns lists the contest_template columns in the order scanContestTemplate reads them
+const templateColumns = "id, name_pattern, prize, prize_structure, entry_fee, total_slots, max_entries_per_user, roster_size, is_guaranteed, min_fill_percent, auto_clone, max_clones, recurrence, sport, start_time, cron_expr, duration_minutes, lead_days, active, created_at"
+
+// scanContestTemplate reads one template selected with templateColumns
+func scanContestTemplate(row rowScanner) (*ContestTemplate, error) {
+	var tmpl ContestTemplate
+	err := row.Scan(
+		&tmpl.ID,
+		&tmpl.NamePattern,
+		&tmpl.Prize,
+		&tmpl.PrizeStructure,
+		&tmpl.EntryFee,
+		&tmpl.TotalSlots,
+		&tmpl.MaxEntriesPerUser,
+		&tmpl.RosterSize,
+		&tmpl.IsGuaranteed,
+		&tmpl.MinFillPercent,
+		&tmpl.AutoClone,
+		&tmpl.MaxClones,
+		&tmpl.Recurrence,
+		&tmpl.Sport,
+		&tmpl.StartTime,
+		&tmpl.CronExpr,
+		&tmpl.DurationMinutes,
+		&tmpl.LeadDays,
+		&tmpl.Active,
+		&tmpl.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tmpl, nil
+}
+
+// getContestTemplate fetches a template by ID
+func getContestTemplate(db *sql.DB, templateID int) (*ContestTemplate, error) {
+	tmpl, err := scanContestTemplate(db.QueryRow("SELECT "+templateColumns+" FROM contest_template WHERE id = ?", templateID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("template_not_found", "Contest template not found")
+		}
+		return nil, err
+	}
+
+	return tmpl, nil
+}
+
+// getContestTemplates lists templates, optionally only the active ones
+func getContestTemplates(db *sql.DB, activeOnly bool) ([]*ContestTemplate, error) {
+	query := "SELECT " + templateColumns + " FROM contest_template"
+	if activeOnly {
+		query += " WHERE active = TRUE"
+	}
+
+	rows, err := db.Query(query + " ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []*ContestTemplate{}
+	for rows.Next() {
+		tmpl, err := scanContestTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+
+	return templates, rows.Err()
+}
+
+// templateOccurrences lists the contests the template produces starting in [from, to),
+// marking the ones that have already been created
+func templateOccurrences(db *sql.DB, tmpl *ContestTemplate, from, to time.Time) ([]ContestOccurrence, error) {
+	var occurrences []ContestOccurrence
+	if tmpl.Recurrence == recurrenceSlate {
+		slates, err := getUpcomingSlates(db, tmpl.Sport, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, slate := range slates {
+			if len(occurrences) == maxTemplateOccurrences {
+				break
+			}
+			rosterSize := tmpl.RosterSize
+			if rosterSize == 0 {
+				rosterSize = slate.RosterSize
+			}
+			occurrences = append(occurrences, ContestOccurrence{
+				Key:        fmt.Sprintf("slate-%d", slate.ID),
+				SlateID:    slate.ID,
+				RosterSize: rosterSize,
+				StartDate:  slate.StartTime,
+				EndDate:    slate.EndTime,
+				Name:       slate.Name,
+			})
+		}
+	} else {
+		var err error
+		occurrences, err = scheduledOccurrences(tmpl, from, to)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(occurrences) == 0 {
+		return occurrences, nil
+	}
+
+	// Look up the contests already created for these occurrences in one query
+	args := []interface{}{tmpl.ID}
+	for _, occ := range occurrences {
+		args = append(args, occ.Key)
+	}
+	rows, err := db.Query("SELECT occurrence_key, id FROM contest WHERE template_id = ? AND occurrence_key IN ("+placeholders(len(occurrences))+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	created := map[string]int{}
+	for rows.Next() {
+		var key string
+		var contestID int
+		if err := rows.Scan(&key, &contestID); err != nil {
+			return nil, err
+		}
+		created[key] = contestID
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range occurrences {
+		occ := &occurrences[i]
+		occ.TemplateID = tmpl.ID
+		occ.Name = templateContestName(tmpl.NamePattern, occ.StartDate, occ.Name)
+		if contestID, ok := created[occ.Key]; ok {
+			occ.ContestID = &contestID
+		}
+	}
+
+	return occurrences, nil
+}
+
+// scheduledOccurrences lists the occurrences of a daily or cron template starting in
+// [from, to), at most maxTemplateOccurrences of them
+func scheduledOccurrences(tmpl *ContestTemplate, from, to time.Time) ([]ContestOccurrence, error) {
+	var occurrences []ContestOccurrence
+	duration := time.Duration(tmpl.DurationMinutes) * time.Minute
+
+	switch tmpl.Recurrence {
+	case recurrenceDaily:
+		startOfDay, err := time.Parse("15:04", tmpl.StartTime)
+		if err != nil {
+			return nil, err
+		}
+		day := time.Date(from.Year(), from.Month(), from.Day(), startOfDay.Hour(), startOfDay.Minute(), 0, 0, time.UTC)
+		for ; day.Before(to) && len(occurrences) < maxTemplateOccurrences; day = day.AddDate(0, 0, 1) {
+			if day.Before(from) {
+				continue
+			}
+			occurrences = append(occurrences, ContestOccurrence{
+				Key:        day.Format("2006-01-02"),
+				RosterSize: tmpl.RosterSize,
+				StartDate:  day,
+				EndDate:    day.Add(duration),
+			})
+		}
+
+	case recurrenceCron:
+		schedule, err := cron.ParseStandard(tmpl.CronExpr)
+		if err != nil {
+			return nil, err
+		}
+		// Step back a second so a tick falling exactly on from is included. Next returns
+		// the zero time once the schedule has no further ticks.
+		for start := schedule.Next(from.Add(-time.Second)); !start.IsZero() && start.Before(to) && len(occurrences) < maxTemplateOccurrences; start = schedule.Next(start) {
+			occurrences = append(occurrences, ContestOccurrence{
+				Key:        start.UTC().Format(time.RFC3339),
+				RosterSize: tmpl.RosterSize,
+				StartDate:  start.UTC(),
+				EndDate:    start.UTC().Add(duration),
+			})
+		}
+	}
+
+	return occurrences, nil
+}
+
+// templateContestName fills the placeholders of a template's name pattern
+func templateContestName(pattern string, start time.Time, slateName string) string {
+	return strings.NewReplacer(
+		"{date}", start.Format("Jan 2"),
+		"{time}", start.Format("15:04"),
+		"{slate}", slateName,
+	).Replace(pattern)
+}
+
+// generateContests creates every missing contest of the active templates within their
+// lead window and returns how many were created. Running it again creates nothing new.
+func generateContests(db *sql.DB, now time.Time) (int, error) {
+	templates, err := getContestTemplates(db, true)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, tmpl := range templates {
+		occurrences, err := templateOccurrences(db, tmpl, now, now.AddDate(0, 0, tmpl.LeadDays))
+		if err != nil {
+			return created, err
+		}
+
+		for _, occ := range occurrences {
+			if occ.ContestID != nil {
+				continue
+			}
+
+			// A slate name or a template stored before validation may not make a valid contest
+			if err := validateRequest(tmpl.contestRequest(occ)); err != nil {
+				log.Printf("templates: skipping occurrence %s of template %d: %v", occ.Key, tmpl.ID, err)
+				continue
+			}
+
+			ok, err := createTemplateContest(db, tmpl, occ)
+			if err != nil {
+				return created, err
+			}
+			if ok {
+				created++
+			}
+		}
+	}
+
+	return created, nil
+}
+
+// createTemplateContest creates the contest for one occurrence. It reports false when
+// another generator run created it first.
+func createTemplateContest(db *sql.DB, tmpl *ContestTemplate, occ ContestOccurrence) (bool, error) {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	contest := tmpl.contestRequest(occ).toContest()
+	contest.PrizeStructure = tmpl.PrizeStructure
+	contest.SlateID = occ.SlateID
+
+	contestID, err := insertContest(tx, contest)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	// The unique (template_id, occurrence_key) index rejects this when a concurrent
+	// run created the same occurrence first
+	_, err = tx.Exec("UPDATE contest SET template_id = ?, occurrence_key = ? WHERE id = ?", tmpl.ID, occ.Key, contestID)
+	if err != nil {
+		tx.Rollback()
+		if isDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// runContestGeneratorJob periodically creates upcoming contests from templates
+func runContestGeneratorJob(ctx context.Context, db *sql.DB) {
+	ticker := time.NewTicker(contestGeneratorInterval)
+	defer ticker.Stop()
+
+	for {
+		created, err := generateContests(db, time.Now().UTC())
+		if err != nil {
+			log.Printf("templates: failed to generate contests: %v", err)
+		} else if created > 0 {
+			log.Printf("templates: created %d contests", created)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
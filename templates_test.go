@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduledOccurrences(t *testing.T) {
+	from := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		tmpl  ContestTemplate
+		to    time.Time
+		count int
+		first time.Time
+	}{
+		// The 09:00 start of the first day is before from, so it is skipped
+		{"daily", ContestTemplate{Recurrence: recurrenceDaily, StartTime: "09:00", DurationMinutes: 60}, from.AddDate(0, 0, 3), 3, time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC)},
+		{"daily later today", ContestTemplate{Recurrence: recurrenceDaily, StartTime: "18:30", DurationMinutes: 60}, from.AddDate(0, 0, 1), 1, time.Date(2026, 3, 2, 18, 30, 0, 0, time.UTC)},
+		// A tick falling exactly on from is included
+		{"cron", ContestTemplate{Recurrence: recurrenceCron, CronExpr: "0 12 * * *", DurationMinutes: 60}, from.AddDate(0, 0, 2), 2, from},
+		{"cron weekly", ContestTemplate{Recurrence: recurrenceCron, CronExpr: "0 20 * * 5", DurationMinutes: 60}, from.AddDate(0, 0, 14), 2, time.Date(2026, 3, 6, 20, 0, 0, 0, time.UTC)},
+		// Expressions that never fire stop instead of looping forever
+		{"cron never fires", ContestTemplate{Recurrence: recurrenceCron, CronExpr: "0 0 30 2 *", DurationMinutes: 60}, from.AddDate(1, 0, 0), 0, time.Time{}},
+		// However long the window, one call produces at most maxTemplateOccurrences
+		{"daily capped", ContestTemplate{Recurrence: recurrenceDaily, StartTime: "09:00", DurationMinutes: 60}, from.AddDate(5, 0, 0), maxTemplateOccurrences, time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC)},
+		{"cron capped", ContestTemplate{Recurrence: recurrenceCron, CronExpr: "0 * * * *", DurationMinutes: 30}, from.AddDate(1, 0, 0), maxTemplateOccurrences, from},
+	}
+
+	for _, tt := range tests {
+		occurrences, err := scheduledOccurrences(&tt.tmpl, from, tt.to)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(occurrences) != tt.count {
+			t.Errorf("%s: %d occurrences, want %d", tt.name, len(occurrences), tt.count)
+			continue
+		}
+		if tt.count == 0 {
+			continue
+		}
+
+		first := occurrences[0]
+		if !first.StartDate.Equal(tt.first) {
+			t.Errorf("%s: first occurrence starts at %s, want %s", tt.name, first.StartDate, tt.first)
+		}
+		if want := first.StartDate.Add(time.Duration(tt.tmpl.DurationMinutes) * time.Minute); !first.EndDate.Equal(want) {
+			t.Errorf("%s: first occurrence ends at %s, want %s", tt.name, first.EndDate, want)
+		}
+
+		keys := make(map[string]bool, len(occurrences))
+		for _, occ := range occurrences {
+			if keys[occ.Key] {
+				t.Errorf("%s: key %s is used twice", tt.name, occ.Key)
+			}
+			keys[occ.Key] = true
+		}
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	valid := func() ContestTemplate {
+		return ContestTemplate{
+			NamePattern:     "Daily {date} {time}",
+			TotalSlots:      100,
+			Recurrence:      recurrenceDaily,
+			StartTime:       "19:00",
+			DurationMinutes: 180,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(*ContestTemplate)
+		field  string
+	}{
+		{"valid daily", func(tmpl *ContestTemplate) {}, ""},
+		{"valid cron", func(tmpl *ContestTemplate) { tmpl.Recurrence, tmpl.CronExpr = recurrenceCron, "0 */2 * * *" }, ""},
+		{"valid slate", func(tmpl *ContestTemplate) { tmpl.Recurrence, tmpl.Sport = recurrenceSlate, "nfl" }, ""},
+		{"bad start time", func(tmpl *ContestTemplate) { tmpl.StartTime = "7pm" }, "start_time"},
+		{"unknown prize structure", func(tmpl *ContestTemplate) { tmpl.PrizeStructure = "lottery" }, "prize_structure"},
+		{"invalid cron", func(tmpl *ContestTemplate) { tmpl.Recurrence, tmpl.CronExpr = recurrenceCron, "every day" }, "cron_expr"},
+		{"cron never fires", func(tmpl *ContestTemplate) { tmpl.Recurrence, tmpl.CronExpr = recurrenceCron, "0 0 30 2 *" }, "cron_expr"},
+		{"cron every minute", func(tmpl *ContestTemplate) { tmpl.Recurrence, tmpl.CronExpr = recurrenceCron, "* * * * *" }, "cron_expr"},
+		{"cron twice an hour", func(tmpl *ContestTemplate) { tmpl.Recurrence, tmpl.CronExpr = recurrenceCron, "0,30 9 * * *" }, "cron_expr"},
+		// The contests a template creates are validated like POST /contests
+		{"name outside the charset", func(tmpl *ContestTemplate) { tmpl.NamePattern = "Daily <b>{date}</b>" }, "name"},
+		{"negative entry fee", func(tmpl *ContestTemplate) { tmpl.EntryFee = -5 }, "entry_fee"},
+		{"one slot", func(tmpl *ContestTemplate) { tmpl.TotalSlots = 1 }, "total_slots"},
+	}
+
+	for _, tt := range tests {
+		tmpl := valid()
+		tt.change(&tmpl)
+
+		err := validateTemplate(&tmpl)
+		if tt.field == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("%s: got %v, want an error on %s", tt.name, err, tt.field)
+			continue
+		}
+		found := false
+		for _, f := range apiErr.Fields {
+			found = found || f.Field == tt.field
+		}
+		if !found {
+			t.Errorf("%s: got %v with fields %v, want an error on %s", tt.name, apiErr, apiErr.Fields, tt.field)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+-- The contest, team, users and user_contest tables as they were before schema.sql,
+-- so tests can build a database from scratch: apply this file, then schema.sql.
+CREATE TABLE contest (
+    id              INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name            VARCHAR(255) NOT NULL,
+    prize           DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    total_slots     INT NOT NULL,
+    remaining_slots INT NOT NULL,
+    start_date      DATETIME NOT NULL,
+    end_date        DATETIME NOT NULL,
+    status          VARCHAR(16) NOT NULL,
+    active_date     DATETIME NOT NULL,
+    created_at      DATETIME NOT NULL
+);
+
+CREATE TABLE team (
+    id          INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    name        VARCHAR(50) NOT NULL,
+    displayname VARCHAR(100) NOT NULL DEFAULT '',
+    created_at  DATETIME NOT NULL
+);
+
+CREATE TABLE users (
+    id                  INT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    age                 INT NOT NULL,
+    selected_contest_id INT NULL
+);
+
+CREATE TABLE user_contest (
+    user_id    INT NOT NULL,
+    contest_id INT NOT NULL,
+    PRIMARY KEY (user_id, contest_id)
+);
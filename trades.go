@@ -0,0 +1,945 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Trade statuses
+const (
+	tradeProposed  = "proposed"
+	tradeInReview  = "in_review"
+	tradeRejected  = "rejected"
+	tradeCountered = "countered"
+	tradeCancelled = "cancelled"
+	tradeVetoed    = "vetoed"
+	tradeExecuted  = "executed"
+	tradeFailed    = "failed"
+)
+
+// Who can veto a trade during its review period
+const (
+	vetoNone         = "none"
+	vetoCommissioner = "commissioner"
+	vetoLeagueVote   = "league_vote"
+)
+
+const (
+	// How a player joined a league team's roster, alongside the draft and waiver ones
+	acquiredTrade = "trade"
+
+	// How often accepted trades whose review period has ended are executed
+	tradeReviewInterval = time.Minute
+)
+
+// TradeSettings is the request body used by a commissioner to configure trade review
+type TradeSettings struct {
+	ReviewHours int    `json:"review_hours"`
+	Veto        string `json:"veto"`
+}
+
+// TradeProposal is the request body used to propose or counter a trade. The proposer
+// gives OfferPlayerIDs and OfferFAAB and gets RequestPlayerIDs and RequestFAAB.
+type TradeProposal struct {
+	ReceiverTeamID   int   `json:"receiver_team_id"`
+	OfferPlayerIDs   []int `json:"offer_player_ids"`
+	RequestPlayerIDs []int `json:"request_player_ids"`
+	OfferFAAB        int   `json:"offer_faab"`
+	RequestFAAB      int   `json:"request_faab"`
+}
+
+// Trade is a proposed exchange of players and FAAB between two league teams
+type Trade struct {
+	ID               int        `json:"id"`
+	LeagueID         int        `json:"league_id"`
+	ProposerTeamID   int        `json:"proposer_team_id"`
+	ReceiverTeamID   int        `json:"receiver_team_id"`
+	OfferPlayerIDs   []int      `json:"offer_player_ids"`
+	RequestPlayerIDs []int      `json:"request_player_ids"`
+	OfferFAAB        int        `json:"offer_faab"`
+	RequestFAAB      int        `json:"request_faab"`
+	Status           string     `json:"status"`
+	CounterOf        *int       `json:"counter_of,omitempty"`
+	ReviewEndsAt     *time.Time `json:"review_ends_at,omitempty"`
+	VetoVotes        int        `json:"veto_votes"`
+	Reason           string     `json:"reason,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func setupTradeRoutes(r *gin.Engine, db *sql.DB) {
+	// Route for the commissioner to configure the trade review period and vetoes
+	r.PUT("/leagues/:id/trade-settings", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		var req TradeSettings
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		if err := updateTradeSettings(db, userID, leagueID, req); err != nil {
+			c.Error(apiError(err, "Failed to update trade settings"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Trade settings updated successfully"})
+	})
+
+	// Route to propose a trade to another team in the league
+	r.POST("/leagues/:id/trades", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		var req TradeProposal
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		tradeID, err := proposeTrade(db, userID, leagueID, req)
+		if err != nil {
+			c.Error(apiError(err, "Failed to propose the trade"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Trade proposed successfully", "trade_id": tradeID})
+	})
+
+	// Route to list a league's trades
+	r.GET("/leagues/:id/trades", func(c *gin.Context) {
+		leagueID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid league ID"))
+			return
+		}
+
+		trades, err := getTrades(db, leagueID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch trades"))
+			return
+		}
+
+		c.JSON(http.StatusOK, trades)
+	})
+
+	// Route for the receiving team to accept a trade
+	r.POST("/leagues/:id/trades/:tradeID/accept", func(c *gin.Context) {
+		tradeRoute(c, db, "accept the trade", acceptTrade)
+	})
+
+	// Route for the receiving team to reject a trade
+	r.POST("/leagues/:id/trades/:tradeID/reject", func(c *gin.Context) {
+		tradeRoute(c, db, "reject the trade", rejectTrade)
+	})
+
+	// Route for the proposing team to withdraw a trade that has not been answered
+	r.DELETE("/leagues/:id/trades/:tradeID", func(c *gin.Context) {
+		tradeRoute(c, db, "cancel the trade", cancelTrade)
+	})
+
+	// Route to veto a trade under review, by the commissioner or by a vote of the other teams
+	r.POST("/leagues/:id/trades/:tradeID/veto", func(c *gin.Context) {
+		tradeRoute(c, db, "veto the trade", vetoTrade)
+	})
+
+	// Route for the receiving team to answer a trade with a counter-proposal
+	r.POST("/leagues/:id/trades/:tradeID/counter", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		tradeID, err := strconv.Atoi(c.Param("tradeID"))
+		if err != nil {
+			c.Error(invalidParam("tradeID", "Invalid trade ID"))
+			return
+		}
+
+		var req TradeProposal
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		counterID, err := counterTrade(db, userID, leagueID, tradeID, req)
+		if err != nil {
+			c.Error(apiError(err, "Failed to counter the trade"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Trade countered successfully", "trade_id": counterID})
+	})
+}
+
+// tradeRoute runs one of the trade actions that only need the user, league and trade
+func tradeRoute(c *gin.Context, db *sql.DB, action string, fn func(*sql.DB, int, int, int) error) {
+	userID, leagueID, ok := leagueRequest(c)
+	if !ok {
+		return
+	}
+
+	tradeID, err := strconv.Atoi(c.Param("tradeID"))
+	if err != nil {
+		c.Error(invalidParam("tradeID", "Invalid trade ID"))
+		return
+	}
+
+	if err := fn(db, userID, leagueID, tradeID); err != nil {
+		c.Error(apiError(err, "Failed to "+action))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Trade updated successfully"})
+}
+
+// updateTradeSettings changes the league's trade review period and who can veto
+func updateTradeSettings(db *sql.DB, userID int, leagueID int, req TradeSettings) error {
+	switch req.Veto {
+	case vetoNone, vetoCommissioner, vetoLeagueVote:
+	default:
+		return invalidField("invalid_veto", "veto", "Unknown veto type")
+	}
+	if req.ReviewHours < 0 {
+		return invalidField("invalid_review_hours", "review_hours", "The review period cannot be negative")
+	}
+
+	commissionerID, err := leagueCommissioner(db, leagueID)
+	if err != nil {
+		return err
+	}
+	if commissionerID != userID {
+		return forbiddenError("not_commissioner", "Only the commissioner can change trade settings")
+	}
+
+	_, err = db.Exec("UPDATE league SET trade_review_hours = ?, trade_veto = ? WHERE id = ?", req.ReviewHours, req.Veto, leagueID)
+	return err
+}
+
+// proposeTrade stores a trade from the user's team to another team in the league
+func proposeTrade(db *sql.DB, userID int, leagueID int, p TradeProposal) (int, error) {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	l, err := lockWaiverLeague(tx, leagueID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	teamID, err := userLeagueTeam(tx, leagueID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	tradeID, err := insertTrade(tx, l, teamID, p, 0)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return tradeID, nil
+}
+
+// insertTrade checks a proposal from proposerTeamID against the current rosters and
+// FAAB balances, stores it and lets the receiving team know
+func insertTrade(tx *sql.Tx, l *waiverLeague, proposerTeamID int, p TradeProposal, counterOf int) (int, error) {
+	if p.ReceiverTeamID == proposerTeamID {
+		return 0, invalidField("self_trade", "receiver_team_id", "You cannot trade with yourself")
+	}
+	if len(p.OfferPlayerIDs)+len(p.RequestPlayerIDs) == 0 {
+		return 0, validationError("empty_trade", "A trade must include at least one player")
+	}
+	if p.OfferFAAB < 0 || p.RequestFAAB < 0 {
+		return 0, validationError("negative_faab", "FAAB amounts cannot be negative")
+	}
+
+	var receiverLeagueID int
+	err := tx.QueryRow("SELECT IFNULL(league_id, 0) FROM team WHERE id = ?", p.ReceiverTeamID).Scan(&receiverLeagueID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+	if receiverLeagueID != l.ID {
+		return 0, invalidField("team_not_in_league", "receiver_team_id", "The other team is not in this league")
+	}
+
+	seen := make(map[int]bool)
+	for _, side := range []struct {
+		teamID    int
+		playerIDs []int
+	}{{proposerTeamID, p.OfferPlayerIDs}, {p.ReceiverTeamID, p.RequestPlayerIDs}} {
+		for _, playerID := range side.playerIDs {
+			if seen[playerID] {
+				return 0, validationError("duplicate_player", "A player can only be in a trade once")
+			}
+			seen[playerID] = true
+
+			ownerTeamID, err := rosteredBy(tx, l.ID, playerID)
+			if err != nil {
+				return 0, err
+			}
+			if ownerTeamID != side.teamID {
+				return 0, conflictError("player_not_on_roster", fmt.Sprintf("Player %d is not on the expected roster", playerID))
+			}
+		}
+	}
+
+	for _, side := range []struct {
+		teamID int
+		amount int
+	}{{proposerTeamID, p.OfferFAAB}, {p.ReceiverTeamID, p.RequestFAAB}} {
+		balance, err := faabBalance(tx, l, side.teamID)
+		if err != nil {
+			return 0, err
+		}
+		if side.amount > balance {
+			return 0, conflictError("insufficient_faab", "A team does not have enough FAAB for this trade")
+		}
+	}
+
+	var counter sql.NullInt64
+	if counterOf != 0 {
+		counter = sql.NullInt64{Int64: int64(counterOf), Valid: true}
+	}
+
+	res, err := tx.Exec(
+		"INSERT INTO trade (league_id, proposer_team_id, receiver_team_id, offer_faab, request_faab, status, counter_of, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, NOW())",
+		l.ID, proposerTeamID, p.ReceiverTeamID, p.OfferFAAB, p.RequestFAAB, tradeProposed, counter,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	tradeID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, playerID := range p.OfferPlayerIDs {
+		if _, err := tx.Exec("INSERT INTO trade_player (trade_id, player_id, from_team_id) VALUES (?, ?, ?)", tradeID, playerID, proposerTeamID); err != nil {
+			return 0, err
+		}
+	}
+	for _, playerID := range p.RequestPlayerIDs {
+		if _, err := tx.Exec("INSERT INTO trade_player (trade_id, player_id, from_team_id) VALUES (?, ?, ?)", tradeID, playerID, p.ReceiverTeamID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := notifyTeam(tx, p.ReceiverTeamID, notificationTradeProposed, fmt.Sprintf("You have received trade offer %d.", tradeID)); err != nil {
+		return 0, err
+	}
+
+	return int(tradeID), nil
+}
+
+// lockTrade reads a league trade and its players, holding the trade row until tx ends
+func lockTrade(tx *sql.Tx, leagueID int, tradeID int) (*Trade, error) {
+	t, err := scanTrade(tx.QueryRow(
+		"SELECT "+tradeColumns+" FROM trade WHERE id = ? AND league_id = ? FOR UPDATE",
+		tradeID, leagueID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFoundError("trade_not_found", "Trade not found")
+		}
+		return nil, err
+	}
+
+	if err := loadTradePlayers(tx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+const tradeColumns = "id, league_id, proposer_team_id, receiver_team_id, offer_faab, request_faab, status, counter_of, review_ends_at, IFNULL(reason, ''), created_at, (SELECT COUNT(*) FROM trade_vote v WHERE v.trade_id = trade.id)"
+
+func scanTrade(row rowScanner) (*Trade, error) {
+	var t Trade
+	var counterOf sql.NullInt64
+	var reviewEndsAt sql.NullTime
+	err := row.Scan(&t.ID, &t.LeagueID, &t.ProposerTeamID, &t.ReceiverTeamID, &t.OfferFAAB, &t.RequestFAAB, &t.Status, &counterOf, &reviewEndsAt, &t.Reason, &t.CreatedAt, &t.VetoVotes)
+	if err != nil {
+		return nil, err
+	}
+	if counterOf.Valid {
+		id := int(counterOf.Int64)
+		t.CounterOf = &id
+	}
+	if reviewEndsAt.Valid {
+		t.ReviewEndsAt = &reviewEndsAt.Time
+	}
+
+	return &t, nil
+}
+
+// loadTradePlayers fills in which players each side gives up
+func loadTradePlayers(q queryer, t *Trade) error {
+	rows, err := q.Query("SELECT player_id, from_team_id FROM trade_player WHERE trade_id = ? ORDER BY player_id", t.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	t.OfferPlayerIDs = []int{}
+	t.RequestPlayerIDs = []int{}
+	for rows.Next() {
+		var playerID, fromTeamID int
+		if err := rows.Scan(&playerID, &fromTeamID); err != nil {
+			return err
+		}
+		if fromTeamID == t.ProposerTeamID {
+			t.OfferPlayerIDs = append(t.OfferPlayerIDs, playerID)
+		} else {
+			t.RequestPlayerIDs = append(t.RequestPlayerIDs, playerID)
+		}
+	}
+
+	return rows.Err()
+}
+
+// getTrades returns a league's trades, newest first
+func getTrades(db *sql.DB, leagueID int) ([]Trade, error) {
+	rows, err := db.Query("SELECT "+tradeColumns+" FROM trade WHERE league_id = ? ORDER BY created_at DESC, id DESC", leagueID)
+	if err != nil {
+		return nil, err
+	}
+
+	var trades []*Trade
+	for rows.Next() {
+		t, err := scanTrade(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		trades = append(trades, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]Trade, len(trades))
+	for i, t := range trades {
+		if err := loadTradePlayers(db, t); err != nil {
+			return nil, err
+		}
+		result[i] = *t
+	}
+
+	return result, nil
+}
+
+// openTrade locks the league and a trade, checking the trade is still in status
+func openTrade(tx *sql.Tx, leagueID int, tradeID int, status string) (*waiverLeague, *Trade, error) {
+	l, err := lockWaiverLeague(tx, leagueID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t, err := lockTrade(tx, leagueID, tradeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if t.Status != status {
+		return nil, nil, conflictError("trade_closed", "The trade can no longer be changed")
+	}
+
+	return l, t, nil
+}
+
+// acceptTrade accepts a trade for the receiving team. It starts the review period, or
+// executes straight away when the league has no review period or vetoes.
+func acceptTrade(db *sql.DB, userID int, leagueID int, tradeID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	l, t, err := openTrade(tx, leagueID, tradeID, tradeProposed)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	teamID, err := userLeagueTeam(tx, leagueID, userID)
+	if err != nil || teamID != t.ReceiverTeamID {
+		tx.Rollback()
+		return forbiddenError("not_trade_receiver", "Only the receiving team can accept the trade")
+	}
+
+	var reviewHours int
+	var veto string
+	err = tx.QueryRow("SELECT trade_review_hours, trade_veto FROM league WHERE id = ?", leagueID).Scan(&reviewHours, &veto)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if reviewHours == 0 || veto == vetoNone {
+		err = executeTrade(tx, l, t)
+	} else {
+		reviewEndsAt := time.Now().Add(time.Duration(reviewHours) * time.Hour)
+		_, err = tx.Exec("UPDATE trade SET status = ?, review_ends_at = ? WHERE id = ?", tradeInReview, reviewEndsAt, tradeID)
+		if err == nil {
+			err = notifyTeam(tx, t.ProposerTeamID, notificationTradeAccepted, fmt.Sprintf("Trade %d was accepted and is under league review until %s.", tradeID, reviewEndsAt.Format(time.RFC1123)))
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// rejectTrade turns a trade down for the receiving team
+func rejectTrade(db *sql.DB, userID int, leagueID int, tradeID int) error {
+	return closeTrade(db, userID, leagueID, tradeID, false, tradeRejected)
+}
+
+// cancelTrade withdraws a trade for the proposing team
+func cancelTrade(db *sql.DB, userID int, leagueID int, tradeID int) error {
+	return closeTrade(db, userID, leagueID, tradeID, true, tradeCancelled)
+}
+
+// closeTrade ends an unanswered trade on behalf of one of its two teams
+func closeTrade(db *sql.DB, userID int, leagueID int, tradeID int, byProposer bool, status string) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, t, err := openTrade(tx, leagueID, tradeID, tradeProposed)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	actor, other := t.ReceiverTeamID, t.ProposerTeamID
+	if byProposer {
+		actor, other = other, actor
+	}
+
+	teamID, err := userLeagueTeam(tx, leagueID, userID)
+	if err != nil || teamID != actor {
+		tx.Rollback()
+		return forbiddenError("not_trade_party", "You cannot change this trade")
+	}
+
+	_, err = tx.Exec("UPDATE trade SET status = ? WHERE id = ?", status, tradeID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := notifyTeam(tx, other, notificationTradeClosed, fmt.Sprintf("Trade %d was %s.", tradeID, status)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// counterTrade replaces a trade with the receiving team's counter-proposal. The counter
+// is a new trade going the other way; the original is marked countered.
+func counterTrade(db *sql.DB, userID int, leagueID int, tradeID int, p TradeProposal) (int, error) {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	l, t, err := openTrade(tx, leagueID, tradeID, tradeProposed)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	teamID, err := userLeagueTeam(tx, leagueID, userID)
+	if err != nil || teamID != t.ReceiverTeamID {
+		tx.Rollback()
+		return 0, forbiddenError("not_trade_receiver", "Only the receiving team can counter the trade")
+	}
+
+	_, err = tx.Exec("UPDATE trade SET status = ? WHERE id = ?", tradeCountered, tradeID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	p.ReceiverTeamID = t.ProposerTeamID
+	counterID, err := insertTrade(tx, l, teamID, p, tradeID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return counterID, nil
+}
+
+// vetoTrade vetoes a trade under review. With commissioner vetoes the commissioner's
+// veto is final; with league votes each team outside the trade gets one vote and the
+// trade is vetoed once half of them, rounded up, have voted.
+func vetoTrade(db *sql.DB, userID int, leagueID int, tradeID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	_, t, err := openTrade(tx, leagueID, tradeID, tradeInReview)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var commissionerID, teams int
+	var veto string
+	err = tx.QueryRow(
+		"SELECT commissioner_id, trade_veto, (SELECT COUNT(*) FROM team WHERE league_id = league.id) FROM league WHERE id = ?",
+		leagueID,
+	).Scan(&commissionerID, &veto, &teams)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	vetoed := false
+	switch veto {
+	case vetoCommissioner:
+		if commissionerID != userID {
+			tx.Rollback()
+			return forbiddenError("not_commissioner", "Only the commissioner can veto trades")
+		}
+		vetoed = true
+	case vetoLeagueVote:
+		teamID, err := userLeagueTeam(tx, leagueID, userID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if teamID == t.ProposerTeamID || teamID == t.ReceiverTeamID {
+			tx.Rollback()
+			return forbiddenError("trade_party_vote", "Teams in the trade cannot vote on it")
+		}
+
+		_, err = tx.Exec("INSERT INTO trade_vote (trade_id, team_id, created_at) VALUES (?, ?, NOW())", tradeID, teamID)
+		if err != nil {
+			tx.Rollback()
+			if isDuplicateKeyError(err) {
+				return conflictError("already_voted", "You have already voted to veto this trade")
+			}
+			return err
+		}
+
+		uninvolved := teams - 2
+		vetoed = t.VetoVotes+1 >= (uninvolved+1)/2
+	default:
+		tx.Rollback()
+		return conflictError("vetoes_disabled", "Trades cannot be vetoed in this league")
+	}
+
+	if vetoed {
+		_, err = tx.Exec("UPDATE trade SET status = ? WHERE id = ?", tradeVetoed, tradeID)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		for _, teamID := range []int{t.ProposerTeamID, t.ReceiverTeamID} {
+			if err := notifyTeam(tx, teamID, notificationTradeClosed, fmt.Sprintf("Trade %d was vetoed.", tradeID)); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// executeTrade swaps the players and FAAB of a trade. The rosters are checked again
+// here since they may have changed since the trade was proposed; a trade that no longer
+// works is marked failed with the reason rather than returning an error.
+func executeTrade(tx *sql.Tx, l *waiverLeague, t *Trade) error {
+	reason, err := tradeProblem(tx, l, t)
+	if err != nil {
+		return err
+	}
+
+	if reason != "" {
+		_, err = tx.Exec("UPDATE trade SET status = ?, reason = ? WHERE id = ?", tradeFailed, reason, t.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, teamID := range []int{t.ProposerTeamID, t.ReceiverTeamID} {
+			if err := notifyTeam(tx, teamID, notificationTradeClosed, fmt.Sprintf("Trade %d could not be completed: %s.", t.ID, reason)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, move := range []struct {
+		from, to  int
+		playerIDs []int
+	}{{t.ProposerTeamID, t.ReceiverTeamID, t.OfferPlayerIDs}, {t.ReceiverTeamID, t.ProposerTeamID, t.RequestPlayerIDs}} {
+		for _, playerID := range move.playerIDs {
+			_, err = tx.Exec(
+				"UPDATE team_player SET team_id = ?, acquired_via = ?, acquired_at = NOW() WHERE team_id = ? AND player_id = ?",
+				move.to, acquiredTrade, move.from, playerID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// FAAB changes hands by moving spend between the two teams
+	net := t.OfferFAAB - t.RequestFAAB
+	if net != 0 {
+		if _, err := tx.Exec("UPDATE team SET faab_spent = faab_spent + ? WHERE id = ?", net, t.ProposerTeamID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE team SET faab_spent = faab_spent - ? WHERE id = ?", net, t.ReceiverTeamID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE trade SET status = ? WHERE id = ?", tradeExecuted, t.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, teamID := range []int{t.ProposerTeamID, t.ReceiverTeamID} {
+		if err := notifyTeam(tx, teamID, notificationTradeExecuted, fmt.Sprintf("Trade %d has gone through.", t.ID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tradeProblem returns why a trade cannot be executed right now, or "" if it can
+func tradeProblem(tx *sql.Tx, l *waiverLeague, t *Trade) (string, error) {
+	for _, side := range []struct {
+		teamID    int
+		playerIDs []int
+	}{{t.ProposerTeamID, t.OfferPlayerIDs}, {t.ReceiverTeamID, t.RequestPlayerIDs}} {
+		for _, playerID := range side.playerIDs {
+			ownerTeamID, err := rosteredBy(tx, l.ID, playerID)
+			if err != nil {
+				return "", err
+			}
+			if ownerTeamID != side.teamID {
+				return fmt.Sprintf("player %d is no longer on the roster", playerID), nil
+			}
+		}
+	}
+
+	for _, side := range []struct {
+		teamID    int
+		in, out   int
+		faabGiven int
+	}{
+		{t.ProposerTeamID, len(t.RequestPlayerIDs), len(t.OfferPlayerIDs), t.OfferFAAB},
+		{t.ReceiverTeamID, len(t.OfferPlayerIDs), len(t.RequestPlayerIDs), t.RequestFAAB},
+	} {
+		count, err := rosterCount(tx, side.teamID)
+		if err != nil {
+			return "", err
+		}
+		if count-side.out+side.in > l.RosterLimit {
+			return fmt.Sprintf("team %d would go over the roster limit of %d", side.teamID, l.RosterLimit), nil
+		}
+
+		balance, err := faabBalance(tx, l, side.teamID)
+		if err != nil {
+			return "", err
+		}
+		if side.faabGiven > balance {
+			return fmt.Sprintf("team %d does not have enough FAAB left", side.teamID), nil
+		}
+	}
+
+	return "", nil
+}
+
+// processTrades executes every accepted trade whose review period has ended
+func processTrades(db *sql.DB) error {
+	rows, err := db.Query("SELECT id, league_id FROM trade WHERE status = ? AND review_ends_at <= NOW() ORDER BY review_ends_at, id", tradeInReview)
+	if err != nil {
+		return err
+	}
+
+	type pending struct{ tradeID, leagueID int }
+	var trades []pending
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.tradeID, &p.leagueID); err != nil {
+			rows.Close()
+			return err
+		}
+		trades = append(trades, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range trades {
+		if err := executeReviewedTrade(db, p.leagueID, p.tradeID); err != nil {
+			return fmt.Errorf("trade %d: %v", p.tradeID, err)
+		}
+	}
+
+	return nil
+}
+
+// executeReviewedTrade executes one trade that made it through review without a veto
+func executeReviewedTrade(db *sql.DB, leagueID int, tradeID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	l, err := lockWaiverLeague(tx, leagueID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	t, err := lockTrade(tx, leagueID, tradeID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Vetoed in the meantime, or already executed by another run
+	if t.Status != tradeInReview {
+		tx.Rollback()
+		return nil
+	}
+
+	if err := executeTrade(tx, l, t); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// runTradeJob periodically executes trades whose review period has ended
+func runTradeJob(ctx context.Context, db *sql.DB) {
+	ticker := time.NewTicker(tradeReviewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := processTrades(db); err != nil {
+			log.Printf("trades: failed to execute reviewed trades: %v", err)
+		}
+	}
+}
+
+// notifyTeam notifies the owner of a league team
+func notifyTeam(tx *sql.Tx, teamID int, kind, message string) error {
+	ownerID, err := teamOwner(tx, teamID)
+	if err != nil {
+		return err
+	}
+
+	return notifyUser(tx, ownerID, kind, message)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// nameCharset matches names made of letters, digits, spaces and a few punctuation
+// marks, starting with a letter or digit
+var nameCharset = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} '&.:\-]*$`)
+
+// registerValidators teaches gin's validator the custom tags used by the request types
+// and makes it report fields by their JSON names
+func registerValidators() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unexpected validator engine")
+	}
+
+	v.RegisterTagNameFunc(jsonFieldName)
+
+	err := v.RegisterValidation("name_charset", func(fl validator.FieldLevel) bool {
+		return nameCharset.MatchString(fl.Field().String())
+	})
+	if err != nil {
+		return err
+	}
+
+	err = v.RegisterValidation("future", func(fl validator.FieldLevel) bool {
+		t, ok := fl.Field().Interface().(time.Time)
+		return ok && t.After(time.Now())
+	})
+	if err != nil {
+		return err
+	}
+
+	v.RegisterStructValidation(validateContestDates, CreateContestRequest{}, PrivateContestRequest{}, ContestRulesChange{})
+
+	return nil
+}
+
+// jsonFieldName returns the name a struct field has in JSON
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// validateContestDates checks that a contest ends after it starts. A rules change
+// that only sets one of the dates is checked against the stored one when applied.
+func validateContestDates(sl validator.StructLevel) {
+	var startDate, endDate time.Time
+	switch req := sl.Current().Interface().(type) {
+	case CreateContestRequest:
+		startDate, endDate = req.StartDate, req.EndDate
+	case PrivateContestRequest:
+		startDate, endDate = req.StartDate, req.EndDate
+	case ContestRulesChange:
+		if req.StartDate == nil || req.EndDate == nil {
+			return
+		}
+		startDate, endDate = *req.StartDate, *req.EndDate
+	}
+
+	if !startDate.IsZero() && !endDate.IsZero() && !endDate.After(startDate) {
+		sl.ReportError(endDate, "end_date", "EndDate", "after_start_date", "")
+	}
+}
+
+// bindJSON decodes the request body into req and validates it against its binding tags
+func bindJSON(c *gin.Context, req interface{}) error {
+	err := c.ShouldBindJSON(req)
+	if err == nil {
+		return nil
+	}
+
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return invalidBody(err)
+	}
+
+	return validationFailed(invalid)
+}
+
+// validateRequest validates req against its binding tags, for requests that do not
+// arrive as JSON, such as gRPC calls mapped onto the same request types
+func validateRequest(req interface{}) error {
+	err := binding.Validator.ValidateStruct(req)
+	if err == nil {
+		return nil
+	}
+
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+
+	return validationFailed(invalid)
+}
+
+// validationFailed reports every invalid field in a single validation error
+func validationFailed(invalid validator.ValidationErrors) *APIError {
+	fields := make([]FieldError, len(invalid))
+	for i, fe := range invalid {
+		fields[i] = fieldError(fe.Field(), validationMessage(fe))
+	}
+
+	return validationError("validation_failed", "The request has invalid fields", fields...)
+}
+
+// validationMessage describes a failed validation tag in words
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max", "lte":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "name_charset":
+		return "must start with a letter or digit and only contain letters, digits, spaces and ' & . : -"
+	case "future":
+		return "must be in the future"
+	case "after_start_date":
+		return "must be after start_date"
+	case "http_url":
+		return "must be an http or https URL"
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(fe.Param()), ", "))
+	}
+	return "is invalid"
+}
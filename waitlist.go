@@ -0,0 +1,571 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Waitlist spot statuses
+const (
+	waitlistWaiting   = "waiting"
+	waitlistPromoted  = "promoted"
+	waitlistConfirmed = "confirmed"
+	waitlistExpired   = "expired"
+	waitlistCancelled = "cancelled"
+)
+
+// How often expired waitlist holds are released
+const waitlistJobInterval = time.Minute
+
+var errWaitlistSpotNotFound = notFoundError("waitlist_spot_not_found", "Waitlist spot not found")
+
+// WaitlistSpot is a user's place in the FIFO queue of a full contest. Once promoted,
+// a slot is held for the user until HoldExpiresAt.
+type WaitlistSpot struct {
+	ID            int         `json:"id"`
+	ContestID     int         `json:"contest_id"`
+	UserID        int         `json:"user_id"`
+	Status        string      `json:"status"`
+	Lineup        []int       `json:"lineup"`
+	Roles         LineupRoles `json:"roles,omitempty"`
+	EntryID       *int        `json:"entry_id,omitempty"`
+	PromotedAt    *time.Time  `json:"promoted_at,omitempty"`
+	HoldExpiresAt *time.Time  `json:"hold_expires_at,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// WaitlistJoin is the request body used to join a contest's waitlist. The lineup and
+// roles are used for the entry once the user confirms a promoted spot.
+type WaitlistJoin struct {
+	Lineup []int       `json:"lineup"`
+	Roles  LineupRoles `json:"roles,omitempty"`
+}
+
+func setupWaitlistRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to join the waitlist of a full contest
+	r.POST("/contests/:id/waitlist", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		contestID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid contest ID"))
+			return
+		}
+
+		var join WaitlistJoin
+		if err := c.ShouldBindJSON(&join); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		spotID, err := joinWaitlist(db, ContestEntry{ContestID: contestID, UserID: userID, Lineup: join.Lineup, Roles: join.Roles})
+		if err != nil {
+			c.Error(apiError(err, "Failed to join the waitlist"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Joined waitlist successfully", "waitlist_id": spotID})
+	})
+
+	// Route to list the current user's waitlist spots
+	r.GET("/me/waitlist", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		spots, err := getUserWaitlistSpots(db, userID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch waitlist"))
+			return
+		}
+
+		c.JSON(http.StatusOK, spots)
+	})
+
+	// Route to turn a promoted waitlist spot into a contest entry
+	r.POST("/waitlist/:id/confirm", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		spotID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid waitlist ID"))
+			return
+		}
+
+		entryID, err := confirmWaitlistSpot(db, userID, spotID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to confirm the waitlist spot"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Entered contest successfully", "entry_id": entryID})
+	})
+
+	// Route to leave a waitlist, giving up a held slot if there is one
+	r.DELETE("/waitlist/:id", func(c *gin.Context) {
+		userID, err := currentUserID(c)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		spotID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid waitlist ID"))
+			return
+		}
+
+		if err := leaveWaitlist(db, userID, spotID); err != nil {
+			c.Error(apiError(err, "Failed to leave the waitlist"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Left waitlist successfully"})
+	})
+}
+
+// joinWaitlist queues the user for a contest that has no remaining slots
+func joinWaitlist(db *sql.DB, entry ContestEntry) (int, error) {
+	if !isUserEligible(db, entry.UserID, entry.ContestID) {
+		return 0, errNotEligible
+	}
+
+	if err := validateLineupRoles(entry.Lineup, entry.Roles); err != nil {
+		return 0, err
+	}
+
+	lineup, err := json.Marshal(entry.Lineup)
+	if err != nil {
+		return 0, err
+	}
+
+	var roles sql.NullString
+	if len(entry.Roles) > 0 {
+		b, err := json.Marshal(entry.Roles)
+		if err != nil {
+			return 0, err
+		}
+		roles = sql.NullString{String: string(b), Valid: true}
+	}
+
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the contest so a slot cannot open up between the check and the insert
+	var remainingSlots int
+	var isPrivate bool
+	var status string
+	var startDate time.Time
+	err = tx.QueryRow(
+		"SELECT remaining_slots, is_private, status, start_date FROM contest WHERE id = ? FOR UPDATE",
+		entry.ContestID,
+	).Scan(&remainingSlots, &isPrivate, &status, &startDate)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return 0, errContestNotFound
+		}
+		return 0, err
+	}
+
+	// Only contests still open for entries can be waited on, as in addEntry
+	if status != contestStatusActive || !time.Now().Before(startDate) {
+		tx.Rollback()
+		return 0, errContestStarted
+	}
+
+	if isPrivate {
+		tx.Rollback()
+		return 0, conflictError("no_waitlist", "Private contests do not have a waitlist")
+	}
+
+	if remainingSlots > 0 {
+		tx.Rollback()
+		return 0, conflictError("contest_open", "Contest still has open slots, enter it directly")
+	}
+
+	// One open spot per user and contest
+	var alreadyWaiting bool
+	err = tx.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM contest_waitlist WHERE contest_id = ? AND user_id = ? AND status IN (?, ?))",
+		entry.ContestID, entry.UserID, waitlistWaiting, waitlistPromoted,
+	).Scan(&alreadyWaiting)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if alreadyWaiting {
+		tx.Rollback()
+		return 0, conflictError("already_waitlisted", "User is already on the waitlist for this contest")
+	}
+
+	res, err := tx.Exec(
+		"INSERT INTO contest_waitlist (contest_id, user_id, status, lineup, roles, created_at) VALUES (?, ?, ?, ?, ?, NOW())",
+		entry.ContestID, entry.UserID, waitlistWaiting, string(lineup), roles,
+	)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	spotID, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(spotID), nil
+}
+
+// promoteWaitlist hands every free slot of the contest to the longest-waiting users.
+// Each promoted user gets the slot held for waitlistHoldWindow and is notified.
+func promoteWaitlist(tx *sql.Tx, contestID int) error {
+	for {
+		var remainingSlots int
+		err := tx.QueryRow("SELECT remaining_slots FROM contest WHERE id = ? FOR UPDATE", contestID).Scan(&remainingSlots)
+		if err != nil {
+			return err
+		}
+
+		if remainingSlots <= 0 {
+			return nil
+		}
+
+		var spotID, userID int
+		err = tx.QueryRow(
+			"SELECT id, user_id FROM contest_waitlist WHERE contest_id = ? AND status = ? ORDER BY created_at, id LIMIT 1 FOR UPDATE",
+			contestID, waitlistWaiting,
+		).Scan(&spotID, &userID)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Hold the slot for the promoted user
+		_, err = tx.Exec(
+			"UPDATE contest_waitlist SET status = ?, promoted_at = NOW(), hold_expires_at = DATE_ADD(NOW(), INTERVAL ? SECOND) WHERE id = ?",
+			waitlistPromoted, int(waitlistHoldWindow.Seconds()), spotID,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("UPDATE contest SET remaining_slots = remaining_slots - 1 WHERE id = ?", contestID)
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("A slot opened up in contest %d. Confirm your entry within %d minutes to keep it.", contestID, int(waitlistHoldWindow.Minutes()))
+		if err := notifyUser(tx, userID, notificationWaitlistPromoted, message); err != nil {
+			return err
+		}
+	}
+}
+
+// confirmWaitlistSpot enters the contest using the slot held for a promoted user
+func confirmWaitlistSpot(db *sql.DB, userID int, spotID int) (int, error) {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var contestID int
+	var status, lineupJSON string
+	var rolesJSON sql.NullString
+	var holdActive bool
+	err = tx.QueryRow(
+		"SELECT contest_id, status, lineup, roles, hold_expires_at > NOW() FROM contest_waitlist WHERE id = ? AND user_id = ? FOR UPDATE",
+		spotID, userID,
+	).Scan(&contestID, &status, &lineupJSON, &rolesJSON, &holdActive)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return 0, errWaitlistSpotNotFound
+		}
+		return 0, err
+	}
+
+	if status != waitlistPromoted || !holdActive {
+		tx.Rollback()
+		return 0, conflictError("no_held_slot", "No slot is being held for this waitlist spot")
+	}
+
+	var lineup []int
+	if err := json.Unmarshal([]byte(lineupJSON), &lineup); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	roles, err := decodeWaitlistRoles(rolesJSON)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// The slot was already taken off remaining_slots when the user was promoted
+	entryID, err := addEntry(db, tx, ContestEntry{ContestID: contestID, UserID: userID, Lineup: lineup, Roles: roles}, false)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	_, err = tx.Exec("UPDATE contest_waitlist SET status = ?, entry_id = ? WHERE id = ?", waitlistConfirmed, entryID, spotID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return entryID, nil
+}
+
+// leaveWaitlist removes the user from a waitlist and frees any slot held for them
+func leaveWaitlist(db *sql.DB, userID int, spotID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var contestID int
+	var status string
+	err = tx.QueryRow("SELECT contest_id, status FROM contest_waitlist WHERE id = ? AND user_id = ? FOR UPDATE", spotID, userID).Scan(&contestID, &status)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return errWaitlistSpotNotFound
+		}
+		return err
+	}
+
+	switch status {
+	case waitlistWaiting:
+		_, err = tx.Exec("UPDATE contest_waitlist SET status = ? WHERE id = ?", waitlistCancelled, spotID)
+	case waitlistPromoted:
+		err = releaseWaitlistHold(tx, spotID, contestID, waitlistCancelled)
+	default:
+		err = conflictError("not_waitlisted", "User is no longer on the waitlist")
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// releaseWaitlistHold gives a held slot back to the contest and promotes the next user in line
+func releaseWaitlistHold(tx *sql.Tx, spotID int, contestID int, status string) error {
+	_, err := tx.Exec("UPDATE contest_waitlist SET status = ? WHERE id = ?", status, spotID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("UPDATE contest SET remaining_slots = remaining_slots + 1 WHERE id = ?", contestID)
+	if err != nil {
+		return err
+	}
+
+	return promoteWaitlist(tx, contestID)
+}
+
+// expireWaitlistHolds releases every hold whose confirmation window has passed
+func expireWaitlistHolds(db *sql.DB) error {
+	rows, err := db.Query("SELECT id FROM contest_waitlist WHERE status = ? AND hold_expires_at <= NOW()", waitlistPromoted)
+	if err != nil {
+		return err
+	}
+
+	var spotIDs []int
+	for rows.Next() {
+		var spotID int
+		if err := rows.Scan(&spotID); err != nil {
+			rows.Close()
+			return err
+		}
+		spotIDs = append(spotIDs, spotID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, spotID := range spotIDs {
+		if err := expireWaitlistHold(db, spotID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expireWaitlistHold releases a single expired hold in its own transaction
+func expireWaitlistHold(db *sql.DB, spotID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Re-check under lock: the user may have confirmed in the meantime
+	var contestID, userID int
+	err = tx.QueryRow(
+		"SELECT contest_id, user_id FROM contest_waitlist WHERE id = ? AND status = ? AND hold_expires_at <= NOW() FOR UPDATE",
+		spotID, waitlistPromoted,
+	).Scan(&contestID, &userID)
+	if err == sql.ErrNoRows {
+		tx.Rollback()
+		return nil
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := releaseWaitlistHold(tx, spotID, contestID, waitlistExpired); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	message := fmt.Sprintf("Your held slot in contest %d expired before it was confirmed.", contestID)
+	if err := notifyUser(tx, userID, notificationWaitlistExpired, message); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// runWaitlistJob periodically releases expired waitlist holds
+func runWaitlistJob(ctx context.Context, db *sql.DB) {
+	ticker := time.NewTicker(waitlistJobInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := expireWaitlistHolds(db); err != nil {
+			log.Printf("waitlist: failed to expire holds: %v", err)
+		}
+	}
+}
+
+// getUserWaitlistSpots returns the user's waitlist spots, newest first
+func getUserWaitlistSpots(db *sql.DB, userID int) ([]WaitlistSpot, error) {
+	rows, err := db.Query(
+		"SELECT id, contest_id, user_id, status, lineup, roles, entry_id, promoted_at, hold_expires_at, created_at FROM contest_waitlist WHERE user_id = ? ORDER BY id DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spots := []WaitlistSpot{}
+	for rows.Next() {
+		var spot WaitlistSpot
+		var lineupJSON string
+		var rolesJSON sql.NullString
+		var entryID sql.NullInt64
+		var promotedAt, holdExpiresAt sql.NullTime
+		err := rows.Scan(&spot.ID, &spot.ContestID, &spot.UserID, &spot.Status, &lineupJSON, &rolesJSON, &entryID, &promotedAt, &holdExpiresAt, &spot.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(lineupJSON), &spot.Lineup); err != nil {
+			return nil, err
+		}
+		if spot.Roles, err = decodeWaitlistRoles(rolesJSON); err != nil {
+			return nil, err
+		}
+		if entryID.Valid {
+			id := int(entryID.Int64)
+			spot.EntryID = &id
+		}
+		if promotedAt.Valid {
+			spot.PromotedAt = &promotedAt.Time
+		}
+		if holdExpiresAt.Valid {
+			spot.HoldExpiresAt = &holdExpiresAt.Time
+		}
+		spots = append(spots, spot)
+	}
+
+	return spots, rows.Err()
+}
+
+// decodeWaitlistRoles reads the roles queued with a waitlist spot; spots queued before
+// roles were stored have none
+func decodeWaitlistRoles(rolesJSON sql.NullString) (LineupRoles, error) {
+	var roles LineupRoles
+	if !rolesJSON.Valid {
+		return roles, nil
+	}
+	err := json.Unmarshal([]byte(rolesJSON.String), &roles)
+	return roles, err
+}
@@ -0,0 +1,928 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+)
+
+// Waiver priority modes
+const (
+	waiverRolling          = "rolling"
+	waiverReverseStandings = "reverse_standings"
+	waiverFAAB             = "faab"
+)
+
+// Waiver claim statuses
+const (
+	claimPending   = "pending"
+	claimWon       = "won"
+	claimLost      = "lost"
+	claimCancelled = "cancelled"
+)
+
+// How a player joined a league team's roster
+const (
+	acquiredDraft     = "draft"
+	acquiredWaiver    = "waiver"
+	acquiredFreeAgent = "free_agent"
+)
+
+// When waiver claims are processed, as a standard cron expression (nightly at 03:00)
+const waiverSchedule = "0 3 * * *"
+
+// WaiverSettings is the request body used by a commissioner to configure waivers
+type WaiverSettings struct {
+	WaiverType  string `json:"waiver_type"`
+	WaiverHours int    `json:"waiver_hours"`
+	FAABBudget  int    `json:"faab_budget"`
+	RosterLimit int    `json:"roster_limit"`
+}
+
+// RosterMove is the request body used to drop a player, or to add one with an
+// optional player to drop in the same move
+type RosterMove struct {
+	PlayerID     int `json:"player_id"`
+	DropPlayerID int `json:"drop_player_id,omitempty"`
+}
+
+// WaiverClaimRequest is the request body used to claim a player on waivers. Bid is
+// only used by FAAB leagues.
+type WaiverClaimRequest struct {
+	PlayerID     int `json:"player_id"`
+	DropPlayerID int `json:"drop_player_id,omitempty"`
+	Bid          int `json:"bid"`
+}
+
+// WaiverClaim is a team's claim on a player on waivers
+type WaiverClaim struct {
+	ID           int        `json:"id"`
+	TeamID       int        `json:"team_id"`
+	PlayerID     int        `json:"player_id"`
+	DropPlayerID int        `json:"drop_player_id,omitempty"`
+	Bid          int        `json:"bid"`
+	Status       string     `json:"status"`
+	Reason       string     `json:"reason,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ProcessedAt  *time.Time `json:"processed_at,omitempty"`
+}
+
+// waiverLeague is the part of a league row needed to run waivers
+type waiverLeague struct {
+	ID          int
+	WaiverType  string
+	WaiverHours int
+	FAABBudget  int
+	RosterLimit int
+}
+
+func setupWaiverRoutes(r *gin.Engine, db *sql.DB) {
+	// Route for the commissioner to configure waivers and the roster limit
+	r.PUT("/leagues/:id/waivers", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		var req WaiverSettings
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		if err := updateWaiverSettings(db, userID, leagueID, req); err != nil {
+			c.Error(apiError(err, "Failed to update waiver settings"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Waiver settings updated successfully"})
+	})
+
+	// Route to drop a player from the current user's team onto waivers
+	r.POST("/leagues/:id/drops", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		var req RosterMove
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		if err := dropPlayer(db, userID, leagueID, req.PlayerID); err != nil {
+			c.Error(apiError(err, "Failed to drop the player"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Player dropped successfully"})
+	})
+
+	// Route to add a free agent straight away, outside any waiver period
+	r.POST("/leagues/:id/adds", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		var req RosterMove
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		if err := addFreeAgent(db, userID, leagueID, req); err != nil {
+			c.Error(apiError(err, "Failed to add the player"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Player added successfully"})
+	})
+
+	// Route to claim a player on waivers
+	r.POST("/leagues/:id/claims", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		var req WaiverClaimRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(invalidBody(err))
+			return
+		}
+
+		claimID, err := createWaiverClaim(db, userID, leagueID, req)
+		if err != nil {
+			c.Error(apiError(err, "Failed to claim the player"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"message": "Claim submitted successfully", "claim_id": claimID})
+	})
+
+	// Route to list the current user's claims in a league
+	r.GET("/leagues/:id/claims", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		claims, err := getWaiverClaims(db, userID, leagueID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch claims"))
+			return
+		}
+
+		c.JSON(http.StatusOK, claims)
+	})
+
+	// Route to withdraw a pending claim
+	r.DELETE("/leagues/:id/claims/:claimID", func(c *gin.Context) {
+		userID, leagueID, ok := leagueRequest(c)
+		if !ok {
+			return
+		}
+
+		claimID, err := strconv.Atoi(c.Param("claimID"))
+		if err != nil {
+			c.Error(invalidParam("claimID", "Invalid claim ID"))
+			return
+		}
+
+		res, err := db.Exec(
+			"UPDATE waiver_claim w JOIN team t ON t.id = w.team_id SET w.status = ? WHERE w.id = ? AND w.league_id = ? AND t.owner_id = ? AND w.status = ?",
+			claimCancelled, claimID, leagueID, userID, claimPending,
+		)
+		if err != nil {
+			c.Error(apiError(err, "Failed to cancel the claim"))
+			return
+		}
+
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			c.Error(notFoundError("claim_not_found", "Pending claim not found"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Claim cancelled successfully"})
+	})
+}
+
+// updateWaiverSettings changes how the league runs waivers
+func updateWaiverSettings(db *sql.DB, userID int, leagueID int, req WaiverSettings) error {
+	switch req.WaiverType {
+	case waiverRolling, waiverReverseStandings, waiverFAAB:
+	default:
+		return invalidField("invalid_waiver_type", "waiver_type", "Unknown waiver type")
+	}
+	if req.WaiverHours < 0 || req.FAABBudget < 0 || req.RosterLimit <= 0 {
+		return validationError("invalid_waiver_settings", "Invalid waiver settings")
+	}
+
+	commissionerID, err := leagueCommissioner(db, leagueID)
+	if err != nil {
+		return err
+	}
+	if commissionerID != userID {
+		return forbiddenError("not_commissioner", "Only the commissioner can change waiver settings")
+	}
+
+	_, err = db.Exec(
+		"UPDATE league SET waiver_type = ?, waiver_hours = ?, faab_budget = ?, roster_limit = ? WHERE id = ?",
+		req.WaiverType, req.WaiverHours, req.FAABBudget, req.RosterLimit, leagueID,
+	)
+	return err
+}
+
+// lockWaiverLeague reads the league's waiver settings, holding the league row until tx
+// ends so roster moves in one league are applied one at a time
+func lockWaiverLeague(tx *sql.Tx, leagueID int) (*waiverLeague, error) {
+	var l waiverLeague
+	err := tx.QueryRow(
+		"SELECT id, waiver_type, waiver_hours, faab_budget, roster_limit FROM league WHERE id = ? FOR UPDATE",
+		leagueID,
+	).Scan(&l.ID, &l.WaiverType, &l.WaiverHours, &l.FAABBudget, &l.RosterLimit)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errLeagueNotFound
+		}
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+// userLeagueTeam returns the user's team in the league
+func userLeagueTeam(q queryer, leagueID int, userID int) (int, error) {
+	var teamID int
+	err := q.QueryRow("SELECT id FROM team WHERE league_id = ? AND owner_id = ?", leagueID, userID).Scan(&teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, forbiddenError("no_league_team", "You do not have a team in this league")
+		}
+		return 0, err
+	}
+
+	return teamID, nil
+}
+
+// checkPlayerExists returns errPlayerNotFound for a player ID that is not in the player
+// pool. Leagues draw from the whole pool, so every existing player is eligible.
+func checkPlayerExists(q queryer, playerID int) error {
+	var exists bool
+	err := q.QueryRow("SELECT EXISTS (SELECT 1 FROM player WHERE id = ?)", playerID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errPlayerNotFound
+	}
+
+	return nil
+}
+
+// rosteredBy returns the league team the player is on, or 0 if nobody has them
+func rosteredBy(q queryer, leagueID int, playerID int) (int, error) {
+	var teamID int
+	err := q.QueryRow(
+		"SELECT tp.team_id FROM team_player tp JOIN team t ON t.id = tp.team_id WHERE t.league_id = ? AND tp.player_id = ?",
+		leagueID, playerID,
+	).Scan(&teamID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	return teamID, err
+}
+
+// rosterCount returns the number of players on a team
+func rosterCount(q queryer, teamID int) (int, error) {
+	var count int
+	err := q.QueryRow("SELECT COUNT(*) FROM team_player WHERE team_id = ?", teamID).Scan(&count)
+	return count, err
+}
+
+// onWaivers reports whether the player is on waivers in the league. A player stays on
+// waivers after the period ends until the next waiver run has resolved the claims.
+func onWaivers(q queryer, leagueID int, playerID int) (bool, error) {
+	var count int
+	err := q.QueryRow("SELECT COUNT(*) FROM player_waiver WHERE league_id = ? AND player_id = ?", leagueID, playerID).Scan(&count)
+	return count > 0, err
+}
+
+// releasePlayer takes a player off a team's roster and puts them on waivers
+func releasePlayer(tx *sql.Tx, l *waiverLeague, teamID int, playerID int) error {
+	res, err := tx.Exec("DELETE FROM team_player WHERE team_id = ? AND player_id = ?", teamID, playerID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return conflictError("player_not_on_roster", "The player is not on your roster")
+	}
+
+	if l.WaiverHours == 0 {
+		return nil
+	}
+
+	clearsAt := time.Now().Add(time.Duration(l.WaiverHours) * time.Hour)
+	_, err = tx.Exec(
+		"INSERT INTO player_waiver (league_id, player_id, clears_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE clears_at = VALUES(clears_at)",
+		l.ID, playerID, clearsAt,
+	)
+	return err
+}
+
+// rosterPlayer puts a player on a team's roster
+func rosterPlayer(tx *sql.Tx, teamID int, playerID int, via string) error {
+	_, err := tx.Exec("INSERT INTO team_player (team_id, player_id, acquired_via, acquired_at) VALUES (?, ?, ?, NOW())", teamID, playerID, via)
+	return err
+}
+
+// dropPlayer releases a player from the user's team onto waivers
+func dropPlayer(db *sql.DB, userID int, leagueID int, playerID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	l, err := lockWaiverLeague(tx, leagueID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	teamID, err := userLeagueTeam(tx, leagueID, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := releasePlayer(tx, l, teamID, playerID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// addFreeAgent adds an unrostered player who is not on waivers to the user's team
+func addFreeAgent(db *sql.DB, userID int, leagueID int, move RosterMove) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	l, err := lockWaiverLeague(tx, leagueID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	teamID, err := userLeagueTeam(tx, leagueID, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := checkPlayerExists(tx, move.PlayerID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	ownerTeamID, err := rosteredBy(tx, leagueID, move.PlayerID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if ownerTeamID != 0 {
+		tx.Rollback()
+		return conflictError("player_rostered", "The player is already on a team")
+	}
+
+	waived, err := onWaivers(tx, leagueID, move.PlayerID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if waived {
+		tx.Rollback()
+		return conflictError("player_on_waivers", "The player is on waivers; submit a claim instead")
+	}
+
+	if move.DropPlayerID != 0 {
+		if err := releasePlayer(tx, l, teamID, move.DropPlayerID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	count, err := rosterCount(tx, teamID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if count >= l.RosterLimit {
+		tx.Rollback()
+		return conflictError("roster_full", "Your roster is full; drop a player to make room")
+	}
+
+	if err := rosterPlayer(tx, teamID, move.PlayerID, acquiredFreeAgent); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createWaiverClaim stores a claim on a player who is on waivers. Claims are resolved
+// by the nightly waiver run after the player's waiver period ends.
+func createWaiverClaim(db *sql.DB, userID int, leagueID int, req WaiverClaimRequest) (int, error) {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	l, err := lockWaiverLeague(tx, leagueID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	teamID, err := userLeagueTeam(tx, leagueID, userID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := checkPlayerExists(tx, req.PlayerID); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	waived, err := onWaivers(tx, leagueID, req.PlayerID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if !waived {
+		tx.Rollback()
+		return 0, conflictError("player_not_on_waivers", "The player is not on waivers")
+	}
+
+	if l.WaiverType == waiverFAAB {
+		balance, err := faabBalance(tx, l, teamID)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if req.Bid < 0 || req.Bid > balance {
+			tx.Rollback()
+			return 0, invalidField("invalid_bid", "bid", fmt.Sprintf("Your bid must be between 0 and your remaining FAAB of %d", balance))
+		}
+	} else {
+		req.Bid = 0
+	}
+
+	var dropPlayerID sql.NullInt64
+	if req.DropPlayerID != 0 {
+		ownerTeamID, err := rosteredBy(tx, leagueID, req.DropPlayerID)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if ownerTeamID != teamID {
+			tx.Rollback()
+			return 0, conflictError("player_not_on_roster", "The player to drop is not on your roster")
+		}
+		dropPlayerID = sql.NullInt64{Int64: int64(req.DropPlayerID), Valid: true}
+	}
+
+	res, err := tx.Exec(
+		"INSERT INTO waiver_claim (league_id, team_id, player_id, drop_player_id, bid, status, created_at) VALUES (?, ?, ?, ?, ?, ?, NOW())",
+		leagueID, teamID, req.PlayerID, dropPlayerID, req.Bid, claimPending,
+	)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	claimID, err := res.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(claimID), nil
+}
+
+// faabBalance returns how much of the season's FAAB budget the team has left
+func faabBalance(q queryer, l *waiverLeague, teamID int) (int, error) {
+	var spent int
+	err := q.QueryRow("SELECT faab_spent FROM team WHERE id = ?", teamID).Scan(&spent)
+	if err != nil {
+		return 0, err
+	}
+
+	return l.FAABBudget - spent, nil
+}
+
+// getWaiverClaims returns the user's claims in the league, newest first
+func getWaiverClaims(db *sql.DB, userID int, leagueID int) ([]WaiverClaim, error) {
+	rows, err := db.Query(
+		"SELECT w.id, w.team_id, w.player_id, IFNULL(w.drop_player_id, 0), w.bid, w.status, IFNULL(w.reason, ''), w.created_at, w.processed_at FROM waiver_claim w JOIN team t ON t.id = w.team_id WHERE w.league_id = ? AND t.owner_id = ? ORDER BY w.created_at DESC, w.id DESC",
+		leagueID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	claims := []WaiverClaim{}
+	for rows.Next() {
+		var w WaiverClaim
+		var processedAt sql.NullTime
+		if err := rows.Scan(&w.ID, &w.TeamID, &w.PlayerID, &w.DropPlayerID, &w.Bid, &w.Status, &w.Reason, &w.CreatedAt, &processedAt); err != nil {
+			return nil, err
+		}
+		if processedAt.Valid {
+			w.ProcessedAt = &processedAt.Time
+		}
+		claims = append(claims, w)
+	}
+
+	return claims, rows.Err()
+}
+
+// processWaivers resolves the pending claims of every league with a player whose
+// waiver period has ended. A league that fails is logged and keeps its claims and
+// waivers for the next run, without holding up the other leagues.
+func processWaivers(db *sql.DB) error {
+	// One cutoff for the whole run, so a waiver clearing midway is either resolved
+	// and removed, or left alone until the next run
+	cutoff := time.Now()
+
+	rows, err := db.Query(
+		"SELECT DISTINCT w.league_id FROM waiver_claim w JOIN player_waiver pw ON pw.league_id = w.league_id AND pw.player_id = w.player_id WHERE w.status = ? AND pw.clears_at <= ? ORDER BY w.league_id",
+		claimPending, cutoff,
+	)
+	if err != nil {
+		return err
+	}
+
+	var leagueIDs []int
+	for rows.Next() {
+		var leagueID int
+		if err := rows.Scan(&leagueID); err != nil {
+			rows.Close()
+			return err
+		}
+		leagueIDs = append(leagueIDs, leagueID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, leagueID := range leagueIDs {
+		if err := processLeagueWaivers(db, leagueID, cutoff); err != nil {
+			log.Printf("waivers: failed to process the claims of league %d: %v", leagueID, err)
+		}
+	}
+
+	// Players whose claims are all resolved, or who had none, become free agents
+	_, err = db.Exec(
+		"DELETE pw FROM player_waiver pw WHERE pw.clears_at <= ? AND NOT EXISTS (SELECT 1 FROM waiver_claim w WHERE w.league_id = pw.league_id AND w.player_id = pw.player_id AND w.status = ?)",
+		cutoff, claimPending,
+	)
+	return err
+}
+
+// processLeagueWaivers resolves one league's claims on players cleared by cutoff. The
+// result only depends on the claims and the waiver order, never on timing:
+//   - rolling and reverse standings: the team with the best priority gets its earliest
+//     valid claim, then the order is walked again from the top; under rolling priority
+//     a team that wins a claim drops to the back of the order
+//   - FAAB: the highest bid wins, ties going to the better waiver priority, then the
+//     earlier claim
+func processLeagueWaivers(db *sql.DB, leagueID int, cutoff time.Time) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	l, err := lockWaiverLeague(tx, leagueID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	priority, err := waiverPriority(tx, l)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	rows, err := tx.Query(
+		"SELECT w.id, w.team_id, w.player_id, IFNULL(w.drop_player_id, 0), w.bid, w.created_at FROM waiver_claim w JOIN player_waiver pw ON pw.league_id = w.league_id AND pw.player_id = w.player_id WHERE w.league_id = ? AND w.status = ? AND pw.clears_at <= ? ORDER BY w.created_at, w.id",
+		leagueID, claimPending, cutoff,
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	var claims []*WaiverClaim
+	for rows.Next() {
+		var w WaiverClaim
+		if err := rows.Scan(&w.ID, &w.TeamID, &w.PlayerID, &w.DropPlayerID, &w.Bid, &w.CreatedAt); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return err
+		}
+		claims = append(claims, &w)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	priority, err = resolveClaimsInOrder(l.WaiverType, priority, claims, func(w *WaiverClaim) error {
+		return resolveClaim(tx, l, w)
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if l.WaiverType == waiverRolling {
+		for i, teamID := range priority {
+			_, err = tx.Exec("UPDATE team SET waiver_priority = ? WHERE id = ?", i+1, teamID)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// resolveClaimsInOrder hands the claims, oldest first, to resolve in the order the
+// waiver type sets out in processLeagueWaivers. resolve records the outcome of a claim in
+// its Status. It returns the waiver order after the run, which only changes under
+// rolling priority.
+func resolveClaimsInOrder(waiverType string, priority []int, claims []*WaiverClaim, resolve func(*WaiverClaim) error) ([]int, error) {
+	if waiverType == waiverFAAB {
+		rank := make(map[int]int, len(priority))
+		for i, teamID := range priority {
+			rank[teamID] = i
+		}
+
+		sort.SliceStable(claims, func(i, j int) bool {
+			if claims[i].Bid != claims[j].Bid {
+				return claims[i].Bid > claims[j].Bid
+			}
+			return rank[claims[i].TeamID] < rank[claims[j].TeamID]
+		})
+
+		for _, w := range claims {
+			if err := resolve(w); err != nil {
+				return nil, err
+			}
+		}
+		return priority, nil
+	}
+
+	for {
+		awarded := false
+		for _, teamID := range priority {
+			for _, w := range claims {
+				if w.TeamID != teamID || w.Status != "" {
+					continue
+				}
+				if err := resolve(w); err != nil {
+					return nil, err
+				}
+				if w.Status == claimWon {
+					awarded = true
+					break
+				}
+			}
+			if awarded {
+				if waiverType == waiverRolling {
+					priority = moveToBack(priority, teamID)
+				}
+				break
+			}
+		}
+		if !awarded {
+			return priority, nil
+		}
+	}
+}
+
+// resolveClaim awards the claim if it can still be carried out, and marks it lost
+// with the reason otherwise. The outcome is recorded in w.Status.
+func resolveClaim(tx *sql.Tx, l *waiverLeague, w *WaiverClaim) error {
+	ownerTeamID, err := rosteredBy(tx, l.ID, w.PlayerID)
+	if err != nil {
+		return err
+	}
+	if ownerTeamID != 0 {
+		return setClaimResult(tx, w, claimLost, "Claimed by a team with higher priority")
+	}
+
+	if l.WaiverType == waiverFAAB {
+		balance, err := faabBalance(tx, l, w.TeamID)
+		if err != nil {
+			return err
+		}
+		if w.Bid > balance {
+			return setClaimResult(tx, w, claimLost, "Not enough FAAB left")
+		}
+	}
+
+	if w.DropPlayerID != 0 {
+		dropTeamID, err := rosteredBy(tx, l.ID, w.DropPlayerID)
+		if err != nil {
+			return err
+		}
+		if dropTeamID != w.TeamID {
+			return setClaimResult(tx, w, claimLost, "The player to drop is no longer on the roster")
+		}
+	}
+
+	count, err := rosterCount(tx, w.TeamID)
+	if err != nil {
+		return err
+	}
+	if w.DropPlayerID != 0 {
+		count--
+	}
+	if count >= l.RosterLimit {
+		return setClaimResult(tx, w, claimLost, "Roster is full")
+	}
+
+	if w.DropPlayerID != 0 {
+		if err := releasePlayer(tx, l, w.TeamID, w.DropPlayerID); err != nil {
+			return err
+		}
+	}
+
+	if err := rosterPlayer(tx, w.TeamID, w.PlayerID, acquiredWaiver); err != nil {
+		return err
+	}
+
+	if w.Bid > 0 {
+		_, err = tx.Exec("UPDATE team SET faab_spent = faab_spent + ? WHERE id = ?", w.Bid, w.TeamID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return setClaimResult(tx, w, claimWon, "")
+}
+
+func setClaimResult(tx *sql.Tx, w *WaiverClaim, status string, reason string) error {
+	var why sql.NullString
+	if reason != "" {
+		why = sql.NullString{String: reason, Valid: true}
+	}
+
+	_, err := tx.Exec("UPDATE waiver_claim SET status = ?, reason = ?, processed_at = NOW() WHERE id = ?", status, why, w.ID)
+	if err != nil {
+		return err
+	}
+
+	w.Status = status
+	w.Reason = reason
+	return nil
+}
+
+// waiverPriority returns the league's teams from first to last waiver priority. Under
+// reverse standings the worst team goes first; otherwise the stored rolling order is
+// used, which FAAB leagues also use to break tied bids.
+func waiverPriority(tx *sql.Tx, l *waiverLeague) ([]int, error) {
+	if l.WaiverType == waiverReverseStandings {
+		standings, err := getStandings(tx, l.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		priority := make([]int, len(standings))
+		for i, s := range standings {
+			priority[len(standings)-1-i] = s.TeamID
+		}
+		return priority, nil
+	}
+
+	rows, err := tx.Query("SELECT id FROM team WHERE league_id = ? ORDER BY waiver_priority, id", l.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var priority []int
+	for rows.Next() {
+		var teamID int
+		if err := rows.Scan(&teamID); err != nil {
+			return nil, err
+		}
+		priority = append(priority, teamID)
+	}
+
+	return priority, rows.Err()
+}
+
+// moveToBack moves teamID to the end of the waiver order
+func moveToBack(priority []int, teamID int) []int {
+	order := make([]int, 0, len(priority))
+	for _, id := range priority {
+		if id != teamID {
+			order = append(order, id)
+		}
+	}
+
+	return append(order, teamID)
+}
+
+// runWaiverJob processes waiver claims on waiverSchedule
+func runWaiverJob(ctx context.Context, db *sql.DB) {
+	schedule, err := cron.ParseStandard(waiverSchedule)
+	if err != nil {
+		log.Printf("waivers: invalid schedule %q: %v", waiverSchedule, err)
+		return
+	}
+
+	for {
+		timer := time.NewTimer(time.Until(schedule.Next(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := processWaivers(db); err != nil {
+			log.Printf("waivers: failed to process claims: %v", err)
+		}
+	}
+}
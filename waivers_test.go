@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveClaimsInOrder(t *testing.T) {
+	type claim struct {
+		team, player, bid int
+	}
+
+	tests := []struct {
+		waiverType string
+		claims     []claim
+		order      []int // claim indexes in the order they are resolved
+		won        []int
+		priority   []int
+	}{
+		// Each team in priority order gets its earliest claim that can still be carried
+		// out, and a winner drops to the back before the order is walked again
+		{
+			waiverRolling,
+			[]claim{{2, 100, 0}, {1, 100, 0}, {1, 101, 0}, {3, 101, 0}, {2, 102, 0}},
+			[]int{1, 0, 4, 3, 2},
+			[]int{1, 4, 3},
+			[]int{1, 2, 3},
+		},
+		// The order stays put, so the top team keeps winning while it has claims
+		{
+			waiverReverseStandings,
+			[]claim{{2, 100, 0}, {1, 100, 0}, {1, 101, 0}, {3, 101, 0}, {2, 102, 0}},
+			[]int{1, 2, 0, 4, 3},
+			[]int{1, 2, 4},
+			[]int{1, 2, 3},
+		},
+		// The highest bid wins; equal bids go to the better priority, then the older claim
+		{
+			waiverFAAB,
+			[]claim{{2, 100, 10}, {1, 100, 10}, {3, 100, 15}, {1, 101, 5}, {3, 101, 5}, {2, 102, 1}, {2, 102, 1}},
+			[]int{2, 1, 0, 3, 4, 5, 6},
+			[]int{2, 3, 5},
+			[]int{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		claims := make([]*WaiverClaim, len(tt.claims))
+		index := make(map[*WaiverClaim]int, len(tt.claims))
+		for i, c := range tt.claims {
+			claims[i] = &WaiverClaim{ID: i, TeamID: c.team, PlayerID: c.player, Bid: c.bid}
+			index[claims[i]] = i
+		}
+
+		// A player can only be won once, like resolveClaim finding them rostered
+		var order, won []int
+		taken := make(map[int]bool)
+		resolve := func(w *WaiverClaim) error {
+			order = append(order, index[w])
+			if taken[w.PlayerID] {
+				w.Status = claimLost
+				return nil
+			}
+			taken[w.PlayerID] = true
+			w.Status = claimWon
+			won = append(won, index[w])
+			return nil
+		}
+
+		priority, err := resolveClaimsInOrder(tt.waiverType, []int{1, 2, 3}, claims, resolve)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.waiverType, err)
+		}
+		if !reflect.DeepEqual(order, tt.order) {
+			t.Errorf("%s: resolved claims %v, want %v", tt.waiverType, order, tt.order)
+		}
+		if !reflect.DeepEqual(won, tt.won) {
+			t.Errorf("%s: won claims %v, want %v", tt.waiverType, won, tt.won)
+		}
+		if !reflect.DeepEqual(priority, tt.priority) {
+			t.Errorf("%s: priority %v, want %v", tt.waiverType, priority, tt.priority)
+		}
+	}
+}
+
+func TestRollingPriorityMovesWinnersToTheBack(t *testing.T) {
+	claims := []*WaiverClaim{
+		{TeamID: 1, PlayerID: 100},
+		{TeamID: 3, PlayerID: 101},
+	}
+	resolve := func(w *WaiverClaim) error {
+		w.Status = claimWon
+		return nil
+	}
+
+	priority, err := resolveClaimsInOrder(waiverRolling, []int{1, 2, 3, 4}, claims, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 4, 1, 3}; !reflect.DeepEqual(priority, want) {
+		t.Errorf("priority %v, want %v", priority, want)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"database/sql"
+)
+
+// debitWallet takes amount out of the user's balance and records it in the ledger.
+// It fails without changing anything if the balance is too low.
+func debitWallet(tx *sql.Tx, userID int, amount float64, reason string) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	res, err := tx.Exec("UPDATE users SET balance = balance - ? WHERE id = ? AND balance >= ?", amount, userID, amount)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return conflictError("insufficient_balance", "Insufficient wallet balance")
+	}
+
+	_, err = tx.Exec("INSERT INTO wallet_transaction (user_id, amount, reason, created_at) VALUES (?, ?, ?, NOW())", userID, -amount, reason)
+	return err
+}
+
+// creditWallet adds amount to the user's balance and records it in the ledger
+func creditWallet(tx *sql.Tx, userID int, amount float64, reason string) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	_, err := tx.Exec("UPDATE users SET balance = balance + ? WHERE id = ?", amount, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("INSERT INTO wallet_transaction (user_id, amount, reason, created_at) VALUES (?, ?, ?, NOW())", userID, amount, reason)
+	return err
+}
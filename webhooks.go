@@ -0,0 +1,857 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Webhook event types
+const (
+	webhookContestOpened    = "contest.opened"
+	webhookContestFilled    = "contest.filled"
+	webhookContestLocked    = "contest.locked"
+	webhookContestCancelled = "contest.cancelled"
+	webhookContestSettled   = "contest.settled"
+	webhookEntryCreated     = "entry.created"
+	webhookEntryDeleted     = "entry.deleted"
+)
+
+// Webhook delivery statuses. A dead delivery ran out of attempts and stays in the
+// dead-letter list until it is replayed.
+const (
+	deliveryPending   = "pending"
+	deliveryDelivered = "delivered"
+	deliveryDead      = "dead"
+)
+
+const (
+	// How often due deliveries are sent
+	webhookJobInterval = 10 * time.Second
+
+	// How many due deliveries one run of the job sends
+	webhookBatchSize = 50
+
+	// How long a partner's endpoint gets to answer a delivery
+	webhookTimeout = 10 * time.Second
+
+	// A delivery is dead after this many failed attempts. The waits between attempts
+	// double from webhookBaseBackoff, so the last attempt is about an hour after the first.
+	webhookMaxAttempts = 8
+	webhookBaseBackoff = 30 * time.Second
+
+	// Headers sent with every delivery. The signature is the hex HMAC-SHA256 of
+	// "<timestamp>.<body>" keyed with the subscription's secret.
+	webhookSignatureHeader = "X-Webhook-Signature"
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+	webhookEventHeader     = "X-Webhook-Event"
+	webhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var (
+	errWebhookNotFound = notFoundError("webhook_not_found", "Webhook subscription not found")
+	errNotPartner      = forbiddenError("not_partner", "Webhooks are only available to partner accounts")
+
+	// errWebhookTargetForbidden is returned when dialling a webhook URL that resolves to
+	// an address inside our own network
+	errWebhookTargetForbidden = errors.New("webhook target is not a public address")
+)
+
+// WebhookRequest is the request body used to subscribe to webhook events
+type WebhookRequest struct {
+	URL        string   `json:"url" binding:"required,http_url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,oneof=contest.opened contest.filled contest.locked contest.cancelled contest.settled entry.created entry.deleted"`
+}
+
+// WebhookSubscription is a partner endpoint that receives the events it subscribed to.
+// The secret used to sign deliveries is only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is one event sent, or still to be sent, to one subscription
+type WebhookDelivery struct {
+	ID             int        `json:"id"`
+	SubscriptionID int        `json:"subscription_id"`
+	EventID        int        `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// WebhookAttempt is the log of one try at sending a delivery
+type WebhookAttempt struct {
+	ID         int       `json:"id"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int       `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookEvent is the body posted to a subscription's URL
+type WebhookEvent struct {
+	ID        int             `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// webhookContestData is the data of the contest events; the leaderboard is only sent
+// once the contest is settled
+type webhookContestData struct {
+	Contest     ContestResponse    `json:"contest"`
+	Leaderboard []LeaderboardEntry `json:"leaderboard,omitempty"`
+}
+
+// webhookEntryData is the data of the entry events
+type webhookEntryData struct {
+	EntryID   int `json:"entry_id"`
+	ContestID int `json:"contest_id"`
+	UserID    int `json:"user_id"`
+}
+
+// dueDelivery is a pending delivery with what is needed to send it
+type dueDelivery struct {
+	ID       int
+	Attempts int
+	URL      string
+	Secret   string
+	Event    WebhookEvent
+}
+
+func setupWebhookRoutes(r *gin.Engine, db *sql.DB) {
+	// Route to subscribe a URL to webhook events
+	r.POST("/webhooks", func(c *gin.Context) {
+		userID, ok := partnerRequest(c, db)
+		if !ok {
+			return
+		}
+
+		var req WebhookRequest
+		if err := bindJSON(c, &req); err != nil {
+			c.Error(err)
+			return
+		}
+
+		subscription, err := createWebhook(db, userID, req)
+		if err != nil {
+			c.Error(apiError(err, "Failed to create webhook"))
+			return
+		}
+
+		c.JSON(http.StatusCreated, subscription)
+	})
+
+	// Route to list the current user's webhook subscriptions
+	r.GET("/webhooks", func(c *gin.Context) {
+		userID, ok := partnerRequest(c, db)
+		if !ok {
+			return
+		}
+
+		subscriptions, err := getUserWebhooks(db, userID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch webhooks"))
+			return
+		}
+
+		c.JSON(http.StatusOK, subscriptions)
+	})
+
+	// Route to unsubscribe. The subscription is deactivated rather than deleted so its
+	// delivery log stays available.
+	r.DELETE("/webhooks/:id", func(c *gin.Context) {
+		userID, ok := partnerRequest(c, db)
+		if !ok {
+			return
+		}
+
+		webhookID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid webhook ID"))
+			return
+		}
+
+		if err := deactivateWebhook(db, userID, webhookID); err != nil {
+			c.Error(apiError(err, "Failed to delete webhook"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+	})
+
+	// Route to list a subscription's deliveries, newest first, optionally only those
+	// with one status, e.g. ?status=dead for the dead letters
+	r.GET("/webhooks/:id/deliveries", func(c *gin.Context) {
+		userID, ok := partnerRequest(c, db)
+		if !ok {
+			return
+		}
+
+		webhookID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid webhook ID"))
+			return
+		}
+
+		status := c.Query("status")
+		if status != "" && status != deliveryPending && status != deliveryDelivered && status != deliveryDead {
+			c.Error(invalidParam("status", "Invalid delivery status"))
+			return
+		}
+
+		deliveries, err := getWebhookDeliveries(db, userID, webhookID, status)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch deliveries"))
+			return
+		}
+
+		c.JSON(http.StatusOK, deliveries)
+	})
+
+	// Route to show every attempt made at sending a delivery
+	r.GET("/webhooks/:id/deliveries/:deliveryID/attempts", func(c *gin.Context) {
+		userID, ok := partnerRequest(c, db)
+		if !ok {
+			return
+		}
+
+		webhookID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid webhook ID"))
+			return
+		}
+
+		deliveryID, err := strconv.Atoi(c.Param("deliveryID"))
+		if err != nil {
+			c.Error(invalidParam("deliveryID", "Invalid delivery ID"))
+			return
+		}
+
+		attempts, err := getDeliveryAttempts(db, userID, webhookID, deliveryID)
+		if err != nil {
+			c.Error(apiError(err, "Failed to fetch delivery attempts"))
+			return
+		}
+
+		c.JSON(http.StatusOK, attempts)
+	})
+
+	// Route to send a delivery again, e.g. a dead letter once the partner fixed their endpoint
+	r.POST("/webhooks/:id/deliveries/:deliveryID/replay", func(c *gin.Context) {
+		userID, ok := partnerRequest(c, db)
+		if !ok {
+			return
+		}
+
+		webhookID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Error(invalidParam("id", "Invalid webhook ID"))
+			return
+		}
+
+		deliveryID, err := strconv.Atoi(c.Param("deliveryID"))
+		if err != nil {
+			c.Error(invalidParam("deliveryID", "Invalid delivery ID"))
+			return
+		}
+
+		if err := replayDelivery(db, userID, webhookID, deliveryID); err != nil {
+			c.Error(apiError(err, "Failed to replay delivery"))
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{"message": "Delivery queued for replay"})
+	})
+}
+
+// partnerRequest reads the current user of a webhook route and checks it is a partner
+// account, writing the error response itself when it is not
+func partnerRequest(c *gin.Context, db *sql.DB) (int, bool) {
+	userID, err := currentUserID(c)
+	if err != nil {
+		c.Error(err)
+		return 0, false
+	}
+
+	var isPartner bool
+	err = db.QueryRow("SELECT is_partner FROM users WHERE id = ?", userID).Scan(&isPartner)
+	if err != nil && err != sql.ErrNoRows {
+		c.Error(apiError(err, "Failed to check the account"))
+		return 0, false
+	}
+	if !isPartner {
+		c.Error(errNotPartner)
+		return 0, false
+	}
+
+	return userID, true
+}
+
+// createWebhook stores a new active subscription with a fresh signing secret
+func createWebhook(db *sql.DB, userID int, req WebhookRequest) (*WebhookSubscription, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	eventTypes := uniqueStrings(req.EventTypes)
+	res, err := db.Exec(
+		"INSERT INTO webhook_subscription (user_id, url, secret, event_types, is_active, created_at) VALUES (?, ?, ?, ?, TRUE, NOW())",
+		userID, req.URL, secret, strings.Join(eventTypes, ","),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookID, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookSubscription{
+		ID:         int(webhookID),
+		URL:        req.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		Active:     true,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// validateWebhookURL rejects URLs whose host is, or resolves to, a loopback, private,
+// link-local or otherwise internal address, so partners cannot make us call into our own
+// network. The dialer checks again at delivery time in case the DNS record changed.
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return invalidField("invalid_webhook_url", "url", "The URL is invalid")
+	}
+
+	host := u.Hostname()
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+		defer cancel()
+
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return invalidField("invalid_webhook_url", "url", "The URL's host could not be resolved")
+		}
+		ips = ips[:0]
+		for _, addr := range addrs {
+			ips = append(ips, addr.IP)
+		}
+	}
+
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return invalidField("invalid_webhook_url", "url", "The URL must point to a public address")
+		}
+	}
+
+	return nil
+}
+
+// isPublicIP reports whether ip can be reached from the internet, i.e. it is not a
+// loopback, private (RFC 1918, RFC 4193), link-local, multicast or unspecified address
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// newWebhookClient returns the HTTP client deliveries are sent with. Its dialer refuses
+// internal addresses after DNS resolution, which also covers redirects, and it ignores
+// proxy settings so the check applies to the partner's endpoint itself.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return errWebhookTargetForbidden
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout:   webhookTimeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext},
+	}
+}
+
+// newWebhookSecret returns a random secret for signing a subscription's deliveries
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// uniqueStrings returns values without duplicates, keeping their first occurrence
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// getUserWebhooks returns the user's subscriptions, including deactivated ones
+func getUserWebhooks(db *sql.DB, userID int) ([]WebhookSubscription, error) {
+	rows, err := db.Query("SELECT id, url, event_types, is_active, created_at FROM webhook_subscription WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []WebhookSubscription{}
+	for rows.Next() {
+		var s WebhookSubscription
+		var eventTypes string
+		if err := rows.Scan(&s.ID, &s.URL, &eventTypes, &s.Active, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		s.EventTypes = strings.Split(eventTypes, ",")
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// checkWebhookOwner returns errWebhookNotFound unless the subscription belongs to the user,
+// and reports whether it is still active
+func checkWebhookOwner(q queryer, userID int, webhookID int) (bool, error) {
+	var active bool
+	err := q.QueryRow("SELECT is_active FROM webhook_subscription WHERE id = ? AND user_id = ?", webhookID, userID).Scan(&active)
+	if err == sql.ErrNoRows {
+		return false, errWebhookNotFound
+	}
+	return active, err
+}
+
+// deactivateWebhook stops all further deliveries to the user's subscription. Pending
+// deliveries are left as they are and are not sent.
+func deactivateWebhook(db *sql.DB, userID int, webhookID int) error {
+	if _, err := checkWebhookOwner(db, userID, webhookID); err != nil {
+		return err
+	}
+
+	_, err := db.Exec("UPDATE webhook_subscription SET is_active = FALSE WHERE id = ?", webhookID)
+	return err
+}
+
+// getWebhookDeliveries returns the subscription's latest deliveries, newest first, only
+// those with the given status unless it is empty
+func getWebhookDeliveries(db *sql.DB, userID int, webhookID int, status string) ([]WebhookDelivery, error) {
+	if _, err := checkWebhookOwner(db, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	query := "SELECT d.id, d.subscription_id, d.event_id, e.event_type, d.status, d.attempts, d.next_attempt_at, IFNULL(d.last_status_code, 0), IFNULL(d.last_error, ''), d.delivered_at, d.created_at FROM webhook_delivery d JOIN webhook_event e ON e.id = d.event_id WHERE d.subscription_id = ?"
+	args := []interface{}{webhookID}
+	if status != "" {
+		query += " AND d.status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY d.id DESC LIMIT 100"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		var nextAttemptAt, deliveredAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts, &nextAttemptAt, &d.LastStatusCode, &d.LastError, &deliveredAt, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		if nextAttemptAt.Valid && d.Status == deliveryPending {
+			d.NextAttemptAt = &nextAttemptAt.Time
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// getDeliveryAttempts returns the attempts made at sending one of the subscription's
+// deliveries, oldest first
+func getDeliveryAttempts(db *sql.DB, userID int, webhookID int, deliveryID int) ([]WebhookAttempt, error) {
+	if _, err := checkWebhookOwner(db, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM webhook_delivery WHERE id = ? AND subscription_id = ?)", deliveryID, webhookID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, notFoundError("delivery_not_found", "Webhook delivery not found")
+	}
+
+	rows, err := db.Query("SELECT id, IFNULL(status_code, 0), IFNULL(error, ''), duration_ms, created_at FROM webhook_delivery_attempt WHERE delivery_id = ? ORDER BY id", deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []WebhookAttempt{}
+	for rows.Next() {
+		var a WebhookAttempt
+		if err := rows.Scan(&a.ID, &a.StatusCode, &a.Error, &a.DurationMS, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+
+	return attempts, rows.Err()
+}
+
+// replayDelivery queues a delivered or dead delivery to be sent again with a fresh set
+// of attempts. Its earlier attempts stay in the log.
+func replayDelivery(db *sql.DB, userID int, webhookID int, deliveryID int) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	active, err := checkWebhookOwner(tx, userID, webhookID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if !active {
+		tx.Rollback()
+		return conflictError("webhook_inactive", "The webhook subscription has been deleted")
+	}
+
+	var status string
+	err = tx.QueryRow("SELECT status FROM webhook_delivery WHERE id = ? AND subscription_id = ? FOR UPDATE", deliveryID, webhookID).Scan(&status)
+	if err != nil {
+		tx.Rollback()
+		if err == sql.ErrNoRows {
+			return notFoundError("delivery_not_found", "Webhook delivery not found")
+		}
+		return err
+	}
+
+	if status == deliveryPending {
+		tx.Rollback()
+		return conflictError("delivery_pending", "The delivery is still being attempted")
+	}
+
+	_, err = tx.Exec("UPDATE webhook_delivery SET status = ?, attempts = 0, next_attempt_at = NOW(), delivered_at = NULL, updated_at = NOW() WHERE id = ?", deliveryPending, deliveryID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// recordWebhookEvent stores an event and queues a delivery for every active subscription
+// to its type, as part of the caller's transaction, so partners are only told about
+// changes that were committed
+func recordWebhookEvent(tx *sql.Tx, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec("INSERT INTO webhook_event (event_type, payload, created_at) VALUES (?, ?, NOW())", eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	eventID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO webhook_delivery (subscription_id, event_id, status, attempts, next_attempt_at, created_at, updated_at) "+
+			"SELECT s.id, ?, ?, 0, NOW(), NOW(), NOW() FROM webhook_subscription s JOIN users u ON u.id = s.user_id WHERE s.is_active = TRUE AND u.is_partner = TRUE AND FIND_IN_SET(?, s.event_types)",
+		eventID, deliveryPending, eventType,
+	)
+	return err
+}
+
+// recordContestEvent records a contest event carrying the contest as it is in tx.
+// Private contests are between their invitees, so partners are never told about them.
+func recordContestEvent(tx *sql.Tx, eventType string, contestID int, leaderboard []LeaderboardEntry) error {
+	contest, err := scanContest(tx.QueryRow("SELECT "+contestColumns+" FROM contest WHERE id = ?", contestID))
+	if err != nil {
+		return err
+	}
+	if contest.IsPrivate {
+		return nil
+	}
+
+	return recordWebhookEvent(tx, eventType, webhookContestData{
+		Contest:     newContestResponse(contest, time.Now()),
+		Leaderboard: leaderboard,
+	})
+}
+
+// recordEntryEvent records an entry event, unless the entry is in a private contest
+func recordEntryEvent(tx *sql.Tx, eventType string, entryID int, contestID int, userID int) error {
+	var isPrivate bool
+	if err := tx.QueryRow("SELECT is_private FROM contest WHERE id = ?", contestID).Scan(&isPrivate); err != nil {
+		return err
+	}
+	if isPrivate {
+		return nil
+	}
+
+	return recordWebhookEvent(tx, eventType, webhookEntryData{EntryID: entryID, ContestID: contestID, UserID: userID})
+}
+
+// deliverDueWebhooks sends the pending deliveries whose next attempt is due
+func deliverDueWebhooks(db *sql.DB, client *http.Client) error {
+	rows, err := db.Query(
+		"SELECT d.id, d.attempts, s.url, s.secret, e.id, e.event_type, e.payload, e.created_at FROM webhook_delivery d "+
+			"JOIN webhook_subscription s ON s.id = d.subscription_id JOIN webhook_event e ON e.id = d.event_id "+
+			"WHERE d.status = ? AND d.next_attempt_at <= NOW() AND s.is_active = TRUE ORDER BY d.next_attempt_at, d.id LIMIT ?",
+		deliveryPending, webhookBatchSize,
+	)
+	if err != nil {
+		return err
+	}
+
+	var due []dueDelivery
+	for rows.Next() {
+		var d dueDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.Attempts, &d.URL, &d.Secret, &d.Event.ID, &d.Event.Type, &payload, &d.Event.CreatedAt); err != nil {
+			rows.Close()
+			return err
+		}
+		d.Event.Data = payload
+		due = append(due, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range due {
+		// Claim the delivery so another instance running this job does not send it too;
+		// if this one dies mid-send the claim expires and the delivery is retried
+		res, err := db.Exec(
+			"UPDATE webhook_delivery SET next_attempt_at = ? WHERE id = ? AND status = ? AND next_attempt_at <= NOW()",
+			time.Now().Add(2*webhookTimeout), d.ID, deliveryPending,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			continue
+		}
+
+		start := time.Now()
+		statusCode, sendErr := sendWebhook(client, d)
+		if err := recordWebhookAttempt(db, d, statusCode, sendErr, time.Since(start)); err != nil {
+			return fmt.Errorf("delivery %d: %v", d.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// sendWebhook posts the delivery's event to the subscription's URL, signed with its
+// secret, and returns the response status. Any status other than 2xx is an error.
+func sendWebhook(client *http.Client, d dueDelivery) (int, error) {
+	body, err := json.Marshal(d.Event)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookSignatureHeader, "sha256="+signWebhook(d.Secret, timestamp, body))
+	req.Header.Set(webhookTimestampHeader, timestamp)
+	req.Header.Set(webhookEventHeader, d.Event.Type)
+	req.Header.Set(webhookDeliveryHeader, strconv.Itoa(d.ID))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// signWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>". Partners recompute it
+// with their secret and reject old timestamps to guard against replayed requests.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff returns how long to wait after the given number of failed attempts
+func webhookBackoff(attempts int) time.Duration {
+	return webhookBaseBackoff << (attempts - 1)
+}
+
+// recordWebhookAttempt logs one attempt at sending a delivery and moves the delivery on:
+// delivered on success, otherwise retried after a backoff or dead once out of attempts
+func recordWebhookAttempt(db *sql.DB, d dueDelivery, statusCode int, sendErr error, duration time.Duration) error {
+	// Start a transaction to ensure consistency
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var errMessage sql.NullString
+	if sendErr != nil {
+		errMessage = sql.NullString{String: truncateString(sendErr.Error(), 512), Valid: true}
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO webhook_delivery_attempt (delivery_id, status_code, error, duration_ms, created_at) VALUES (?, NULLIF(?, 0), ?, ?, NOW())",
+		d.ID, statusCode, errMessage, duration.Milliseconds(),
+	)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	attempts := d.Attempts + 1
+	switch {
+	case sendErr == nil:
+		_, err = tx.Exec(
+			"UPDATE webhook_delivery SET status = ?, attempts = ?, last_status_code = ?, last_error = NULL, delivered_at = NOW(), updated_at = NOW() WHERE id = ?",
+			deliveryDelivered, attempts, statusCode, d.ID,
+		)
+	case attempts >= webhookMaxAttempts:
+		_, err = tx.Exec(
+			"UPDATE webhook_delivery SET status = ?, attempts = ?, last_status_code = NULLIF(?, 0), last_error = ?, updated_at = NOW() WHERE id = ?",
+			deliveryDead, attempts, statusCode, errMessage, d.ID,
+		)
+	default:
+		_, err = tx.Exec(
+			"UPDATE webhook_delivery SET attempts = ?, next_attempt_at = ?, last_status_code = NULLIF(?, 0), last_error = ?, updated_at = NOW() WHERE id = ?",
+			attempts, time.Now().Add(webhookBackoff(attempts)), statusCode, errMessage, d.ID,
+		)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// truncateString cuts s to at most n bytes
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// runWebhookJob periodically sends the webhook deliveries that are due
+func runWebhookJob(ctx context.Context, db *sql.DB) {
+	client := newWebhookClient()
+
+	ticker := time.NewTicker(webhookJobInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := deliverDueWebhooks(db, client); err != nil {
+			log.Printf("webhooks: failed to send deliveries: %v", err)
+		}
+	}
+}
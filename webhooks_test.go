@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.public {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.public)
+		}
+	}
+}
+
+func TestValidateWebhookURLRejectsInternalTargets(t *testing.T) {
+	for _, rawURL := range []string{
+		"http://127.0.0.1/hook",
+		"http://10.0.0.5:8080/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"https://[::1]/hook",
+		"http://localhost/hook",
+	} {
+		err := validateWebhookURL(rawURL)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != "invalid_webhook_url" {
+			t.Errorf("%s: got %v, want invalid_webhook_url", rawURL, err)
+		}
+	}
+
+	if err := validateWebhookURL("https://93.184.216.34/hook"); err != nil {
+		t.Errorf("public address rejected: %v", err)
+	}
+}
+
+func TestWebhookClientRefusesInternalTargets(t *testing.T) {
+	called := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer ts.Close()
+
+	// The URL passed validation earlier, e.g. before its DNS record was changed
+	_, err := sendWebhook(newWebhookClient(), dueDelivery{ID: 1, URL: ts.URL, Secret: "whsec_test", Event: WebhookEvent{ID: 1, Type: webhookContestOpened}})
+	if !errors.Is(err, errWebhookTargetForbidden) {
+		t.Errorf("got %v, want %v", err, errWebhookTargetForbidden)
+	}
+	if called {
+		t.Error("the internal endpoint was called")
+	}
+}